@@ -0,0 +1,115 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type AnonymousSuite struct{}
+
+var _ = Suite(&AnonymousSuite{})
+
+type ExampleAnonymous struct {
+	Limits struct {
+		MaxConns int `default:"100"`
+		MaxIdle  int `default:"10"`
+	}
+	Backup *struct {
+		Path string `default:"/var/backup"`
+	}
+	Routes []struct {
+		Path    string   `default:"/"`
+		Methods []string `default:"[GET]"`
+	}
+}
+
+func (s *AnonymousSuite) TestSetDefaults(c *C) {
+	foo := &ExampleAnonymous{}
+	foo.Routes = make([]struct {
+		Path    string   `default:"/"`
+		Methods []string `default:"[GET]"`
+	}, 2)
+	foo.Routes[1].Path = "/api"
+	SetDefaults(foo)
+
+	c.Assert(foo.Limits.MaxConns, Equals, 100)
+	c.Assert(foo.Limits.MaxIdle, Equals, 10)
+	c.Assert(foo.Backup, IsNil)
+	c.Assert(foo.Routes[0].Path, Equals, "/")
+	c.Assert(foo.Routes[1].Path, Equals, "/api")
+	c.Assert(foo.Routes[1].Methods, DeepEquals, []string{"GET"})
+}
+
+func (s *AnonymousSuite) TestPaths(c *C) {
+	report := &FillReport{}
+	filler := NewFiller(WithReport(report))
+	foo := &ExampleAnonymous{Routes: make([]struct {
+		Path    string   `default:"/"`
+		Methods []string `default:"[GET]"`
+	}, 1)}
+	c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+
+	var paths []string
+	for _, field := range report.Fields {
+		paths = append(paths, field.Path)
+	}
+	c.Assert(paths, DeepEquals, []string{"Limits.MaxConns", "Limits.MaxIdle", "Routes[0].Path", "Routes[0].Methods"})
+
+	err := SetDefaultsContext(context.Background(), &struct {
+		Limits struct {
+			MaxConns int `default:"many"`
+		}
+	}{})
+	c.Assert(err, ErrorMatches, `Limits.MaxConns: .*`)
+}
+
+// Unnamed types all hash to ".", a filler registered for it must not catch
+// them.
+func (s *AnonymousSuite) TestTypeHash(c *C) {
+	var calls int
+	filler := NewFiller()
+	filler.FuncByType[GetTypeHash(reflect.TypeOf(ExampleAnonymous{}.Limits))] = func(field *FieldData) {
+		calls++
+	}
+
+	foo := &ExampleAnonymous{}
+	filler.Fill(foo)
+	c.Assert(calls, Equals, 0)
+	c.Assert(foo.Limits.MaxConns, Equals, 100)
+}
+
+func (s *AnonymousSuite) TestPlanAndLayers(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExampleAnonymous{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleAnonymous{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Limits.MaxConns, Equals, 100)
+
+	bar := &ExampleAnonymous{}
+	layer := MapLayer(map[string]string{"Limits.MaxIdle": "5", "Backup.Path": "/tmp"})
+	c.Assert(Apply(bar, layer, TagLayer()), IsNil)
+	c.Assert(bar.Limits.MaxConns, Equals, 100)
+	c.Assert(bar.Limits.MaxIdle, Equals, 5)
+	c.Assert(bar.Backup.Path, Equals, "/tmp")
+}
+
+func (s *AnonymousSuite) TestInspect(c *C) {
+	fields, err := ListDefaultFields(&ExampleAnonymous{})
+	c.Assert(err, IsNil)
+
+	var paths []string
+	for _, field := range fields {
+		paths = append(paths, field.Path)
+	}
+	c.Assert(paths, DeepEquals, []string{"Limits.MaxConns", "Limits.MaxIdle", "Backup.Path", "Routes[].Path", "Routes[].Methods"})
+
+	doc, err := GenerateDoc(&ExampleAnonymous{})
+	c.Assert(err, IsNil)
+	c.Assert(doc, Matches, `(?s).*\| Limits.MaxConns \| int \| `+"`100`"+` \|.*\| Routes\[\].Methods \| \[\]string \|.*`)
+
+	isDefault, err := IsDefault(&ExampleAnonymous{}, "Limits.MaxIdle")
+	c.Assert(err, IsNil)
+	c.Assert(isDefault, Equals, false)
+}
@@ -0,0 +1,210 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ApplyArgs sets the fields of the struct behind v from command line style
+// arguments, then fills every other field with its default. Arguments are
+// written --key=value or --key value, or --key for booleans, keys being the
+// lowercase dotted paths of the fields, named as in ExtractDefaults; a value
+// given apart can't start like an argument, use --offset=-x for those, but
+// negative numbers such as --offset -5 are fine. Values are parsed like
+// default tags, so --timeout=30s, --tags=[a,b] and --port=env:PORT work. The
+// arguments deriving their value from a sibling, e.g. --url=ref:Base, are set
+// once the other fields are filled, in the order they are given, see
+// PhaseDerived. A value given as an argument is kept even when it is the
+// zero value.
+//
+// Usage
+//
+//	var config Config
+//	if err := ApplyArgs(&config, os.Args[1:]); err != nil {
+//	    log.Fatal(err)
+//	}
+func ApplyArgs(v interface{}, args []string) error {
+	return getDefaultFiller().ApplyArgs(v, args)
+}
+
+// ApplyArgs is ApplyArgs using f for parsing the arguments and filling.
+func (f *Filler) ApplyArgs(v interface{}, args []string) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return invalidTargetf("godefault: expected a non-nil pointer to a struct, got %T", v)
+	}
+
+	fields := make(map[string]*FieldInfo)
+	var keys []string
+	f.walkType(value.Elem().Type(), func(tf *FieldInfo) {
+		if tf.Excluded || tf.InElement {
+			return
+		}
+		key := strings.ToLower(tf.Key())
+		fields[key] = tf
+		keys = append(keys, key)
+	})
+
+	type assignment struct {
+		tf    *FieldInfo
+		value string
+	}
+	var assignments []assignment
+	var errs []error
+	for i := 0; i < len(args); i++ {
+		key, argValue, err := parseArg(args[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		tf, ok := fields[strings.ToLower(key)]
+		if !ok {
+			err := unknownPathf("godefault: unknown argument --%s", key)
+			if suggestion := closestKey(strings.ToLower(key), keys); suggestion != "" {
+				err = fmt.Errorf("%w, did you mean --%s?", err, suggestion)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if argValue == nil {
+			if t := tf.Field.Type; t.Kind() != reflect.Bool && (t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Bool) {
+				if i+1 == len(args) || isArgName(args[i+1]) {
+					errs = append(errs, fmt.Errorf("godefault: argument --%s needs a value", key))
+					continue
+				}
+				i++
+				argValue = &args[i]
+			} else {
+				enabled := "true"
+				argValue = &enabled
+			}
+		}
+		assignments = append(assignments, assignment{tf: tf, value: *argValue})
+	}
+	if len(errs) != 0 {
+		return joinErrors(errs)
+	}
+
+	state := &fillState{assigned: make(map[string]bool), recover: true, root: value.Elem()}
+	var derived []*FieldData
+	for _, a := range assignments {
+		field := fieldByPath(value.Elem(), a.tf.Path, f, state)
+		field.TagValue = field.coerce(a.value)
+		state.assigned[a.tf.Path] = true
+		// Derived values read their siblings, filled below.
+		if isDerivedTag(field.TagValue) {
+			derived = append(derived, field)
+			continue
+		}
+		f.SetDefaultValue(field)
+	}
+	f.SetDefaultValues(f.getFieldsFromValue(value.Elem(), nil, state))
+	for _, field := range derived {
+		f.SetDefaultValue(field)
+	}
+	f.resolveLookups(state)
+
+	return joinErrors(state.errs)
+}
+
+// parseArg splits "--key=value" into its key and value, which is nil for a
+// bare "--key".
+func parseArg(arg string) (string, *string, error) {
+	key := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	if key == arg || key == "" || strings.HasPrefix(key, "-") {
+		return "", nil, fmt.Errorf("godefault: unexpected argument %q", arg)
+	}
+
+	i := strings.Index(key, "=")
+	if i < 0 {
+		return key, nil, nil
+	}
+	value := key[i+1:]
+
+	return key[:i], &value, nil
+}
+
+// isArgName reports whether arg starts like an argument, -key or --key,
+// rather than a value such as -5.
+func isArgName(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && !(arg[1] >= '0' && arg[1] <= '9' || arg[1] == '.')
+}
+
+// fieldByPath returns the field at the dotted Go path of the struct value,
+// allocating the nil pointers to structs on the way, with its parents set so
+// that errors carry the path.
+func fieldByPath(value reflect.Value, path string, f *Filler, state *fillState) *FieldData {
+	var field *FieldData
+	for _, name := range strings.Split(path, ".") {
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+
+		sf, _ := value.Type().FieldByName(name)
+		siblings := value
+		value = value.FieldByIndex(sf.Index)
+		field = &FieldData{
+			Value:    value,
+			Field:    sf,
+			TagValue: sf.Tag.Get(f.Tag),
+			Parent:   field,
+			filler:   f,
+			state:    state,
+			siblings: siblings,
+		}
+	}
+
+	return field
+}
+
+// closestKey returns the key closest to key by edit distance, or "" when none
+// is close enough to be a plausible typo.
+func closestKey(key string, keys []string) string {
+	best, bestDistance := "", len(key)/3+2
+	for _, candidate := range keys {
+		if d := editDistance(key, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+
+	return a
+}
@@ -0,0 +1,111 @@
+package godefault
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ArgsSuite struct{}
+
+var _ = Suite(&ArgsSuite{})
+
+type ExampleArgs struct {
+	Debug   bool `default:"true"`
+	Verbose bool
+	Name    string   `default:"app"`
+	Tags    []string `default:"[x]"`
+	Server  struct {
+		Timeout  time.Duration `default:"10s"`
+		BindAddr string        `yaml:"bind_addr" default:"0.0.0.0"`
+		Port     int           `default:"8080"`
+	}
+	Child  *Child
+	Hidden string `yaml:"-"`
+	Peers  []Child
+}
+
+func (s *ArgsSuite) TestApplyArgs(c *C) {
+	foo := &ExampleArgs{}
+	err := ApplyArgs(foo, []string{
+		"--server.timeout=30s",
+		"--verbose",
+		"--debug=false",
+		"--tags=[a,b]",
+		"-server.bind_addr=127.0.0.1",
+		"--Child.Name=bob",
+	})
+	c.Assert(err, IsNil)
+
+	c.Assert(foo.Server.Timeout, Equals, 30*time.Second)
+	c.Assert(foo.Verbose, Equals, true)
+	c.Assert(foo.Debug, Equals, false)
+	c.Assert(foo.Tags, DeepEquals, []string{"a", "b"})
+	c.Assert(foo.Server.BindAddr, Equals, "127.0.0.1")
+	c.Assert(*foo.Child, Equals, Child{Name: "bob", Age: 10})
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.Server.Port, Equals, 8080)
+}
+
+func (s *ArgsSuite) TestApplyArgsSeparateValues(c *C) {
+	foo := &ExampleArgs{}
+	err := ApplyArgs(foo, []string{
+		"--server.timeout", "3s",
+		"--verbose",
+		"--server.port", "-1",
+		"-name", "",
+		"--tags", "[a,b]",
+	})
+	c.Assert(err, IsNil)
+
+	c.Assert(foo.Server.Timeout, Equals, 3*time.Second)
+	c.Assert(foo.Verbose, Equals, true)
+	c.Assert(foo.Server.Port, Equals, -1)
+	c.Assert(foo.Name, Equals, "")
+	c.Assert(foo.Tags, DeepEquals, []string{"a", "b"})
+
+	err = ApplyArgs(&ExampleArgs{}, []string{"--server.timeout", "--verbose", "--name"})
+	c.Assert(err, ErrorMatches, "godefault: argument --server.timeout needs a value\n"+
+		"godefault: argument --name needs a value")
+}
+
+type ExampleArgsRef struct {
+	Greeting string
+	Password string `default:"s3cr3t"`
+	Copy     int
+	Port     int `default:"8080"`
+}
+
+func (s *ArgsSuite) TestApplyArgsRef(c *C) {
+	foo := &ExampleArgsRef{}
+	err := ApplyArgs(foo, []string{"--greeting=ref:Password", "--copy=ref:Port", "--port=9"})
+	c.Assert(err, IsNil)
+	c.Assert(foo.Greeting, Equals, "s3cr3t")
+	c.Assert(foo.Copy, Equals, 9)
+	c.Assert(foo.Port, Equals, 9)
+}
+
+func (s *ArgsSuite) TestApplyArgsErrors(c *C) {
+	foo := &ExampleArgs{}
+	err := ApplyArgs(foo, []string{"--sever.port=1", "--name", "--hidden=x", "--xyz=1", "name=foo", "---name=a"})
+	c.Assert(err, ErrorMatches, "godefault: unknown argument --sever.port, did you mean --server.port\\?\n"+
+		"godefault: argument --name needs a value\n"+
+		"godefault: unknown argument --hidden\n"+
+		"godefault: unknown argument --xyz\n"+
+		"godefault: unexpected argument \"name=foo\"\n"+
+		"godefault: unexpected argument \"---name=a\"")
+	c.Assert(foo.Name, Equals, "")
+
+	err = ApplyArgs(foo, []string{"--server.port=foo"})
+	c.Assert(err, ErrorMatches, `Server.Port: strconv.ParseInt: parsing "foo": invalid syntax`)
+
+	c.Assert(ApplyArgs(ExampleArgs{}, nil), ErrorMatches, "godefault: expected a non-nil pointer to a struct, got godefault.ExampleArgs")
+}
+
+func (s *ArgsSuite) TestEditDistance(c *C) {
+	c.Assert(editDistance("", "abc"), Equals, 3)
+	c.Assert(editDistance("kitten", "sitting"), Equals, 3)
+	c.Assert(editDistance("server.port", "server.port"), Equals, 0)
+	c.Assert(closestKey("nmae", []string{"name", "tags"}), Equals, "name")
+	c.Assert(closestKey("something", []string{"name", "tags"}), Equals, "")
+}
@@ -0,0 +1,86 @@
+package godefault
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// fillArray fills every element of an array of structs from the tags of its
+// type. A json: array default gives one object per element, overriding the
+// defaults of the fields it names like the json: default of a struct does,
+// see isInheritedDefault:
+//
+//	Nodes [3]Node `default:"json:[{},{\"ID\":2},{}]"`
+//
+// Arrays of anything else have no default.
+func fillArray(field *FieldData) {
+	t := field.Value.Type()
+	if !isStructType(t.Elem()) {
+		return
+	}
+	tags, err := arrayElementTags(t, field.TagValue)
+	field.check(err)
+	if err != nil {
+		return
+	}
+
+	filler := field.owner()
+	for i, tag := range tags {
+		elem := field.element(field.Value.Index(i), strconv.Itoa(i))
+		elem.TagValue = tag
+		filler.SetDefaultValue(elem)
+	}
+}
+
+// arrayElementTags returns the default of each element of an array of
+// structs of type t, given by its default value: a json: array of objects
+// or null, or nothing.
+func arrayElementTags(t reflect.Type, value string) ([]string, error) {
+	tags := make([]string, t.Len())
+	if value == "" {
+		return tags, nil
+	}
+	if !isJSONValue(value) {
+		return nil, fmt.Errorf("invalid %s value %q, expected %s followed by a JSON array", t, value, jsonPrefix)
+	}
+
+	raws, err := parseJSONValue(value, reflect.TypeOf([]json.RawMessage(nil)), false)
+	if err != nil {
+		return nil, err
+	}
+	elems := raws.Interface().([]json.RawMessage)
+	if len(elems) != t.Len() {
+		return nil, fmt.Errorf("invalid %s value: %d elements for a %s", jsonPrefix, len(elems), t)
+	}
+	for i, raw := range elems {
+		switch {
+		case string(raw) == "null":
+		case raw[0] == '{':
+			tags[i] = jsonPrefix + string(raw)
+		default:
+			return nil, fmt.Errorf("invalid %s value: element %d is not an object", jsonPrefix, i)
+		}
+	}
+
+	return tags, nil
+}
+
+// checkArrayValue validates the default of an array of structs of type t.
+func checkArrayValue(t reflect.Type, value string) error {
+	tags, err := arrayElementTags(t, value)
+	if err != nil {
+		return err
+	}
+	for i, tag := range tags {
+		if tag == "" {
+			continue
+		}
+		if err := checkInheritedDefaults(t.Elem(), tag); err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
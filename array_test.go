@@ -0,0 +1,64 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type ArraySuite struct{}
+
+var _ = Suite(&ArraySuite{})
+
+type ExampleNode struct {
+	ID   int    `default:"1"`
+	Role string `json:"role" default:"replica"`
+	Port int    `default:"7000"`
+}
+
+type ExampleTopology struct {
+	Nodes  [3]ExampleNode `default:"json:[{},{\"ID\":2,\"role\":\"primary\"},null]"`
+	Spares [2]ExampleNode
+}
+
+func (s *ArraySuite) TestArray(c *C) {
+	foo := &ExampleTopology{}
+	foo.Nodes[2].Port = 7002
+	c.Assert(NewFiller().FillContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Nodes, Equals, [3]ExampleNode{
+		{ID: 1, Role: "replica", Port: 7000},
+		{ID: 2, Role: "primary", Port: 7000},
+		{ID: 1, Role: "replica", Port: 7002},
+	})
+	c.Assert(foo.Spares, Equals, [2]ExampleNode{{1, "replica", 7000}, {1, "replica", 7000}})
+
+	plan, err := NewFiller().Compile(reflect.TypeOf(ExampleTopology{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleTopology{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Nodes[1].ID, Equals, 2)
+	c.Assert(bar.Spares[1].Port, Equals, 7000)
+}
+
+func (s *ArraySuite) TestArrayErrors(c *C) {
+	foo := &struct {
+		Count  [2]ExampleNode `default:"json:[{}]"`
+		Scalar [2]ExampleNode `default:"json:[{},1]"`
+		Syntax [2]ExampleNode `default:"[{},{}]"`
+		Field  [2]ExampleNode `default:"json:[{},{\"Port\":\"x\"}]"`
+	}{}
+	err := NewFiller().FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Count: invalid json: value: 1 elements for a \[2\]godefault.ExampleNode\n`+
+		`Scalar: invalid json: value: element 1 is not an object\n`+
+		`Syntax: invalid \[2\]godefault.ExampleNode value "\[{},{}\]", expected json: followed by a JSON array\n`+
+		`Field\[1\].Port: strconv.ParseInt: parsing "x": invalid syntax`)
+	c.Assert(foo.Count[0].Port, Equals, 0)
+	c.Assert(foo.Field[0].Port, Equals, 7000)
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 4)
+	c.Assert(errs[3], ErrorMatches, `Field: \[1\]: Port: strconv.ParseInt: parsing "x": invalid syntax`)
+	c.Assert(CheckDefaults(&ExampleTopology{}), HasLen, 0)
+}
@@ -0,0 +1,174 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// The min and max tags bound the defaults of a time.Duration field, e.g.
+//
+//	Timeout time.Duration `default:"env:TIMEOUT:30s" min:"1s" max:"5m"`
+//
+// A default out of range, typically read from the environment, is clamped
+// into it, which strict fills also report as an ErrOverflow. Values set
+// before the fill are left alone.
+//
+// They bound float fields the same way, written as numbers or percentages
+// whatever the default is written as:
+//
+//	Threshold float64 `default:"env:THRESHOLD:85%" min:"50%" max:"0.99"`
+
+// isDurationType reports whether t, or the type it points to, is
+// time.Duration.
+func isDurationType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == durationType
+}
+
+// durationBounds returns the min and max tags of sf, zero when missing.
+func durationBounds(sf reflect.StructField) (min, max time.Duration, err error) {
+	if tag := sf.Tag.Get("min"); tag != "" {
+		if min, err = time.ParseDuration(tag); err != nil {
+			return 0, 0, fmt.Errorf("invalid min tag: %w", err)
+		}
+	}
+	if tag := sf.Tag.Get("max"); tag != "" {
+		if max, err = time.ParseDuration(tag); err != nil {
+			return 0, 0, fmt.Errorf("invalid max tag: %w", err)
+		}
+	}
+	if min != 0 && max != 0 && min > max {
+		return 0, 0, fmt.Errorf("min tag %s exceeds max tag %s", min, max)
+	}
+
+	return min, max, nil
+}
+
+// clampDuration returns d, the default of field, moved into the range of its
+// min and max tags.
+func clampDuration(field *FieldData, d time.Duration) time.Duration {
+	min, max, err := durationBounds(field.Field)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return d
+	}
+
+	clamped, err := boundDuration(field.Field, d, min, max)
+	if err != nil && field.owner().strict {
+		field.fail(err)
+	}
+
+	return clamped
+}
+
+// boundDuration returns d clamped into [min, max], with an error when it was
+// out of range. Bounds without a tag don't apply.
+func boundDuration(sf reflect.StructField, d, min, max time.Duration) (time.Duration, error) {
+	switch {
+	case sf.Tag.Get("min") != "" && d < min:
+		return min, overflowf("duration %s is below the min %s", d, min)
+	case sf.Tag.Get("max") != "" && d > max:
+		return max, overflowf("duration %s is above the max %s", d, max)
+	}
+
+	return d, nil
+}
+
+// checkDurationBounds validates the min and max tags of sf, and that the
+// default value, when static, is in range.
+func checkDurationBounds(sf reflect.StructField, value string) error {
+	min, max, err := durationBounds(sf)
+	if err != nil || !isStaticTag(value) {
+		return err
+	}
+
+	d, err := parseDurationValue(value)
+	if err != nil {
+		return nil
+	}
+	_, err = boundDuration(sf, d, min, max)
+
+	return err
+}
+
+// isFloatType reports whether t, or the type it points to, is a float.
+func isFloatType(t reflect.Type) bool {
+	switch derefType(t).Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// floatBounds returns the min and max tags of the float field sf, zero when
+// missing.
+func floatBounds(sf reflect.StructField) (min, max float64, err error) {
+	t := derefType(sf.Type)
+	if tag := sf.Tag.Get("min"); tag != "" {
+		if min, err = parseFloatValue(tag, t); err != nil {
+			return 0, 0, fmt.Errorf("invalid min tag: %w", err)
+		}
+	}
+	if tag := sf.Tag.Get("max"); tag != "" {
+		if max, err = parseFloatValue(tag, t); err != nil {
+			return 0, 0, fmt.Errorf("invalid max tag: %w", err)
+		}
+	}
+	if sf.Tag.Get("min") != "" && sf.Tag.Get("max") != "" && min > max {
+		return 0, 0, fmt.Errorf("min tag %g exceeds max tag %g", min, max)
+	}
+
+	return min, max, nil
+}
+
+// clampFloat returns n, the default of field, moved into the range of its min
+// and max tags.
+func clampFloat(field *FieldData, n float64) float64 {
+	min, max, err := floatBounds(field.Field)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return n
+	}
+
+	clamped, err := boundFloat(field.Field, n, min, max)
+	if err != nil && field.owner().strict {
+		field.fail(err)
+	}
+
+	return clamped
+}
+
+// boundFloat returns n clamped into [min, max], with an error when it was out
+// of range. Bounds without a tag don't apply.
+func boundFloat(sf reflect.StructField, n, min, max float64) (float64, error) {
+	switch {
+	case sf.Tag.Get("min") != "" && n < min:
+		return min, overflowf("value %g is below the min %g", n, min)
+	case sf.Tag.Get("max") != "" && n > max:
+		return max, overflowf("value %g is above the max %g", n, max)
+	}
+
+	return n, nil
+}
+
+// checkFloatBounds validates the min and max tags of the float field sf, and
+// that the default value, when static, is in range.
+func checkFloatBounds(sf reflect.StructField, value string) error {
+	min, max, err := floatBounds(sf)
+	if err != nil || !isStaticTag(value) {
+		return err
+	}
+
+	n, err := parseFloatValue(value, derefType(sf.Type))
+	if err != nil {
+		return nil
+	}
+	_, err = boundFloat(sf, n, min, max)
+
+	return err
+}
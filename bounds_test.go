@@ -0,0 +1,108 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type BoundsSuite struct{}
+
+var _ = Suite(&BoundsSuite{})
+
+type ExampleBounds struct {
+	Timeout time.Duration  `default:"30s" min:"1s" max:"5m"`
+	Short   time.Duration  `default:"1ms" min:"1s"`
+	Long    *time.Duration `default:"1h" max:"5m"`
+	Env     time.Duration  `default:"env:GODEFAULT_TEST_TIMEOUT:10s" min:"1s" max:"1m"`
+	Preset  time.Duration  `default:"30s" max:"5m"`
+}
+
+func (s *BoundsSuite) TestClamp(c *C) {
+	os.Setenv("GODEFAULT_TEST_TIMEOUT", "24h")
+	defer os.Unsetenv("GODEFAULT_TEST_TIMEOUT")
+
+	foo := &ExampleBounds{Preset: time.Hour}
+	SetDefaults(foo)
+
+	c.Assert(foo.Timeout, Equals, 30*time.Second)
+	c.Assert(foo.Short, Equals, time.Second)
+	c.Assert(*foo.Long, Equals, 5*time.Minute)
+	c.Assert(foo.Env, Equals, time.Minute)
+	c.Assert(foo.Preset, Equals, time.Hour)
+
+	plan, err := Compile(reflect.TypeOf(ExampleBounds{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleBounds{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Short, Equals, time.Second)
+}
+
+func (s *BoundsSuite) TestClampStrict(c *C) {
+	err := SetDefaultsContext(context.Background(), &ExampleBounds{}, WithStrict())
+	c.Assert(err, ErrorMatches, `Short: duration 1ms is below the min 1s\nLong: duration 1h0m0s is above the max 5m0s`)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+
+	c.Assert(SetDefaultsContext(context.Background(), &ExampleBounds{}), IsNil)
+}
+
+func (s *BoundsSuite) TestInvalidBounds(c *C) {
+	foo := &struct {
+		Invalid  time.Duration `default:"1s" min:"soon"`
+		Reversed time.Duration `default:"1s" min:"1m" max:"1s"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Invalid: invalid min tag: .*\nReversed: min tag 1m0s exceeds max tag 1s`)
+	c.Assert(foo.Invalid, Equals, time.Second)
+
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+}
+
+type ExampleFloatBounds struct {
+	Threshold float64   `default:"85%" min:"0.5" max:"99%"`
+	Low       float64   `default:"0.1" min:"50%"`
+	High      *float32  `default:"150%" max:"1"`
+	Env       float64   `default:"env:GODEFAULT_TEST_THRESHOLD:0.5" min:"10%" max:"90%"`
+	Steps     []float64 `default:"[10%,200%]" max:"1"`
+}
+
+func (s *BoundsSuite) TestClampFloat(c *C) {
+	os.Setenv("GODEFAULT_TEST_THRESHOLD", "5%")
+	defer os.Unsetenv("GODEFAULT_TEST_THRESHOLD")
+
+	foo := &ExampleFloatBounds{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Threshold, Equals, 0.85)
+	c.Assert(foo.Low, Equals, 0.5)
+	c.Assert(*foo.High, Equals, float32(1))
+	c.Assert(foo.Env, Equals, 0.1)
+	// The bounds of a field don't apply to its elements.
+	c.Assert(foo.Steps, DeepEquals, []float64{0.1, 2})
+
+	err := SetDefaultsContext(context.Background(), &ExampleFloatBounds{}, WithStrict())
+	c.Assert(err, ErrorMatches, `Low: value 0.1 is below the min 0.5\nHigh: value 1.5 is above the max 1\nEnv: value 0.05 is below the min 0.1`)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+
+	errs := CheckDefaults(&ExampleFloatBounds{})
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `Low: value 0.1 is below the min 0.5`)
+
+	bar := &struct {
+		Invalid  float64 `default:"1" min:"low"`
+		Reversed float64 `default:"1" min:"90%" max:"10%"`
+	}{}
+	err = SetDefaultsContext(context.Background(), bar)
+	c.Assert(err, ErrorMatches, `Invalid: invalid min tag: .*\nReversed: min tag 0.9 exceeds max tag 0.1`)
+	c.Assert(bar.Invalid, Equals, 1.0)
+}
+
+func (s *BoundsSuite) TestCheckDefaultsBounds(c *C) {
+	errs := CheckDefaults(&ExampleBounds{})
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `Short: duration 1ms is below the min 1s`)
+	c.Assert(errs[1], ErrorMatches, `Long: .*`)
+}
@@ -0,0 +1,76 @@
+package godefault
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+)
+
+// filePrefix introduces a []byte default read from a file when the field is
+// filled, relative paths being relative to the working directory:
+//
+//	CA []byte `default:"file:/etc/ssl/ca.pem"`
+//
+// A file that can't be read fails the field, which stays nil.
+const filePrefix = "file:"
+
+// emptyBytes is the default of an empty, non-nil []byte.
+const emptyBytes = "[]"
+
+// fillBytes fills a []byte field, or a field of a named type such as
+// type Token []byte, from its default:
+//
+//	nil, default     the parsed default, see parseBytesValue and filePrefix
+//	nil, "[]"        an empty, non-nil slice, as does "zero"
+//	empty, non-nil   left alone, it was set on purpose
+//	populated        left alone
+func fillBytes(field *FieldData) {
+	if field.Value.Bytes() != nil || field.TagValue == "" {
+		return
+	}
+	if field.TagValue == emptyBytes {
+		field.Value.Set(reflect.MakeSlice(field.Value.Type(), 0, 0))
+		return
+	}
+
+	var data []byte
+	var err error
+	// Assigned values can't read files.
+	if strings.HasPrefix(field.TagValue, filePrefix) && !field.literal {
+		data, err = ioutil.ReadFile(field.TagValue[len(filePrefix):])
+	} else {
+		data, err = parseBytesValue(field.TagValue)
+	}
+	field.check(err)
+	if err == nil {
+		field.Value.SetBytes(data)
+	}
+}
+
+// checkBytesValue validates a []byte default. Files are only read at fill
+// time, their path has to be given.
+func checkBytesValue(value string) error {
+	if strings.HasPrefix(value, filePrefix) {
+		if value == filePrefix {
+			return fmt.Errorf("invalid file value %q, expected %sPATH", value, filePrefix)
+		}
+		return nil
+	}
+	if value == emptyBytes {
+		return nil
+	}
+	_, err := parseBytesValue(value)
+
+	return err
+}
+
+// isBytesType reports whether t, or the type it points to, is filled by
+// fillBytes.
+func isBytesType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
@@ -0,0 +1,77 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type BytesSuite struct{}
+
+var _ = Suite(&BytesSuite{})
+
+type ExampleBytesToken []byte
+
+type ExampleBytes struct {
+	Raw       []byte             `default:"raw"`
+	Hex       []byte             `default:"hex:00ff"`
+	File      []byte             `default:"file:testdata/ca.pem"`
+	Empty     []byte             `default:"[]"`
+	Zero      []byte             `default:"zero"`
+	Preset    []byte             `default:"raw"`
+	SetEmpty  []byte             `default:"raw"`
+	Token     ExampleBytesToken  `default:"hex:0102"`
+	Pointer   *ExampleBytesToken `default:"[]"`
+	Untagged  []byte
+	NilTagged []byte `default:""`
+}
+
+func (s *BytesSuite) TestSetDefaultsBytes(c *C) {
+	foo := &ExampleBytes{Preset: []byte("set"), SetEmpty: []byte{}}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Raw, DeepEquals, []byte("raw"))
+	c.Assert(foo.Hex, DeepEquals, []byte{0, 0xff})
+	c.Assert(string(foo.File), Equals, "-----BEGIN CERTIFICATE-----\n")
+	c.Assert(foo.Empty, NotNil)
+	c.Assert(foo.Empty, HasLen, 0)
+	c.Assert(foo.Zero, NotNil)
+	c.Assert(foo.Zero, HasLen, 0)
+	c.Assert(foo.Preset, DeepEquals, []byte("set"))
+	c.Assert(foo.SetEmpty, DeepEquals, []byte{})
+	c.Assert(foo.Token, DeepEquals, ExampleBytesToken{1, 2})
+	c.Assert(*foo.Pointer, DeepEquals, ExampleBytesToken{})
+	c.Assert(foo.Untagged, IsNil)
+	c.Assert(foo.NilTagged, IsNil)
+
+	plan, err := Compile(reflect.TypeOf(ExampleBytes{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleBytes{Preset: []byte("set"), SetEmpty: []byte{}}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar, DeepEquals, foo)
+
+	c.Assert(CheckDefaults(&ExampleBytes{}), HasLen, 0)
+}
+
+func (s *BytesSuite) TestSetDefaultsBytesErrors(c *C) {
+	foo := &struct {
+		Hex     []byte `default:"hex:0g"`
+		Missing []byte `default:"file:testdata/missing.pem"`
+		NoPath  []byte `default:"file:"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Hex: invalid hex value "hex:0g": .*\nMissing: open testdata/missing.pem: .*\nNoPath: open : .*`)
+	c.Assert(foo.Hex, IsNil)
+	c.Assert(foo.Missing, IsNil)
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[1], ErrorMatches, `NoPath: invalid file value "file:", expected file:PATH`)
+
+	filler := NewFiller()
+	filler.Restrict(FeatureEnv)
+	c.Assert(filler.CheckDefaults(&ExampleBytes{}), HasLen, 1)
+	c.Assert(TagFeatures(reflect.TypeOf([]byte(nil)), "file:ca.pem"), DeepEquals, []Feature{FeatureFile})
+	c.Assert(TagFeatures(reflect.TypeOf(""), "file:ca.pem"), IsNil)
+}
@@ -0,0 +1,49 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithMakeChannels makes the fills set the nil chan fields with a default to
+// a new channel, the default being its buffer size, 0 for an unbuffered one:
+//
+//	Jobs chan Job `default:"256"`
+//
+// Directional channels, <-chan T and chan<- T, can't be defaulted. Without
+// the option chan fields are left alone, tagged or not.
+func WithMakeChannels(enabled bool) Option {
+	return func(f *Filler) {
+		f.makeChannels = enabled
+	}
+}
+
+// parseChanValue parses the buffer size of a channel of type t.
+func parseChanValue(t reflect.Type, value string) (int, error) {
+	if t.ChanDir() != reflect.BothDir {
+		return 0, fmt.Errorf("%s is a directional channel, expected a chan %s", t, t.Elem())
+	}
+	n, err := parseIntValue(value, reflect.TypeOf(0))
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("invalid buffer size %d, expected a non-negative integer", n)
+	}
+
+	return int(n), nil
+}
+
+// fillChan makes the channel of field when its Filler is WithMakeChannels.
+func fillChan(field *FieldData) {
+	if !field.owner().makeChannels || field.TagValue == "" {
+		return
+	}
+
+	n, err := parseChanValue(field.Value.Type(), field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return
+	}
+	field.Value.Set(reflect.MakeChan(field.Value.Type(), n))
+}
@@ -0,0 +1,65 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type ChanSuite struct{}
+
+var _ = Suite(&ChanSuite{})
+
+type ExampleChan struct {
+	Jobs     chan string   `default:"256"`
+	Done     chan struct{} `default:"0"`
+	Results  chan int      `default:"env:GODEFAULT_TEST_UNSET:8"`
+	Untagged chan int
+}
+
+func (s *ChanSuite) TestWithMakeChannels(c *C) {
+	foo := &ExampleChan{}
+	c.Assert(NewFiller(WithMakeChannels(true)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(cap(foo.Jobs), Equals, 256)
+	c.Assert(foo.Done, NotNil)
+	c.Assert(cap(foo.Done), Equals, 0)
+	c.Assert(cap(foo.Results), Equals, 8)
+	c.Assert(foo.Untagged, IsNil)
+
+	// Set channels are kept.
+	jobs := make(chan string)
+	foo = &ExampleChan{Jobs: jobs}
+	c.Assert(NewFiller(WithMakeChannels(true)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Jobs, Equals, jobs)
+
+	// Every fill makes channels of its own.
+	plan, err := Compile(reflect.TypeOf(ExampleChan{}), WithMakeChannels(true))
+	c.Assert(err, IsNil)
+	first, second := &ExampleChan{}, &ExampleChan{}
+	c.Assert(plan.Apply(first), IsNil)
+	c.Assert(plan.Apply(second), IsNil)
+	c.Assert(cap(first.Jobs), Equals, 256)
+	c.Assert(first.Jobs != second.Jobs, Equals, true)
+
+	bar := &ExampleChan{}
+	c.Assert(SetDefaultsContext(context.Background(), bar), IsNil)
+	c.Assert(bar.Jobs, IsNil)
+	c.Assert(CheckDefaults(bar), HasLen, 0)
+}
+
+func (s *ChanSuite) TestWithMakeChannelsErrors(c *C) {
+	foo := &struct {
+		Receive  <-chan int `default:"1"`
+		Send     chan<- int `default:"1"`
+		Negative chan int   `default:"-1"`
+		Invalid  chan int   `default:"many"`
+	}{}
+	err := NewFiller(WithMakeChannels(true)).FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Receive: <-chan int is a directional channel, expected a chan int\n`+
+		`Send: chan<- int is a directional channel, expected a chan int\n`+
+		`Negative: invalid buffer size -1, expected a non-negative integer\n`+
+		`Invalid: .*invalid syntax`)
+	c.Assert(foo.Receive, IsNil)
+	c.Assert(CheckDefaults(foo), HasLen, 4)
+}
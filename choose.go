@@ -0,0 +1,195 @@
+package godefault
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// choosePrefix introduces a default picked at random among weighted
+// branches, e.g. for canary rollouts:
+//
+//	Engine string `default:"choose:v1=90,v2=10"` // v1 nine times out of ten
+//
+// The weights are non-negative integers, at least one of them positive, and
+// the branches are defaults like any other, split on their last equal sign,
+// their commas escaped as in slice defaults, e.g. choose:a|,b=1,c=1. The
+// draw uses the source of WithRandSource, or math/rand. The branch picked is
+// reported, see FieldReport.Choice.
+//
+// A seedfrom tag makes the pick stable, hashing a seed rather than drawing:
+// the value of the sibling field it names, or the hostname for "hostname".
+// The same seed always picks the same branch of the same weights:
+//
+//	ID     string `default:"env:INSTANCE_ID"`
+//	Engine string `default:"choose:v1=90,v2=10" seedfrom:"ID"`
+//	Cache  string `default:"choose:lru=50,arc=50" seedfrom:"hostname"`
+//
+// Like ref: defaults, choose: ones are filled once their siblings are, see
+// PhaseDerived.
+const choosePrefix = "choose:"
+
+// seedFromTag names the tag seeding the pick of a choose: default, see
+// choosePrefix.
+const seedFromTag = "seedfrom"
+
+// hostnameSeed is the seedfrom value seeding a pick with the hostname.
+const hostnameSeed = "hostname"
+
+type chooseBranch struct {
+	value  string
+	weight uint64
+}
+
+func isChooseRef(value string) bool {
+	return strings.HasPrefix(value, choosePrefix)
+}
+
+// parseChooseRef splits a choose: default into its branches, reporting the
+// sum of their weights.
+func parseChooseRef(value string) ([]chooseBranch, uint64, error) {
+	elems, err := splitTagList(value[len(choosePrefix):], false, defaultSeparator)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid choose value %q: %w", value, err)
+	}
+
+	branches := make([]chooseBranch, 0, len(elems))
+	var total uint64
+	for _, elem := range elems {
+		i := strings.LastIndexByte(elem.value, '=')
+		if i < 0 {
+			return nil, 0, fmt.Errorf("invalid choose branch %q, expected value=weight", elem.value)
+		}
+		weight, err := strconv.ParseUint(elem.value[i+1:], 10, 32)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid choose branch %q: weight %q is not a non-negative integer", elem.value, elem.value[i+1:])
+		}
+		branches = append(branches, chooseBranch{value: elem.value[:i], weight: weight})
+		total += weight
+	}
+	if total == 0 {
+		return nil, 0, fmt.Errorf("invalid choose value %q: the weights sum to zero", value)
+	}
+
+	return branches, total, nil
+}
+
+// pick returns the branch at position n, from 0 to the total weight.
+func pick(branches []chooseBranch, n uint64) chooseBranch {
+	for _, branch := range branches {
+		if n < branch.weight {
+			return branch
+		}
+		n -= branch.weight
+	}
+
+	return branches[len(branches)-1]
+}
+
+// resolveChooseRef picks a branch of the choose: default of field, failing
+// the field when the default doesn't parse or its seed can't be read.
+func resolveChooseRef(field *FieldData) (string, bool) {
+	branches, total, err := parseChooseRef(field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return "", false
+	}
+
+	seed, seeded, err := chooseSeed(field)
+	if err != nil {
+		field.fail(err)
+		return "", false
+	}
+	var n uint64
+	if seeded {
+		h := fnv.New64a()
+		h.Write([]byte(seed))
+		n = h.Sum64() % total
+	} else {
+		n = uint64(field.owner().random().Int63n(int64(total)))
+	}
+
+	return pick(branches, n).value, true
+}
+
+// chooseSeed returns the seed named by the seedFromTag of field, if any.
+func chooseSeed(field *FieldData) (string, bool, error) {
+	name, ok := field.Field.Tag.Lookup(seedFromTag)
+	if !ok || field.notTag {
+		return "", false, nil
+	}
+	if name == hostnameSeed {
+		host, err := hostname()
+		if err != nil {
+			return "", false, &ResolverError{Prefix: choosePrefix, Err: err}
+		}
+		return host, true, nil
+	}
+
+	if !field.siblings.IsValid() {
+		return "", false, unknownPathf("%s %q: no such sibling field", seedFromTag, name)
+	}
+	sf, ok := field.siblings.Type().FieldByName(name)
+	if !ok || sf.PkgPath != "" || len(sf.Index) != 1 {
+		return "", false, unknownPathf("%s %q: no such sibling field", seedFromTag, name)
+	}
+
+	return fmt.Sprint(field.siblings.Field(sf.Index[0]).Interface()), true, nil
+}
+
+// checkChooseRef validates the choose: default of a field, each branch
+// checked by check.
+func checkChooseRef(value string, check func(branch string) error) error {
+	branches, _, err := parseChooseRef(value)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		if err := check(branch.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockedRand is a rand.Rand safe for concurrent fills.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (r *lockedRand) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.r.Int63n(n)
+}
+
+// globalRand draws from the math/rand source, safe for concurrent use.
+type globalRand struct{}
+
+func (globalRand) Int63n(n int64) int64 {
+	return rand.Int63n(n)
+}
+
+// random returns the source of the draws of f, see WithRandSource.
+func (f *Filler) random() interface{ Int63n(n int64) int64 } {
+	if f.rand == nil {
+		return globalRand{}
+	}
+
+	return f.rand
+}
+
+// WithRandSource draws the branches of choose: defaults from src rather than
+// from math/rand, e.g. a seeded source for reproducible tests. The Filler
+// serializes its use of src.
+func WithRandSource(src rand.Source) Option {
+	return func(f *Filler) {
+		f.rand = &lockedRand{r: rand.New(src)}
+	}
+}
@@ -0,0 +1,114 @@
+package godefault
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type ChooseSuite struct{}
+
+var _ = Suite(&ChooseSuite{})
+
+type ExampleChoose struct {
+	Engine  string `default:"choose:v1=90,v2=10"`
+	Workers int    `default:"choose:2=1,4=1,8=2"`
+	Mode    string `default:"choose:a|,b=1,c=0"`
+	Region  string `default:"choose:env:GODEFAULT_TEST_REGION:eu=1"`
+}
+
+func (s *ChooseSuite) TestChoose(c *C) {
+	os.Setenv("GODEFAULT_TEST_REGION", "us")
+	defer os.Unsetenv("GODEFAULT_TEST_REGION")
+
+	counts := map[string]int{}
+	filler := NewFiller(WithRandSource(rand.NewSource(1)))
+	for i := 0; i < 1000; i++ {
+		foo := &ExampleChoose{}
+		c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+		counts[foo.Engine]++
+
+		c.Assert(foo.Workers == 2 || foo.Workers == 4 || foo.Workers == 8, Equals, true, Commentf("%d", foo.Workers))
+		c.Assert(foo.Mode, Equals, "a,b")
+		c.Assert(foo.Region, Equals, "us")
+	}
+	c.Assert(counts, HasLen, 2)
+	c.Assert(counts["v1"] > 850 && counts["v1"] < 950, Equals, true, Commentf("%v", counts))
+
+	// The same source draws the same branches.
+	first, second := &ExampleChoose{}, &ExampleChoose{}
+	c.Assert(NewFiller(WithRandSource(rand.NewSource(7))).FillContext(context.Background(), first), IsNil)
+	c.Assert(NewFiller(WithRandSource(rand.NewSource(7))).FillContext(context.Background(), second), IsNil)
+	c.Assert(first, DeepEquals, second)
+
+	c.Assert(EnvKeys(&ExampleChoose{}), DeepEquals, []string{"GODEFAULT_TEST_REGION"})
+	c.Assert(TagFeatures(reflect.TypeOf(""), "choose:env:A:a=1,kv:b=1"), DeepEquals, []Feature{FeatureRandom, FeatureEnv, FeatureKV})
+	c.Assert(TagPhase("choose:a=1"), Equals, PhaseDerived)
+	c.Assert(CheckDefaults(&ExampleChoose{}), HasLen, 0)
+}
+
+type ExampleChooseSeed struct {
+	Engine string `default:"choose:v1=50,v2=50" seedfrom:"ID"`
+	ID     string `default:"instance-42"`
+	Cache  string `default:"choose:lru=50,arc=50" seedfrom:"hostname"`
+}
+
+func (s *ChooseSuite) TestChooseSeed(c *C) {
+	defer withHostname("prod-eu-1", nil)()
+
+	first := &ExampleChooseSeed{}
+	c.Assert(SetDefaultsContext(context.Background(), first), IsNil)
+	c.Assert(first.Engine == "v1" || first.Engine == "v2", Equals, true)
+	for i := 0; i < 20; i++ {
+		foo := &ExampleChooseSeed{}
+		c.Assert(NewFiller(WithRandSource(rand.NewSource(int64(i)))).FillContext(context.Background(), foo), IsNil)
+		c.Assert(foo, DeepEquals, first)
+	}
+
+	engines := map[string]bool{}
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		foo := &ExampleChooseSeed{ID: id}
+		c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+		engines[foo.Engine] = true
+	}
+	c.Assert(engines, HasLen, 2)
+}
+
+func (s *ChooseSuite) TestChooseReport(c *C) {
+	foo := &struct {
+		Engine string `default:"choose:v1=1,v2=0"`
+		Token  string `default:"choose:abc=1" secret:"true"`
+		Plain  string `default:"plain"`
+	}{}
+	report := &FillReport{}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Engine, Equals, "v1")
+	c.Assert(foo.Token, Equals, "abc")
+	c.Assert(report.Fields, HasLen, 3)
+	c.Assert(report.Fields[0].Choice, Equals, "")
+	c.Assert(report.Fields[1].Choice, Equals, "v1")
+	c.Assert(report.Fields[2].Choice, Equals, secretValue)
+}
+
+func (s *ChooseSuite) TestChooseErrors(c *C) {
+	foo := &struct {
+		Zero     string `default:"choose:a=0,b=0"`
+		Negative string `default:"choose:a=-1,b=2"`
+		NoWeight string `default:"choose:a,b=1"`
+		Invalid  int    `default:"choose:x=1"`
+		Sibling  string `default:"choose:a=1,b=1" seedfrom:"Missing"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Zero: invalid choose value "choose:a=0,b=0": the weights sum to zero\n`+
+		`Negative: invalid choose branch "a=-1": weight "-1" is not a non-negative integer\n`+
+		`NoWeight: invalid choose branch "a", expected value=weight\n`+
+		`Invalid: .*invalid syntax\n`+
+		`Sibling: seedfrom "Missing": no such sibling field`)
+	c.Assert(foo.Zero, Equals, "")
+
+	c.Assert(CheckDefaults(foo), HasLen, 4)
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sonnt85/godefault"
+)
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// knownTypes are the named types the filler handles by type, mapped to the
+// real types by import path and name.
+var knownTypes = map[string]reflect.Type{
+	"time.Duration":            reflect.TypeOf(time.Duration(0)),
+	"time.Time":                reflect.TypeOf(time.Time{}),
+	"regexp.Regexp":            reflect.TypeOf(regexp.Regexp{}),
+	"database/sql.NullString":  reflect.TypeOf(sql.NullString{}),
+	"database/sql.NullInt64":   reflect.TypeOf(sql.NullInt64{}),
+	"database/sql.NullInt32":   reflect.TypeOf(sql.NullInt32{}),
+	"database/sql.NullFloat64": reflect.TypeOf(sql.NullFloat64{}),
+	"database/sql.NullBool":    reflect.TypeOf(sql.NullBool{}),
+	"database/sql.NullTime":    reflect.TypeOf(sql.NullTime{}),
+	"sync.Map":                 reflect.TypeOf(sync.Map{}),
+
+	"github.com/sonnt85/godefault.HostPort": reflect.TypeOf(godefault.HostPort{}),
+}
+
+// errOpaque is returned, when checking, for the named types a loader can
+// only rebuild by shape.
+var errOpaque = errors.New("can't be rebuilt, not checked")
+
+var basicTypes = map[string]reflect.Type{
+	"bool":       reflect.TypeOf(false),
+	"string":     reflect.TypeOf(""),
+	"int":        reflect.TypeOf(int(0)),
+	"int8":       reflect.TypeOf(int8(0)),
+	"int16":      reflect.TypeOf(int16(0)),
+	"int32":      reflect.TypeOf(int32(0)),
+	"rune":       reflect.TypeOf(rune(0)),
+	"int64":      reflect.TypeOf(int64(0)),
+	"uint":       reflect.TypeOf(uint(0)),
+	"uint8":      reflect.TypeOf(uint8(0)),
+	"byte":       reflect.TypeOf(byte(0)),
+	"uint16":     reflect.TypeOf(uint16(0)),
+	"uint32":     reflect.TypeOf(uint32(0)),
+	"uint64":     reflect.TypeOf(uint64(0)),
+	"uintptr":    reflect.TypeOf(uintptr(0)),
+	"float32":    reflect.TypeOf(float32(0)),
+	"float64":    reflect.TypeOf(float64(0)),
+	"complex64":  reflect.TypeOf(complex64(0)),
+	"complex128": reflect.TypeOf(complex128(0)),
+	"error":      interfaceType,
+	"any":        interfaceType,
+}
+
+// loader reads Go packages from source and rebuilds their struct types with
+// reflect.StructOf, field names and tags included, so that the runtime
+// inspection functions can run over them without importing the package.
+// Named types lose their name and keep their underlying shape, except for
+// the knownTypes which are mapped to the real types.
+//
+// A type rebuilt by shape may not be filled the way the real one is: a named
+// int may have a FuncByType registered for it, a standard library struct may
+// be parsed from a string. A checking loader skips the fields of such types,
+// the named non-struct types and the standard library structs, with a
+// warning, rather than report their defaults as invalid.
+//
+// Packages are found with go/build and parsed with go/parser, not with
+// golang.org/x/tools/go/packages: the command shares the module of the
+// library, whose users would all get the dependency, and the versions of
+// x/tools that still build with the Go version of the module are no longer
+// maintained. go/build resolves the import paths in module mode too, by
+// running the go command, and the loader only needs the syntax of the type
+// declarations, not their type checking.
+type loader struct {
+	ctx      build.Context
+	srcDir   string
+	checking bool
+	fset     *token.FileSet
+	pkgs     map[string]*pkgSource
+	warnings []string
+}
+
+type pkgSource struct {
+	path      string
+	goroot    bool
+	specs     map[string]*ast.TypeSpec
+	files     map[string]*ast.File
+	types     map[string]reflect.Type
+	resolving map[string]bool
+}
+
+func newLoader(srcDir string) *loader {
+	return &loader{
+		ctx:    build.Default,
+		srcDir: srcDir,
+		fset:   token.NewFileSet(),
+		pkgs:   make(map[string]*pkgSource),
+	}
+}
+
+// Load returns the rebuilt type named name in the package importPath.
+func (l *loader) Load(importPath, name string) (reflect.Type, error) {
+	pkg, err := l.pkg(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := l.named(pkg, name)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s.%s is not a struct type", importPath, name)
+	}
+
+	return t, nil
+}
+
+func (l *loader) pkg(importPath string) (*pkgSource, error) {
+	if pkg, ok := l.pkgs[importPath]; ok {
+		return pkg, nil
+	}
+
+	bp, err := l.ctx.Import(importPath, l.srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &pkgSource{
+		path:      importPath,
+		goroot:    bp.Goroot,
+		specs:     make(map[string]*ast.TypeSpec),
+		files:     make(map[string]*ast.File),
+		types:     make(map[string]reflect.Type),
+		resolving: make(map[string]bool),
+	}
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		file, err := parser.ParseFile(l.fset, filepath.Join(bp.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				pkg.specs[ts.Name.Name] = ts
+				pkg.files[ts.Name.Name] = file
+			}
+		}
+	}
+
+	l.pkgs[importPath] = pkg
+	return pkg, nil
+}
+
+func (l *loader) named(pkg *pkgSource, name string) (reflect.Type, error) {
+	if t, ok := pkg.types[name]; ok {
+		return t, nil
+	}
+	// A type referring back to itself, e.g. through a pointer, cannot be
+	// rebuilt with reflect; the filler never descends there anyway.
+	if pkg.resolving[name] {
+		return interfaceType, nil
+	}
+
+	spec, ok := pkg.specs[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in %s", name, pkg.path)
+	}
+
+	pkg.resolving[name] = true
+	defer delete(pkg.resolving, name)
+
+	t, err := l.typeOf(pkg, pkg.files[name], spec.Type)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", pkg.path, name, err)
+	}
+
+	pkg.types[name] = t
+	return t, nil
+}
+
+// field returns the rebuilt named type of a field, or errOpaque when checking
+// and the type is only rebuilt by shape.
+func (l *loader) field(pkg *pkgSource, name string) (reflect.Type, error) {
+	cyclic := pkg.resolving[name]
+	t, err := l.named(pkg, name)
+	if err != nil {
+		return nil, err
+	}
+	if l.checking && !cyclic && (pkg.goroot || t.Kind() != reflect.Struct) {
+		return nil, fmt.Errorf("type %s.%s %w", pkg.path, name, errOpaque)
+	}
+
+	return t, nil
+}
+
+func (l *loader) typeOf(pkg *pkgSource, file *ast.File, expr ast.Expr) (reflect.Type, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if t, ok := basicTypes[e.Name]; ok {
+			return t, nil
+		}
+		return l.field(pkg, e.Name)
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type expression")
+		}
+		importPath, err := l.importPath(file, x.Name)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := knownTypes[importPath+"."+e.Sel.Name]; ok {
+			return t, nil
+		}
+		other, err := l.pkg(importPath)
+		if err != nil {
+			return nil, err
+		}
+		return l.field(other, e.Sel.Name)
+	case *ast.ParenExpr:
+		return l.typeOf(pkg, file, e.X)
+	case *ast.StarExpr:
+		elem, err := l.typeOf(pkg, file, e.X)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PtrTo(elem), nil
+	case *ast.ArrayType:
+		elem, err := l.typeOf(pkg, file, e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		if e.Len == nil {
+			return reflect.SliceOf(elem), nil
+		}
+		lit, ok := e.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return nil, fmt.Errorf("unsupported array length")
+		}
+		n, err := strconv.Atoi(lit.Value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ArrayOf(n, elem), nil
+	case *ast.MapType:
+		key, err := l.typeOf(pkg, file, e.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !key.Comparable() {
+			return nil, fmt.Errorf("invalid map key type %s", key)
+		}
+		elem, err := l.typeOf(pkg, file, e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(key, elem), nil
+	case *ast.InterfaceType:
+		return interfaceType, nil
+	case *ast.StructType:
+		return l.structOf(pkg, file, e)
+	}
+
+	return nil, fmt.Errorf("unsupported type expression %T", expr)
+}
+
+func (l *loader) structOf(pkg *pkgSource, file *ast.File, st *ast.StructType) (t reflect.Type, err error) {
+	// StructOf panics on shapes it can't build, such as duplicated names.
+	defer func() {
+		if r := recover(); r != nil {
+			t, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	var fields []reflect.StructField
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		names := f.Names
+		embedded := len(names) == 0
+		if embedded {
+			names = []*ast.Ident{embeddedName(f.Type)}
+		}
+
+		for _, name := range names {
+			// Unexported fields can't be set by the filler either.
+			if name == nil || !name.IsExported() {
+				continue
+			}
+			t, err := l.typeOf(pkg, file, f.Type)
+			if err != nil {
+				l.warnings = append(l.warnings, fmt.Sprintf("%s: field %s skipped: %v",
+					l.fset.Position(f.Pos()), name.Name, err))
+				continue
+			}
+			fields = append(fields, reflect.StructField{
+				Name:      name.Name,
+				Type:      t,
+				Tag:       tag,
+				Anonymous: embedded && t.Kind() == reflect.Struct,
+			})
+		}
+	}
+
+	return reflect.StructOf(fields), nil
+}
+
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	}
+
+	return nil
+}
+
+// importPath resolves the package name used in a selector expression to the
+// import path of the file's matching import.
+func (l *loader) importPath(file *ast.File, name string) (string, error) {
+	var candidates []string
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return "", err
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return p, nil
+			}
+			continue
+		}
+		if path.Base(p) == name {
+			return p, nil
+		}
+		candidates = append(candidates, p)
+	}
+
+	// The package name doesn't have to match the last path element.
+	for _, p := range candidates {
+		if bp, err := l.ctx.Import(p, l.srcDir, build.ImportComment); err == nil && bp.Name == name {
+			return p, nil
+		}
+	}
+
+	return "", fmt.Errorf("package %s is not imported", name)
+}
@@ -0,0 +1,115 @@
+// Command godefault inspects the default tags of a struct type without
+// importing or running the package that declares it.
+//
+// Usage
+//
+//	godefault -type github.com/me/app/config.Config [-format table|schema] [-tag default]
+//
+// The package is read from source and its tags are checked with the same
+// parsers SetDefaults uses. The default table (or JSON schema) is written to
+// stdout; invalid tags are reported on stderr and make the command exit with
+// status 1. Tags resolved at fill time, such as envs| mappings, are shown raw.
+// The fields of named types that can't be rebuilt without importing their
+// package, such as a named int or a standard library struct other than
+// time.Time, regexp.Regexp and the sql.Null* wrappers, are documented by shape
+// but not checked, with a warning.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/sonnt85/godefault"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("godefault", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	typeName := flags.String("type", "", "type to inspect, as <import path>.<name>")
+	format := flags.String("format", "table", "output format: table or schema")
+	tag := flags.String("tag", "default", "struct tag holding the default values")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	importPath, name, err := splitTypeName(*typeName)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		flags.Usage()
+		return 2
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	t, err := newLoader(wd).Load(importPath, name)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	// The checked type leaves out the fields it can't rebuild faithfully,
+	// its warnings cover those of t.
+	checker := newLoader(wd)
+	checker.checking = true
+	checked, err := checker.Load(importPath, name)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	for _, warning := range checker.warnings {
+		fmt.Fprintln(stderr, "warning:", warning)
+	}
+
+	v := reflect.New(t).Interface()
+	switch *format {
+	case "table":
+		doc, err := godefault.GenerateDoc(v, *tag)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		fmt.Fprint(stdout, doc)
+	case "schema":
+		schema, err := godefault.GenerateJSONSchema(v, *tag)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		fmt.Fprintln(stdout, string(schema))
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	errs := godefault.CheckDefaults(reflect.New(checked).Interface(), *tag)
+	for _, err := range errs {
+		fmt.Fprintf(stderr, "%s.%s: %v\n", importPath, name, err)
+	}
+	if len(errs) != 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// splitTypeName splits "github.com/me/app/config.Config" into the import
+// path and the type name.
+func splitTypeName(s string) (string, string, error) {
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i < strings.LastIndex(s, "/") || i == len(s)-1 {
+		return "", "", fmt.Errorf("invalid -type %q, expected <import path>.<name>", s)
+	}
+
+	return s[:i], s[i+1:], nil
+}
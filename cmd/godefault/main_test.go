@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunTable(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-type", "./testdata/config.Config"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr.String())
+	}
+
+	for _, row := range []string{
+		"| Name | string | `app` |",
+		"| Timeout | time.Duration | `30s` |",
+		"| Level | int | `2` |",
+		"| Server.Port | uint16 | `8080` |",
+		"| Server.Hosts | []string | `[a,b]` |",
+		"| Peers[].Weight | float64 | `0.5` |",
+	} {
+		if !strings.Contains(stdout.String(), row) {
+			t.Errorf("missing row %q in:\n%s", row, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "hidden") {
+		t.Errorf("unexported field documented:\n%s", stdout.String())
+	}
+}
+
+func TestRunSchema(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-type", "./testdata/config.Config", "-format", "schema"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"default": 8080`) {
+		t.Errorf("missing typed default in:\n%s", stdout.String())
+	}
+}
+
+func TestRunInvalidTags(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-type", "./testdata/config.Broken"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code %d, want 1", code)
+	}
+
+	for _, field := range []string{"Port", "Timeout", "Debug"} {
+		if !strings.Contains(stderr.String(), "config.Broken: "+field+":") {
+			t.Errorf("missing error for %s in:\n%s", field, stderr.String())
+		}
+	}
+	if strings.Contains(stderr.String(), "Env:") {
+		t.Errorf("env-dependent tag reported:\n%s", stderr.String())
+	}
+}
+
+func TestRunKnownTypes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-type", "./testdata/config.Known"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code %d, want 1, stderr: %s", code, stderr.String())
+	}
+
+	if !strings.Contains(stderr.String(), "config.Known: Retries:") {
+		t.Errorf("missing error for Retries in:\n%s", stderr.String())
+	}
+	for _, field := range []string{"Pattern", "Name", "Level", "Proxy"} {
+		if strings.Contains(stderr.String(), "config.Known: "+field+":") {
+			t.Errorf("%s reported:\n%s", field, stderr.String())
+		}
+	}
+	for _, field := range []string{"Level", "Proxy"} {
+		if !strings.Contains(stderr.String(), "field "+field+" skipped") {
+			t.Errorf("missing warning for %s in:\n%s", field, stderr.String())
+		}
+	}
+	if !strings.Contains(stdout.String(), "| Level | int | `high` |") {
+		t.Errorf("unchecked field not documented:\n%s", stdout.String())
+	}
+}
+
+func TestSplitTypeName(t *testing.T) {
+	path, name, err := splitTypeName("github.com/me/app/config.Config")
+	if err != nil || path != "github.com/me/app/config" || name != "Config" {
+		t.Errorf("got %q %q %v", path, name, err)
+	}
+
+	for _, s := range []string{"", "Config", "github.com/me/app", "github.com/me/app."} {
+		if _, _, err := splitTypeName(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"database/sql"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+type Level int
+
+type Config struct {
+	Name    string        `default:"app"`
+	Timeout time.Duration `default:"30s"`
+	Level   Level         `default:"2"`
+	Server  Server
+	Peers   []Peer
+	secret  string `default:"hidden"`
+}
+
+type Server struct {
+	Port  uint16   `default:"8080"`
+	Hosts []string `default:"[a,b]"`
+	Next  *Server
+}
+
+type Peer struct {
+	Weight float64 `default:"0.5"`
+}
+
+type Broken struct {
+	Port    uint8         `default:"300"`
+	Timeout time.Duration `default:"30 seconds"`
+	Debug   bool          `default:"yes"`
+	Env     string        `default:"envs|MODE|dev,1|prod,2"`
+}
+
+type Known struct {
+	Pattern *regexp.Regexp `default:"^[a-z]+$"`
+	Name    sql.NullString `default:"app"`
+	Retries sql.NullInt64  `default:"many"`
+	Level   Level          `default:"high"`
+	Proxy   *url.URL       `default:"http://proxy:3128"`
+}
@@ -0,0 +1,161 @@
+package godefault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Compat names another defaults library whose tag conventions a Filler can
+// accept, see WithCompat.
+type Compat string
+
+const (
+	// CompatCreasty accepts the conventions of github.com/creasty/defaults:
+	// JSON objects and arrays for structs, pointers to structs, slices and
+	// arrays, e.g. `default:"{\"port\":5432}"` or `default:"[\"a\",\"b\"]"`,
+	// read like json: defaults.
+	CompatCreasty Compat = "creasty"
+	// CompatMcuadros accepts the conventions of github.com/mcuadros/go-defaults:
+	// slices as comma separated elements without brackets, e.g.
+	// `default:"1,2,3"`.
+	CompatMcuadros Compat = "mcuadros"
+)
+
+// WithCompat makes the fills read the default tags with the conventions of
+// the library compat, to migrate from it without rewriting every tag. The
+// tags using the syntax of this package keep working, but where both read
+// the same tag differently, compat wins; MigrateReport lists those tags. The
+// empty Compat reads the tags natively. CheckDefaults reads them like the
+// fills do.
+func WithCompat(compat Compat) Option {
+	return func(f *Filler) {
+		f.compat = compat
+	}
+}
+
+// compatTag rewrites value, the default tag of a field of type t written with
+// the conventions of compat, into the native syntax.
+func compatTag(t reflect.Type, value string, compat Compat) string {
+	value = strings.TrimSpace(value)
+	elem := derefType(t)
+	switch compat {
+	case CompatCreasty:
+		object := isStructModeType(elem) && strings.HasPrefix(value, "{")
+		array := (elem.Kind() == reflect.Slice && elem.Elem().Kind() != reflect.Uint8 || elem.Kind() == reflect.Array) && strings.HasPrefix(value, "[")
+		if (object || array) && json.Valid([]byte(value)) {
+			return jsonPrefix + value
+		}
+	case CompatMcuadros:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && t.Elem().Kind() != reflect.Struct &&
+			value != "" && !strings.HasPrefix(value, "[") && isStaticTag(value) && !isZeroSentinel(t, value) {
+			return "[" + value + "]"
+		}
+	}
+
+	return value
+}
+
+// MigrationNote is a field whose default tag gives another value with the
+// conventions of a Compat than natively, see MigrateReport. The values are
+// formatted like those of FieldDiff, or are the error of the tag.
+type MigrationNote struct {
+	Path   string
+	Tag    string
+	Native string
+	Compat string
+}
+
+// MigrateReport lists the fields of v, a struct or a pointer to a struct,
+// whose default tag doesn't give the same value read natively and with the
+// conventions of compat, in declaration order, both filled by a Filler
+// configured with opts. It tells which tags a switch from another library
+// changes, e.g.
+//
+//	notes, _ := godefault.MigrateReport(&Config{}, godefault.CompatCreasty)
+//	for _, note := range notes {
+//	    log.Printf("%s %q: %s natively, %s before", note.Path, note.Tag, note.Native, note.Compat)
+//	}
+//
+// Secret fields are reported with both values "****".
+func MigrateReport(v interface{}, compat Compat, opts ...Option) ([]MigrationNote, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	filler := NewFiller(opts...)
+	native, nativeErrs := migrationFill(t, filler)
+	compatFiller := NewFiller(opts...)
+	compatFiller.compat = compat
+	compatValue, compatErrs := migrationFill(t, compatFiller)
+
+	var notes []MigrationNote
+	w := &typeWalker{tagName: filler.Tag, nameTags: filler.nameTags, visiting: make(map[reflect.Type]bool), structs: true, pointers: true, filler: filler}
+	w.visit = func(tf *FieldInfo) {
+		if !tf.HasTag || compatTag(tf.Field.Type, tf.Tag, compat) == tf.Tag {
+			return
+		}
+		index, err := pathIndex(t, tf.Path)
+		if err != nil {
+			return
+		}
+		note := MigrationNote{
+			Path:   tf.Path,
+			Tag:    redact(tf.Field, tf.Tag),
+			Native: migrationOutcome(native, index, nativeErrs[tf.Path]),
+			Compat: migrationOutcome(compatValue, index, compatErrs[tf.Path]),
+		}
+		if note.Native == note.Compat {
+			return
+		}
+		if isSecret(tf.Field) {
+			note.Native, note.Compat = secretValue, secretValue
+		}
+		notes = append(notes, note)
+	}
+	w.walk(t, &FieldInfo{})
+
+	return notes, nil
+}
+
+// migrationFill fills a new value of the struct type t with filler,
+// returning the messages of the errors by field path.
+func migrationFill(t reflect.Type, filler *Filler) (reflect.Value, map[string]string) {
+	value := reflect.New(t)
+	err := filler.FillContext(context.Background(), value.Interface())
+
+	messages := make(map[string]string)
+	var aggregate AggregateError
+	if errors.As(err, &aggregate) {
+		for _, err := range aggregate {
+			var fe *FieldError
+			if errors.As(err, &fe) {
+				messages[fe.Path] = "error: " + fe.Err.Error()
+			}
+		}
+	}
+
+	return value.Elem(), messages
+}
+
+// migrationOutcome formats the field at index in value, or returns message
+// when the field failed.
+func migrationOutcome(value reflect.Value, index []int, message string) string {
+	if message != "" {
+		return message
+	}
+	for _, i := range index {
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return "<nil>"
+			}
+			value = value.Elem()
+		}
+		value = value.Field(i)
+	}
+
+	return formatValue(value)
+}
@@ -0,0 +1,73 @@
+package godefault
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type CompatSuite struct{}
+
+var _ = Suite(&CompatSuite{})
+
+type ExampleCompatDatabase struct {
+	Host string `default:"localhost"`
+	Port int    `default:"5432"`
+}
+
+type ExampleCompat struct {
+	Database ExampleCompatDatabase  `default:"{\"port\":6432}"`
+	Replica  *ExampleCompatDatabase `default:"{\"host\":\"replica\"}"`
+	Names    []string               `default:"[\"a\",\"b,c\"]"`
+	Ports    []int                  `default:"[80,443]"`
+	Plain    []int                  `default:"1,2,3"`
+	Timeout  time.Duration          `default:"5s"`
+	Secret   []string               `default:"x,y" secret:"true"`
+}
+
+func (s *CompatSuite) TestWithCompatCreasty(c *C) {
+	foo := &ExampleCompat{}
+	err := NewFiller(WithCompat(CompatCreasty)).FillContext(context.Background(), foo)
+	c.Assert(err, IsNil)
+	c.Assert(foo.Database, Equals, ExampleCompatDatabase{Host: "localhost", Port: 6432})
+	c.Assert(*foo.Replica, Equals, ExampleCompatDatabase{Host: "replica", Port: 5432})
+	c.Assert(foo.Names, DeepEquals, []string{"a", "b,c"})
+	c.Assert(foo.Ports, DeepEquals, []int{80, 443})
+	c.Assert(foo.Plain, IsNil)
+	// Only the slices without brackets are left, creasty doesn't take them.
+	c.Assert(NewFiller(WithCompat(CompatCreasty)).CheckDefaults(foo), HasLen, 2)
+
+	// Natively, structs don't take JSON objects.
+	bar := &ExampleCompat{}
+	c.Assert(SetDefaultsContext(context.Background(), bar), NotNil)
+	c.Assert(CheckDefaults(bar), HasLen, 3)
+}
+
+func (s *CompatSuite) TestWithCompatMcuadros(c *C) {
+	foo := &ExampleCompat{}
+	c.Assert(NewFiller(WithCompat(CompatMcuadros)).FillContext(context.Background(), foo), NotNil)
+	c.Assert(foo.Plain, DeepEquals, []int{1, 2, 3})
+	c.Assert(foo.Secret, DeepEquals, []string{"x", "y"})
+	c.Assert(foo.Ports, DeepEquals, []int{80, 443})
+	c.Assert(foo.Timeout, Equals, 5*time.Second)
+}
+
+func (s *CompatSuite) TestMigrateReport(c *C) {
+	notes, err := MigrateReport(&ExampleCompat{}, CompatCreasty)
+	c.Assert(err, IsNil)
+	c.Assert(notes, DeepEquals, []MigrationNote{
+		{Path: "Database", Tag: `{"port":6432}`, Native: `error: invalid struct default "{\"port\":6432}", expected "skipzero" or "-"`, Compat: "{localhost 6432}"},
+		{Path: "Replica", Tag: `{"host":"replica"}`, Native: "{localhost 5432}", Compat: "{replica 5432}"},
+	})
+
+	notes, err = MigrateReport(ExampleCompat{}, CompatMcuadros)
+	c.Assert(err, IsNil)
+	c.Assert(notes, DeepEquals, []MigrationNote{
+		{Path: "Plain", Tag: "1,2,3", Native: "[]", Compat: "[1 2 3]"},
+		{Path: "Secret", Tag: "****", Native: "****", Compat: "****"},
+	})
+
+	_, err = MigrateReport(42, CompatCreasty)
+	c.Assert(err, ErrorMatches, `godefault: expected a struct or a pointer to a struct, got int`)
+}
@@ -0,0 +1,52 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+)
+
+// SetDefaultsContext applies the default values like SetDefaults, passing ctx
+// to every filler through FieldData.Context. The fill stops as soon as ctx is
+// done and returns ctx.Err() wrapped with the path of the field in flight.
+// Fills that don't resolve anything externally behave like SetDefaults,
+// except that tags which fail to parse are reported, one error per field.
+//
+// Usage
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := SetDefaultsContext(ctx, &config); err != nil {
+//	    return err
+//	}
+func SetDefaultsContext(ctx context.Context, variable interface{}, opts ...Option) error {
+	filler := getDefaultFiller()
+	if len(opts) != 0 {
+		filler = NewFiller(opts...)
+	}
+
+	return filler.FillContext(ctx, variable)
+}
+
+// FillContext is the context aware version of Fill, see SetDefaultsContext.
+// Unlike Fill it returns an error rather than panicking when variable is not
+// a non-nil pointer to a struct, and reports the fields whose filler panicked
+// as PanicErrors, filling the others.
+func (f *Filler) FillContext(ctx context.Context, variable interface{}) error {
+	value := reflect.ValueOf(variable)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return invalidTargetf("godefault: expected a non-nil pointer to a struct, got %T", variable)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	state := &fillState{ctx: ctx, done: ctx.Done(), recover: true, root: value.Elem()}
+	f.SetDefaultValues(f.getFieldsFromValue(value.Elem(), nil, state))
+	f.resolveLookups(state)
+
+	if state.err != nil {
+		return state.err
+	}
+
+	return joinErrors(state.errs)
+}
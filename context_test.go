@@ -0,0 +1,108 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ContextSuite struct{}
+
+var _ = Suite(&ContextSuite{})
+
+type ExampleContext struct {
+	Before string `default:"foo"`
+	Nested struct {
+		Slow string `default:"bar"`
+	}
+	After string `default:"qux"`
+}
+
+func (s *ContextSuite) TestFillContextCancelled(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	f := NewFiller()
+	f.FuncByName = map[string]FillerFunc{
+		"Slow": func(field *FieldData) {
+			close(started)
+			<-field.Context().Done()
+		},
+	}
+
+	foo := &ExampleContext{}
+	result := make(chan error, 1)
+	go func() {
+		result <- f.FillContext(ctx, foo)
+	}()
+	<-started
+	cancel()
+
+	select {
+	case err := <-result:
+		c.Assert(errors.Is(err, context.Canceled), Equals, true)
+		c.Assert(err, ErrorMatches, "Nested.Slow: context canceled")
+	case <-time.After(time.Second):
+		c.Fatal("fill didn't return after the context was cancelled")
+	}
+
+	c.Assert(foo.Before, Equals, "foo")
+	c.Assert(foo.After, Equals, "")
+}
+
+func (s *ContextSuite) TestFillContextDeadline(c *C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	f := NewFiller()
+	f.FuncByName = map[string]FillerFunc{
+		"Slow": func(field *FieldData) {
+			<-field.Context().Done()
+		},
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- f.FillContext(ctx, &ExampleContext{})
+	}()
+
+	select {
+	case err := <-result:
+		c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true)
+	case <-time.After(time.Second):
+		c.Fatal("fill didn't return within the deadline")
+	}
+}
+
+func (s *ContextSuite) TestFillContextDone(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	foo := &ExampleContext{}
+	c.Assert(NewFiller().FillContext(ctx, foo), Equals, context.Canceled)
+	c.Assert(foo.Before, Equals, "")
+}
+
+func (s *ContextSuite) TestSetDefaultsContext(c *C) {
+	foo := &ExampleBasic{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Integer, Equals, 33)
+	c.Assert(foo.Struct.Integer, Equals, 33)
+
+	bar := &struct {
+		Foo int `foo:"42" default:"1"`
+	}{}
+	c.Assert(SetDefaultsContext(context.Background(), bar, WithTag("foo")), IsNil)
+	c.Assert(bar.Foo, Equals, 42)
+}
+
+func (s *ContextSuite) TestSetDefaultsContextInvalidTarget(c *C) {
+	c.Assert(SetDefaultsContext(context.Background(), ExampleBasic{}), NotNil)
+	c.Assert(SetDefaultsContext(context.Background(), (*ExampleBasic)(nil)), NotNil)
+	c.Assert(SetDefaultsContext(context.Background(), new(int)), NotNil)
+}
+
+func (s *ContextSuite) TestFieldDataContext(c *C) {
+	c.Assert((&FieldData{}).Context(), Equals, context.Background())
+}
@@ -0,0 +1,83 @@
+package godefault
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+)
+
+// cpuExprPattern matches the defaults of integer fields sized after the
+// processors of the machine:
+//
+//	numcpu         runtime.NumCPU()
+//	gomaxprocs     runtime.GOMAXPROCS(0)
+//	numcpu*N       multiplied by N, likewise numcpu/N, numcpu+N and numcpu-N
+//
+// The result is never less than 1, e.g. numcpu-1 is 1 on a single CPU, and
+// the integer transforms still apply to it.
+//
+//	Workers int `default:"numcpu*2"`
+//	Readers int `default:"env:READERS:numcpu-1"`
+var cpuExprPattern = regexp.MustCompile(`^(numcpu|gomaxprocs)(?:([-+*/])(\d+))?$`)
+
+// resolveCPUExpr replaces the processor count expression of an integer
+// default by its value, see cpuExprPattern. Any other value is returned as it
+// is.
+func resolveCPUExpr(value string) (string, error) {
+	value, transform := splitIntTransform(value)
+	n, ok, err := evalCPUExpr(value)
+	if !ok {
+		return value + transform, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(n, 10) + transform, nil
+}
+
+// isCPUExpr reports whether value is a processor count expression, possibly
+// followed by a transform.
+func isCPUExpr(value string) bool {
+	value, _ = splitIntTransform(value)
+	return cpuExprPattern.MatchString(value)
+}
+
+// evalCPUExpr evaluates expr, reporting whether it is a processor count
+// expression at all.
+func evalCPUExpr(expr string) (int64, bool, error) {
+	match := cpuExprPattern.FindStringSubmatch(expr)
+	if match == nil {
+		return 0, false, nil
+	}
+
+	n := int64(runtime.NumCPU())
+	if match[1] == "gomaxprocs" {
+		n = int64(runtime.GOMAXPROCS(0))
+	}
+	if match[2] != "" {
+		operand, err := strconv.ParseInt(match[3], 10, 32)
+		if err != nil {
+			return 0, true, err
+		}
+		switch match[2] {
+		case "+":
+			n += operand
+		case "-":
+			n -= operand
+		case "*":
+			n *= operand
+		case "/":
+			if operand == 0 {
+				return 0, true, fmt.Errorf("invalid processor count %q: division by zero", expr)
+			}
+			n /= operand
+		}
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	return n, true, nil
+}
@@ -0,0 +1,69 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+
+	. "gopkg.in/check.v1"
+)
+
+type CPUSuite struct{}
+
+var _ = Suite(&CPUSuite{})
+
+type ExampleCPU struct {
+	Workers  int    `default:"numcpu"`
+	Double   uint   `default:"numcpu*2"`
+	Half     int    `default:"numcpu/2"`
+	Spare    int    `default:"numcpu-1000"`
+	Plus     int    `default:"numcpu+1"`
+	Procs    int    `default:"gomaxprocs"`
+	Shifted  int    `default:"numcpu|+10"`
+	Env      int    `default:"env:GODEFAULT_TEST_WORKERS:numcpu*3"`
+	Pointer  *int32 `default:"numcpu"`
+	Name     string `default:"numcpu"`
+	Invalid  int    `default:"numcpu/0"`
+	Operator int    `default:"numcpu^2"`
+}
+
+func (s *CPUSuite) TestNumCPU(c *C) {
+	foo := &ExampleCPU{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Invalid: invalid processor count "numcpu/0": division by zero\nOperator: strconv.ParseInt: parsing "numcpu\^2": invalid syntax`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+
+	n := runtime.NumCPU()
+	c.Assert(foo.Workers, Equals, n)
+	c.Assert(foo.Double, Equals, uint(2*n))
+	c.Assert(foo.Spare, Equals, 1)
+	c.Assert(foo.Plus, Equals, n+1)
+	c.Assert(foo.Procs, Equals, runtime.GOMAXPROCS(0))
+	c.Assert(foo.Shifted, Equals, n+10)
+	c.Assert(foo.Env, Equals, 3*n)
+	c.Assert(*foo.Pointer, Equals, int32(n))
+	c.Assert(foo.Name, Equals, "numcpu")
+	c.Assert(foo.Invalid, Equals, 0)
+	if n >= 2 {
+		c.Assert(foo.Half, Equals, n/2)
+	} else {
+		c.Assert(foo.Half, Equals, 1)
+	}
+}
+
+func (s *CPUSuite) TestNumCPUEnvSet(c *C) {
+	os.Setenv("GODEFAULT_TEST_WORKERS", "7")
+	defer os.Unsetenv("GODEFAULT_TEST_WORKERS")
+
+	foo := &ExampleCPU{}
+	SetDefaults(foo)
+	c.Assert(foo.Env, Equals, 7)
+}
+
+func (s *CPUSuite) TestCheckDefaultsNumCPU(c *C) {
+	errs := CheckDefaults(&ExampleCPU{})
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `Invalid: .*division by zero`)
+	c.Assert(errs[1], ErrorMatches, `Operator: .*invalid syntax`)
+}
@@ -0,0 +1,87 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// csvPrefix introduces the positional default of a flat struct field, the
+// values given to its exported fields in declaration order:
+//
+//	Limits struct {
+//	    Name    string
+//	    Workers int
+//	    Debug   bool
+//	} `default:"csv:batch,4,true"`
+//
+// Values are parsed like the elements of a slice default, so "|," is a
+// literal comma and quoted values keep theirs, e.g. a slice "[a,b]"; an empty
+// value leaves its field without a default. Values a struct has no field for are ignored and
+// fields past the last value keep their own tags, unless WithStrict makes a
+// count mismatch an error.
+const csvPrefix = "csv:"
+
+func isCSVDefault(value string) bool {
+	return strings.HasPrefix(value, csvPrefix)
+}
+
+// csvValues splits the csv: default value into its values.
+func csvValues(value string) ([]string, error) {
+	entries, err := splitTagList(value[len(csvPrefix):], false, defaultSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q: %w", csvPrefix, value, err)
+	}
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		values[i] = entry.value
+	}
+
+	return values, nil
+}
+
+// csvFields returns the exported fields of the struct type t, in
+// declaration order.
+func csvFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.PkgPath == "" {
+			fields = append(fields, sf)
+		}
+	}
+
+	return fields
+}
+
+// csvDefaults decodes the csv: default of a struct of type t into the default
+// tags it gives to the fields, by Go name.
+func csvDefaults(t reflect.Type, value string) (map[string]string, error) {
+	values, err := csvValues(value)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(values))
+	for i, sf := range csvFields(t) {
+		if i == len(values) {
+			break
+		}
+		tags[sf.Name] = values[i]
+	}
+
+	return tags, nil
+}
+
+// checkCSVCount reports the csv: default of a struct of type t whose values
+// don't match its fields one to one, for strict fills.
+func checkCSVCount(t reflect.Type, value string) error {
+	values, err := csvValues(value)
+	if err != nil {
+		return err
+	}
+	if n := len(csvFields(t)); len(values) != n {
+		return fmt.Errorf("invalid %s value %q: %d values for the %d fields of %s", csvPrefix, value, len(values), n, t)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+type CSVSuite struct{}
+
+var _ = Suite(&CSVSuite{})
+
+type ExampleCSVLimits struct {
+	Name    string
+	Workers int `default:"1"`
+	Debug   bool
+	hidden  string
+	Tags    []string
+}
+
+type ExampleCSV struct {
+	Limits  ExampleCSVLimits `default:"csv:batch,4,true,\"[a,b]\""`
+	Partial ExampleCSVLimits `default:"csv:\"x,y\",,true"`
+	Extra   ExampleCSVLimits `default:"csv:a|,b,2,false,[],ignored"`
+}
+
+func (s *CSVSuite) TestSetDefaultsCSV(c *C) {
+	foo := &ExampleCSV{}
+	SetDefaults(foo)
+
+	c.Assert(foo.Limits, DeepEquals, ExampleCSVLimits{Name: "batch", Workers: 4, Debug: true, Tags: []string{"a", "b"}})
+	c.Assert(foo.Partial, DeepEquals, ExampleCSVLimits{Name: "x,y", Debug: true})
+	c.Assert(foo.Extra, DeepEquals, ExampleCSVLimits{Name: "a,b", Workers: 2, Tags: []string{}})
+
+	c.Assert(CheckDefaults(&ExampleCSV{}), HasLen, 0)
+}
+
+func (s *CSVSuite) TestSetDefaultsCSVStrict(c *C) {
+	filler := NewFiller(WithStrict())
+	foo := &ExampleCSV{}
+	err := filler.FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Partial: invalid csv: value "csv:\\"x,y\\",,true": 3 values for the 4 fields of godefault.ExampleCSVLimits\n`+
+		`Extra: invalid csv: value "csv:a\|,b,2,false,\[\],ignored": 5 values for the 4 fields of godefault.ExampleCSVLimits`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(foo.Limits.Workers, Equals, 4)
+	c.Assert(foo.Partial, DeepEquals, ExampleCSVLimits{})
+
+	c.Assert(filler.CheckDefaults(&ExampleCSV{}), HasLen, 2)
+}
+
+func (s *CSVSuite) TestCheckDefaultsCSV(c *C) {
+	foo := &struct {
+		Limits ExampleCSVLimits `default:"csv:a,many"`
+		Broken ExampleCSVLimits `default:"csv:\"a"`
+	}{}
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `Limits: Workers: strconv.ParseInt: parsing "many": invalid syntax`)
+	c.Assert(errs[1], ErrorMatches, `Broken: invalid csv: value .*`)
+}
@@ -0,0 +1,126 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// decoders holds the decoders registered with RegisterDecoder, by prefix.
+var decoders sync.Map
+
+var decoderPrefixPattern = regexp.MustCompile(`^\w+$`)
+
+// RegisterDecoder registers decode, an Unmarshal function such as those of
+// the YAML and TOML packages, as the decoder of the defaults introduced by
+// prefix and a colon, usually from an init function:
+//
+//	godefault.RegisterDecoder("yaml", yaml.Unmarshal)
+//
+//	Limits Limits            `default:"yaml:{cpu: 2, memory: 512Mi}"`
+//	Labels map[string]string `default:"yaml:{team: core}"`
+//
+// The rest of the tag is decoded into a new value of the type of the field,
+// which is set as a whole once decoded: a default that fails to decode fails
+// the field and leaves it alone. Pointers are allocated, and the zero fields
+// of a decoded struct still get the defaults of their own tags. A filler set
+// by name in FuncByName wins over the decoder. The references of the
+// package, e.g. env:, are resolved before, so their prefixes can't introduce
+// a decoder, but their values can, e.g. env:LIMITS:yaml:{cpu: 1}.
+//
+// Keeping the decoders pluggable keeps the package free of dependencies,
+// json:, which has its own semantics, being the only built-in one, see
+// jsonPrefix. Registering the same prefix again replaces its decoder.
+// RegisterDecoder panics when prefix is json or not a word.
+func RegisterDecoder(prefix string, decode func(data []byte, v interface{}) error) {
+	if !decoderPrefixPattern.MatchString(prefix) || prefix+":" == jsonPrefix {
+		panic(fmt.Sprintf("godefault: RegisterDecoder of %q, not a word or json", prefix))
+	}
+	decoders.Store(prefix, decode)
+}
+
+// decoderOf returns the decoder registered for the prefix of value and the
+// data after it, see RegisterDecoder.
+func decoderOf(value string) (func([]byte, interface{}) error, string, bool) {
+	i := strings.IndexByte(value, ':')
+	if i <= 0 {
+		return nil, "", false
+	}
+	decode, ok := decoders.Load(value[:i])
+	if !ok {
+		return nil, "", false
+	}
+
+	return decode.(func([]byte, interface{}) error), value[i+1:], true
+}
+
+// isDecodedValue reports whether value is introduced by the prefix of a
+// registered decoder.
+func isDecodedValue(value string) bool {
+	_, _, ok := decoderOf(value)
+	return ok
+}
+
+func (f *Filler) getFunctionByDecoder(field *FieldData) FillerFunc {
+	// Pointers are allocated by their filler, which decodes their pointee.
+	if field.Value.Kind() != reflect.Ptr && isDecodedValue(field.TagValue) {
+		return fillDecoded
+	}
+
+	return nil
+}
+
+// fillDecoded sets field from its default decoded by the registered decoder
+// of its prefix, then fills the zero fields of a struct, or of the struct
+// elements of a slice or an array, from their tags like an untagged field.
+func fillDecoded(field *FieldData) {
+	value, err := parseDecodedValue(field.TagValue, field.Value.Type())
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return
+	}
+	field.Value.Set(value)
+
+	filler := field.owner()
+	t := field.Value.Type()
+	switch {
+	case t.Kind() == reflect.Struct && filler.isStructType(t),
+		(t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Struct && filler.isStructType(t.Elem()):
+		if fn := filler.FuncByKind[t.Kind()]; fn != nil {
+			tag := field.TagValue
+			field.TagValue = ""
+			fn(field)
+			field.TagValue = tag
+		}
+	}
+}
+
+// parseDecodedValue decodes value, introduced by the prefix of a registered
+// decoder, into a new value of type t.
+func parseDecodedValue(value string, t reflect.Type) (reflect.Value, error) {
+	decode, data, _ := decoderOf(value)
+	result := reflect.New(t)
+	if err := decode([]byte(data), result.Interface()); err != nil {
+		prefix := value[:len(value)-len(data)]
+		return reflect.Value{}, fmt.Errorf("invalid %s value: %w", prefix, err)
+	}
+
+	return result.Elem(), nil
+}
+
+// checkDecoded validates value, the default of a field of type t, when it is
+// introduced by the prefix of a registered decoder, or is an env: reference
+// falling back to such a value, reporting false when it isn't.
+func checkDecoded(t reflect.Type, value string) (error, bool) {
+	if _, fallback, ok := parseEnvRef(value); ok && isDecodedValue(fallback) {
+		value = fallback
+	}
+	if !isDecodedValue(value) {
+		return nil, false
+	}
+	_, err := parseDecodedValue(value, derefType(t))
+
+	return err, true
+}
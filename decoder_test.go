@@ -0,0 +1,75 @@
+package godefault
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type DecoderSuite struct{}
+
+var _ = Suite(&DecoderSuite{})
+
+func init() {
+	// A stand-in for yaml.Unmarshal: key=value pairs, separated by
+	// semicolons, decoded through encoding/json.
+	RegisterDecoder("pairs", func(data []byte, v interface{}) error {
+		var fields []string
+		for _, pair := range strings.Split(string(data), ";") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return json.Unmarshal([]byte(pair), v)
+			}
+			fields = append(fields, `"`+kv[0]+`":`+kv[1])
+		}
+		return json.Unmarshal([]byte("{"+strings.Join(fields, ",")+"}"), v)
+	})
+}
+
+type ExampleDecoderLimits struct {
+	CPU    int    `json:"cpu" default:"1"`
+	Memory string `json:"memory" default:"128Mi"`
+}
+
+type ExampleDecoder struct {
+	Limits  ExampleDecoderLimits   `default:"pairs:cpu=4"`
+	Pointer *ExampleDecoderLimits  `default:"pairs:memory=\"1Gi\""`
+	Labels  map[string]string      `default:"pairs:team=\"core\";tier=\"web\""`
+	Ports   []int                  `default:"pairs:[80,443]"`
+	Env     ExampleDecoderLimits   `default:"env:GODEFAULT_TEST_LIMITS:pairs:cpu=8"`
+	Invalid int                    `default:"pairs:cpu=4"`
+	Elems   []ExampleDecoderLimits `default:"pairs:[{\"cpu\":2},{}]"`
+	Plain   string                 `default:"unregistered:value"`
+}
+
+func (s *DecoderSuite) TestRegisterDecoder(c *C) {
+	foo := &ExampleDecoder{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Invalid: invalid pairs: value: json: cannot unmarshal object into Go value of type int`)
+	c.Assert(foo.Limits, Equals, ExampleDecoderLimits{CPU: 4, Memory: "128Mi"})
+	c.Assert(*foo.Pointer, Equals, ExampleDecoderLimits{CPU: 1, Memory: "1Gi"})
+	c.Assert(foo.Labels, DeepEquals, map[string]string{"team": "core", "tier": "web"})
+	c.Assert(foo.Ports, DeepEquals, []int{80, 443})
+	c.Assert(foo.Env, Equals, ExampleDecoderLimits{CPU: 8, Memory: "128Mi"})
+	c.Assert(foo.Invalid, Equals, 0)
+	c.Assert(foo.Elems, DeepEquals, []ExampleDecoderLimits{{CPU: 2, Memory: "128Mi"}, {CPU: 1, Memory: "128Mi"}})
+	c.Assert(foo.Plain, Equals, "unregistered:value")
+
+	errs := CheckDefaults(&ExampleDecoder{})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Invalid: .*cannot unmarshal object.*`)
+
+	// A filler set by name wins.
+	filler := NewFiller()
+	filler.FuncByName = map[string]FillerFunc{"Invalid": func(field *FieldData) { field.Value.SetInt(7) }}
+	bar := &ExampleDecoder{}
+	c.Assert(filler.FillContext(context.Background(), bar), IsNil)
+	c.Assert(bar.Invalid, Equals, 7)
+}
+
+func (s *DecoderSuite) TestRegisterDecoderPanics(c *C) {
+	c.Assert(func() { RegisterDecoder("json", json.Unmarshal) }, PanicMatches, `godefault: RegisterDecoder of "json", not a word or json`)
+	c.Assert(func() { RegisterDecoder("a:b", json.Unmarshal) }, PanicMatches, `godefault: RegisterDecoder of "a:b", not a word or json`)
+}
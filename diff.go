@@ -0,0 +1,233 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldDiff is a field whose value differs from its default, see
+// DiffFromDefaults. Both values are formatted with fmt, nil pointers as
+// "<nil>".
+type FieldDiff struct {
+	Path    string
+	Default string
+	Actual  string
+}
+
+// DiffFromDefaults compares v, a struct or a pointer to a struct, with a
+// fresh value of the same type filled with its defaults by a Filler
+// configured by opts, and returns the fields that differ in declaration
+// order. Nested structs and pointers to structs set on both sides are
+// compared field by field, anything else as a whole: times with Equal,
+// slices and maps deeply. Secret fields are reported with both values
+// "****".
+//
+//	diffs, _ := DiffFromDefaults(&config)
+//	for _, diff := range diffs {
+//	    log.Printf("%s changed: %s -> %s", diff.Path, diff.Default, diff.Actual)
+//	}
+func DiffFromDefaults(v interface{}, opts ...Option) ([]FieldDiff, error) {
+	defaults, value, err := defaultsOf(v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &differ{visited: make(map[uintptr]bool)}
+	d.diffStruct(defaults, value, "")
+
+	return d.diffs, nil
+}
+
+// IsAllDefault reports whether every field of v, a struct or a pointer to a
+// struct, is at its default, compared like DiffFromDefaults does. Fields
+// without a default must be zero. It stops at the first difference.
+func IsAllDefault(v interface{}, opts ...Option) (bool, error) {
+	defaults, value, err := defaultsOf(v, opts)
+	if err != nil {
+		return false, err
+	}
+
+	d := &differ{visited: make(map[uintptr]bool), first: true}
+	d.diffStruct(defaults, value, "")
+
+	return len(d.diffs) == 0, nil
+}
+
+// IsDefault is IsAllDefault for the field at the dotted Go path of v, e.g.
+// "Server.Port", which may be a nested struct. A nil pointer on the path is
+// at its default when the default is nil too.
+func IsDefault(v interface{}, path string, opts ...Option) (bool, error) {
+	defaults, value, err := defaultsOf(v, opts)
+	if err != nil {
+		return false, err
+	}
+	index, err := pathIndex(value.Type(), path)
+	if err != nil {
+		return false, err
+	}
+
+	for _, i := range index {
+		if value.Kind() == reflect.Ptr {
+			if defaults.IsNil() || value.IsNil() {
+				return defaults.IsNil() == value.IsNil(), nil
+			}
+			defaults, value = defaults.Elem(), value.Elem()
+		}
+		defaults, value = defaults.Field(i), value.Field(i)
+	}
+
+	if value.Kind() == reflect.Ptr && isStructType(value.Type().Elem()) && !value.IsNil() && !defaults.IsNil() {
+		defaults, value = defaults.Elem(), value.Elem()
+	}
+	if !isStructType(value.Type()) {
+		return valuesEqual(defaults, value), nil
+	}
+
+	d := &differ{visited: make(map[uintptr]bool), first: true}
+	d.diffStruct(defaults, value, "")
+
+	return len(d.diffs) == 0, nil
+}
+
+// defaultsOf returns the struct behind v with a fresh value of its type
+// filled with its defaults.
+func defaultsOf(v interface{}, opts []Option) (defaults, value reflect.Value, err error) {
+	value = reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, reflect.Value{}, invalidTargetf("godefault: expected a struct or a non-nil pointer to a struct, got %T", v)
+	}
+
+	filled := reflect.New(value.Type())
+	NewFiller(opts...).Fill(filled.Interface())
+
+	return filled.Elem(), value, nil
+}
+
+// pathIndex returns the index of every field on the dotted Go path in the
+// struct type t, through pointers to structs.
+func pathIndex(t reflect.Type, path string) ([]int, error) {
+	var index []int
+	for _, name := range strings.Split(path, ".") {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, unknownPathf("godefault: no field %s in %s", path, t)
+		}
+		sf, ok := t.FieldByName(name)
+		if !ok || len(sf.Index) != 1 || sf.PkgPath != "" {
+			return nil, unknownPathf("godefault: no field %s in %s", path, t)
+		}
+		index = append(index, sf.Index[0])
+		t = sf.Type
+	}
+
+	return index, nil
+}
+
+type differ struct {
+	diffs []FieldDiff
+	// first stops the comparison at the first difference.
+	first bool
+	// visited holds the pointers of v already compared, so that cyclic data
+	// terminates.
+	visited map[uintptr]bool
+}
+
+func (d *differ) diffStruct(defaults, actual reflect.Value, prefix string) {
+	t := actual.Type()
+	for i := 0; i < t.NumField() && !(d.first && len(d.diffs) != 0); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || isMetaField(sf) {
+			continue
+		}
+
+		path := prefix + sf.Name
+		defaultValue, actualValue := defaults.Field(i), actual.Field(i)
+		switch {
+		case isStructType(sf.Type):
+			d.diffStruct(defaultValue, actualValue, path+".")
+		case sf.Type.Kind() == reflect.Ptr && isStructType(sf.Type.Elem()) &&
+			!defaultValue.IsNil() && !actualValue.IsNil():
+			if !d.visited[actualValue.Pointer()] {
+				d.visited[actualValue.Pointer()] = true
+				d.diffStruct(defaultValue.Elem(), actualValue.Elem(), path+".")
+			}
+		case !valuesEqual(defaultValue, actualValue):
+			d.diffs = append(d.diffs, FieldDiff{
+				Path:    path,
+				Default: redact(sf, formatValue(defaultValue)),
+				Actual:  redact(sf, formatValue(actualValue)),
+			})
+		}
+	}
+}
+
+// valuesEqual reports whether a and b, of the same type, are deeply equal,
+// times being compared with Equal so that locations don't matter.
+func valuesEqual(a, b reflect.Value) bool {
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Pointer() == b.Pointer() || valuesEqual(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !valuesEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		// Nil and empty slices are equal.
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !valuesEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.MapKeys() {
+			other := b.MapIndex(key)
+			if !other.IsValid() || !valuesEqual(a.MapIndex(key), other) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !a.CanInterface() {
+		// Unexported fields of nested values can't be read generically.
+		return fmt.Sprint(a) == fmt.Sprint(b)
+	}
+
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// formatValue formats value for a FieldDiff, dereferencing pointers.
+func formatValue(value reflect.Value) string {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "<nil>"
+		}
+		value = value.Elem()
+	}
+
+	return fmt.Sprint(value)
+}
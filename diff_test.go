@@ -0,0 +1,122 @@
+package godefault
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type DiffSuite struct{}
+
+var _ = Suite(&DiffSuite{})
+
+type ExampleDiff struct {
+	Host     string            `default:"localhost"`
+	Port     int               `default:"8080"`
+	Password string            `default:"hunter2" secret:"true"`
+	Started  time.Time         `default:"2020-01-01T00:00:00Z"`
+	Hosts    []string          `default:"[a,b]"`
+	Labels   map[string]string `default:"{env:dev}"`
+	Limit    *int              `default:"10"`
+	Server   struct {
+		Timeout time.Duration `default:"1s"`
+	}
+	TLS *struct {
+		Cert string `default:"cert.pem"`
+	}
+}
+
+func (s *DiffSuite) TestDiffFromDefaultsUnchanged(c *C) {
+	foo := &ExampleDiff{}
+	SetDefaults(foo)
+	// Same instant, other location.
+	foo.Started = foo.Started.In(time.FixedZone("UTC+1", 3600))
+
+	diffs, err := DiffFromDefaults(foo)
+	c.Assert(err, IsNil)
+	c.Assert(diffs, HasLen, 0)
+}
+
+func (s *DiffSuite) TestDiffFromDefaults(c *C) {
+	foo := &ExampleDiff{}
+	SetDefaults(foo)
+	foo.Port = 9090
+	foo.Password = "secret"
+	foo.Hosts = append(foo.Hosts, "c")
+	foo.Labels["env"] = "prod"
+	foo.Limit = nil
+	foo.Server.Timeout = time.Minute
+	foo.TLS = &struct {
+		Cert string `default:"cert.pem"`
+	}{Cert: "other.pem"}
+
+	diffs, err := DiffFromDefaults(*foo)
+	c.Assert(err, IsNil)
+	c.Assert(diffs, DeepEquals, []FieldDiff{
+		{Path: "Port", Default: "8080", Actual: "9090"},
+		{Path: "Password", Default: "****", Actual: "****"},
+		{Path: "Hosts", Default: "[a b]", Actual: "[a b c]"},
+		{Path: "Labels", Default: "map[env:dev]", Actual: "map[env:prod]"},
+		{Path: "Limit", Default: "10", Actual: "<nil>"},
+		{Path: "Server.Timeout", Default: "1s", Actual: "1m0s"},
+		{Path: "TLS", Default: "<nil>", Actual: "{other.pem}"},
+	})
+}
+
+func (s *DiffSuite) TestDiffFromDefaultsNotStruct(c *C) {
+	_, err := DiffFromDefaults(42)
+	c.Assert(err, NotNil)
+}
+
+func (s *DiffSuite) TestIsAllDefault(c *C) {
+	foo := &ExampleDiff{}
+	SetDefaults(foo)
+
+	isDefault, err := IsAllDefault(foo)
+	c.Assert(err, IsNil)
+	c.Assert(isDefault, Equals, true)
+
+	foo.Server.Timeout = time.Minute
+	isDefault, err = IsAllDefault(foo)
+	c.Assert(err, IsNil)
+	c.Assert(isDefault, Equals, false)
+
+	// Untagged fields compare against their zero value.
+	isDefault, err = IsAllDefault(&struct{ Count int }{})
+	c.Assert(err, IsNil)
+	c.Assert(isDefault, Equals, true)
+
+	_, err = IsAllDefault(nil)
+	c.Assert(err, NotNil)
+}
+
+func (s *DiffSuite) TestIsDefault(c *C) {
+	foo := &ExampleDiff{}
+	SetDefaults(foo)
+	foo.Server.Timeout = time.Minute
+
+	for path, expected := range map[string]bool{
+		"Port":           true,
+		"Hosts":          true,
+		"Server":         false,
+		"Server.Timeout": false,
+		"TLS":            true,
+		"TLS.Cert":       true,
+	} {
+		isDefault, err := IsDefault(foo, path)
+		c.Assert(err, IsNil)
+		c.Assert(isDefault, Equals, expected, Commentf(path))
+	}
+
+	foo.TLS = &struct {
+		Cert string `default:"cert.pem"`
+	}{}
+	isDefault, err := IsDefault(foo, "TLS.Cert")
+	c.Assert(err, IsNil)
+	c.Assert(isDefault, Equals, false)
+
+	_, err = IsDefault(foo, "Server.Unknown")
+	c.Assert(err, ErrorMatches, `godefault: no field Server.Unknown in struct .*`)
+	_, err = IsDefault(foo, "Port.Value")
+	c.Assert(err, ErrorMatches, `godefault: no field Port.Value in int`)
+}
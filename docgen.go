@@ -0,0 +1,235 @@
+package godefault
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GenerateDoc renders a Markdown table listing every field of the struct
+// behind v that carries a default tag, with its key, type and raw default.
+// Keys are named after the yaml, toml or json tags of the fields, in that
+// order, and fields excluded from config files with "-" are left out.
+// Tags are shown as written, so values resolved at fill time (envs| mappings,
+// date placeholders) appear unresolved, and secret fields as "****".
+//
+//	| Field | Type | Default |
+//	| --- | --- | --- |
+//	| server.port | int | `8080` |
+func GenerateDoc(v interface{}, tagNames ...string) (string, error) {
+	return tagFiller(tagNames).GenerateDoc(v)
+}
+
+// GenerateDoc is GenerateDoc with the tag and the name tags of f, leaving out
+// the fields f skips, see WithHonorJSONDash.
+func (f *Filler) GenerateDoc(v interface{}) (string, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("| Field | Type | Default |\n")
+	buf.WriteString("| --- | --- | --- |\n")
+	f.walkType(t, func(tf *FieldInfo) {
+		if !tf.HasTag || tf.Excluded {
+			return
+		}
+		fmt.Fprintf(&buf, "| %s | %s | `%s` |\n", escapeCell(tf.Key()), escapeCell(tf.Field.Type.String()), escapeCell(redact(tf.Field, tf.Tag)))
+	})
+
+	return buf.String(), nil
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// GenerateJSONSchema returns a draft-07 JSON schema describing the struct
+// behind v, with the default tags as "default" keywords. Defaults that parse
+// statically are emitted typed, anything else as the raw tag string.
+// Properties are named like the keys of GenerateDoc.
+func GenerateJSONSchema(v interface{}, tagNames ...string) ([]byte, error) {
+	return tagFiller(tagNames).GenerateJSONSchema(v)
+}
+
+// GenerateJSONSchema is GenerateJSONSchema with the tag and the name tags of
+// f, leaving out the fields f skips, see WithHonorJSONDash.
+func (f *Filler) GenerateJSONSchema(v interface{}) ([]byte, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &schemaBuilder{filler: f, visiting: make(map[reflect.Type]bool)}
+	schema := b.structSchema(t)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	if t.Name() != "" {
+		schema["title"] = t.Name()
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaBuilder keeps track of the struct types being described so that
+// recursive types, e.g. linked through pointers, terminate.
+type schemaBuilder struct {
+	filler   *Filler
+	visiting map[reflect.Type]bool
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	if b.visiting[t] {
+		return map[string]interface{}{"type": "object"}
+	}
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || isMetaField(sf) || b.filler.skipsJSONDash(sf) || !b.filler.marks(sf) {
+			continue
+		}
+
+		tag, hasTag := sf.Tag.Lookup(b.filler.Tag)
+		if tag == "-" {
+			continue
+		}
+		name, inline, ok := externalName(sf, b.filler.nameTags)
+		if !ok {
+			continue
+		}
+
+		schema := b.typeSchema(sf.Type)
+		if inner, isObject := schema["properties"].(map[string]interface{}); inline && isObject {
+			for key, value := range inner {
+				properties[key] = value
+			}
+			continue
+		}
+		if hasTag && tag != "" && !isStructType(sf.Type) {
+			if isSecret(sf) {
+				schema["default"] = secretValue
+			} else {
+				schema["default"] = schemaDefault(sf.Type, tag, separatorOf(sf))
+			}
+		}
+		properties[name] = schema
+	}
+
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case durationType:
+		return map[string]interface{}{"type": "string", "format": "duration"}
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case hostPortType:
+		return map[string]interface{}{"type": "string"}
+	case syncMapType:
+		return map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.typeSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if isNullType(t) {
+			schema := b.typeSchema(t.Field(0).Type)
+			schema["type"] = []interface{}{schema["type"], "null"}
+			return schema
+		}
+		return b.structSchema(t)
+	}
+
+	return map[string]interface{}{}
+}
+
+// schemaDefault converts a tag value into its JSON representation, falling
+// back to the raw string when it cannot be parsed statically. sep separates
+// the elements of slices, see sepTag.
+func schemaDefault(t reflect.Type, tag string, sep rune) interface{} {
+	if t == durationType || t == timeType {
+		return tag
+	}
+	if isNullType(t) {
+		return schemaDefault(t.Field(0).Type, tag, sep)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if v, err := parseBoolValue(tag); err == nil {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, err := parseIntValue(tag, t); err == nil {
+			return v
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := parseUintValue(tag, t); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := parseFloatValue(tag, t); err == nil {
+			return v
+		}
+	case reflect.String:
+		if tag == "-," {
+			return "-"
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if isJSONValue(tag) {
+			if elems, err := jsonElements(tag); err == nil {
+				values := make([]interface{}, len(elems))
+				for i, elem := range elems {
+					values[i] = schemaDefault(t.Elem(), elem, defaultSeparator)
+				}
+				return values
+			}
+		}
+		if elems, ok, err := splitSliceTagSep(tag, sep); ok && err == nil {
+			values := make([]interface{}, len(elems))
+			for i, elem := range elems {
+				values[i] = schemaDefault(t.Elem(), elem, defaultSeparator)
+			}
+			return values
+		}
+	case reflect.Map:
+		if isLooseType(t) {
+			break
+		}
+		if entries, ok, err := splitMapTagSep(tag, sep); ok && err == nil {
+			values := make(map[string]interface{}, len(entries))
+			for _, entry := range entries {
+				values[entry.key] = schemaDefault(t.Elem(), entry.value, defaultSeparator)
+			}
+			return values
+		}
+	}
+
+	return tag
+}
@@ -0,0 +1,126 @@
+package godefault
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type DocgenSuite struct{}
+
+var _ = Suite(&DocgenSuite{})
+
+type ExampleDoc struct {
+	Name    string        `default:"app"`
+	Timeout time.Duration `default:"30s"`
+	Mode    string        `default:"envs|MODE|dev,a|prod,b"`
+	Plain   int
+	Server  struct {
+		Port  uint16   `default:"8080"`
+		Hosts []string `default:"[a,b]"`
+		Debug bool     `default:"true"`
+	}
+	Peers   []Child
+	Ignored int `default:"-"`
+}
+
+func (s *DocgenSuite) TestGenerateDoc(c *C) {
+	doc, err := GenerateDoc(&ExampleDoc{})
+	c.Assert(err, IsNil)
+	c.Assert(doc, Equals, "| Field | Type | Default |\n"+
+		"| --- | --- | --- |\n"+
+		"| Name | string | `app` |\n"+
+		"| Timeout | time.Duration | `30s` |\n"+
+		"| Mode | string | `envs\\|MODE\\|dev,a\\|prod,b` |\n"+
+		"| Server.Port | uint16 | `8080` |\n"+
+		"| Server.Hosts | []string | `[a,b]` |\n"+
+		"| Server.Debug | bool | `true` |\n"+
+		"| Peers[].Age | int | `10` |\n")
+}
+
+func (s *DocgenSuite) TestGenerateDocNotStruct(c *C) {
+	_, err := GenerateDoc("foo")
+	c.Assert(err, NotNil)
+}
+
+func (s *DocgenSuite) TestGenerateJSONSchema(c *C) {
+	data, err := GenerateJSONSchema(&ExampleDoc{})
+	c.Assert(err, IsNil)
+
+	var schema map[string]interface{}
+	c.Assert(json.Unmarshal(data, &schema), IsNil)
+	c.Assert(schema["title"], Equals, "ExampleDoc")
+	c.Assert(schema["type"], Equals, "object")
+
+	properties := schema["properties"].(map[string]interface{})
+	c.Assert(properties["Name"], DeepEquals, map[string]interface{}{"type": "string", "default": "app"})
+	c.Assert(properties["Timeout"], DeepEquals, map[string]interface{}{"type": "string", "format": "duration", "default": "30s"})
+	c.Assert(properties["Plain"], DeepEquals, map[string]interface{}{"type": "integer"})
+	c.Assert(properties["Ignored"], IsNil)
+
+	server := properties["Server"].(map[string]interface{})["properties"].(map[string]interface{})
+	c.Assert(server["Port"], DeepEquals, map[string]interface{}{"type": "integer", "default": 8080.0})
+	c.Assert(server["Hosts"], DeepEquals, map[string]interface{}{
+		"type":    "array",
+		"items":   map[string]interface{}{"type": "string"},
+		"default": []interface{}{"a", "b"},
+	})
+	c.Assert(server["Debug"], DeepEquals, map[string]interface{}{"type": "boolean", "default": true})
+}
+
+func (s *DocgenSuite) TestGenerateJSONSchemaNested(c *C) {
+	data, err := GenerateJSONSchema(&struct {
+		Groups map[string][]int `default:"{a:[1,2],b:[]}"`
+	}{})
+	c.Assert(err, IsNil)
+
+	var schema map[string]interface{}
+	c.Assert(json.Unmarshal(data, &schema), IsNil)
+	groups := schema["properties"].(map[string]interface{})["Groups"].(map[string]interface{})
+	c.Assert(groups["default"], DeepEquals, map[string]interface{}{"a": []interface{}{1.0, 2.0}, "b": []interface{}{}})
+}
+
+type exampleRecursive struct {
+	Value int `default:"1"`
+	Next  *exampleRecursive
+}
+
+func (s *DocgenSuite) TestGenerateJSONSchemaRecursive(c *C) {
+	_, err := GenerateJSONSchema(&exampleRecursive{})
+	c.Assert(err, IsNil)
+}
+
+func (s *DocgenSuite) TestGenerateDocRecursive(c *C) {
+	doc, err := GenerateDoc(&exampleRecursive{})
+	c.Assert(err, IsNil)
+	c.Assert(doc, Equals, "| Field | Type | Default |\n| --- | --- | --- |\n| Value | int | `1` |\n")
+}
+
+type ExampleSecret struct {
+	User  string `default:"admin"`
+	Token string `default:"s3cr3t" secret:"true"`
+	Port  int    `default:"abc" secret:"true"`
+}
+
+func (s *DocgenSuite) TestGenerateDocSecret(c *C) {
+	doc, err := GenerateDoc(&ExampleSecret{})
+	c.Assert(err, IsNil)
+	c.Assert(doc, Equals, "| Field | Type | Default |\n"+
+		"| --- | --- | --- |\n"+
+		"| User | string | `admin` |\n"+
+		"| Token | string | `****` |\n"+
+		"| Port | int | `****` |\n")
+
+	data, err := GenerateJSONSchema(&ExampleSecret{})
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(data), "s3cr3t"), Equals, false)
+	c.Assert(strings.Contains(string(data), `"default": "****"`), Equals, true)
+}
+
+func (s *DocgenSuite) TestSetDefaultsSecret(c *C) {
+	foo := &ExampleSecret{}
+	SetDefaults(foo)
+	c.Assert(foo.Token, Equals, "s3cr3t")
+}
@@ -0,0 +1,113 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// bareNumberPattern matches the duration defaults written without a unit.
+var bareNumberPattern = regexp.MustCompile(`^[+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)$`)
+
+// WithBareDurationUnit makes the fills read the defaults of duration fields
+// written as bare numbers in unit, e.g. `default:"30"` as 30 seconds with
+// time.Second, as the configs of libraries counting durations in seconds
+// write them:
+//
+//	                       "30"              "30s"   "0"
+//	no unit                error             30s     0
+//	time.Second            30s               30s     0
+//	time.Second, strict    30s and an error  30s     0
+//
+// Without the option a bare number other than 0 fails to parse, as it does
+// with time.ParseDuration. A bare number being more often a forgotten unit
+// than not, strict fills, see WithStrict, set the field from it but fail it.
+// Values assigned by layers and arguments use WithWeakDurationUnit instead.
+func WithBareDurationUnit(unit time.Duration) Option {
+	return func(f *Filler) {
+		f.bareDurationUnit = unit
+	}
+}
+
+// isBareNumber reports whether value, the default of a duration, has no
+// unit. 0 needs none.
+func isBareNumber(value string) bool {
+	return bareNumberPattern.MatchString(value) && value != "0"
+}
+
+// parseFieldDuration parses value, the default of the duration field, with
+// the bare number unit of its filler, see WithBareDurationUnit. Strict fills
+// fail field, the value parsed, when value is a bare number.
+func parseFieldDuration(field *FieldData, value string) (time.Duration, error) {
+	f := field.owner()
+	if f.bareDurationUnit == 0 || !isBareNumber(value) {
+		return parseDurationValue(value)
+	}
+
+	d, err := bareDuration(value, f.bareDurationUnit)
+	if err == nil && f.strict {
+		field.fail(parseErrorOf(bareDurationError(value, d)))
+	}
+
+	return d, err
+}
+
+func bareDurationError(value string, d time.Duration) error {
+	return fmt.Errorf("duration %s has no unit, read as %s", value, d)
+}
+
+// bareDurationTag returns tag, the default of sf checked with f, its bare
+// numbers written as the durations they stand for in the fills of f, or the
+// error strict fills report for them. Durations and slices and arrays of
+// durations have bare numbers.
+func bareDurationTag(f *Filler, sf reflect.StructField, tag string) (string, error) {
+	t := derefType(sf.Type)
+	switch {
+	case t == durationType:
+		if !isBareNumber(tag) {
+			return tag, nil
+		}
+		return checkBareDuration(f, tag)
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem() == durationType:
+		sep := separatorOf(sf)
+		elems, ok, err := splitSliceTagSep(tag, sep)
+		if !ok || err != nil {
+			return tag, nil
+		}
+		for i, elem := range elems {
+			if !isBareNumber(elem) {
+				continue
+			}
+			if elems[i], err = checkBareDuration(f, elem); err != nil {
+				return "", err
+			}
+		}
+		return "[" + strings.Join(elems, string(sep)) + "]", nil
+	}
+
+	return tag, nil
+}
+
+// checkBareDuration returns the bare number value as the duration it stands
+// for in the fills of f, or the error strict fills report for it.
+func checkBareDuration(f *Filler, value string) (string, error) {
+	d, err := bareDuration(value, f.bareDurationUnit)
+	if err == nil && f.strict {
+		err = bareDurationError(value, d)
+	}
+
+	return d.String(), err
+}
+
+// bareDuration returns the duration the bare number value stands for in
+// unit.
+func bareDuration(value string, unit time.Duration) (time.Duration, error) {
+	coerced, err := coerceValue(value, durationType, false, unit)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseDurationValue(coerced)
+}
@@ -0,0 +1,83 @@
+package godefault
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type DurationSuite struct{}
+
+var _ = Suite(&DurationSuite{})
+
+type ExampleBareDuration struct {
+	Bare    time.Duration   `default:"30"`
+	Unit    time.Duration   `default:"30s"`
+	Zero    time.Duration   `default:"0"`
+	Pointer *time.Duration  `default:"1.5"`
+	List    []time.Duration `default:"[30,1m]"`
+}
+
+func (s *DurationSuite) TestWithBareDurationUnit(c *C) {
+	for _, t := range []struct {
+		opts   []Option
+		err    string
+		bare   time.Duration
+		list   []time.Duration
+		checks int
+	}{
+		{
+			err:    `Bare: time: missing unit in duration "30"\nPointer: time: missing unit in duration "1.5"\nList\[0\]: time: missing unit in duration "30"`,
+			list:   []time.Duration{0, time.Minute},
+			checks: 3,
+		},
+		{
+			opts:   []Option{WithStrict()},
+			err:    `Bare: time: missing unit in duration "30"\nPointer: time: missing unit in duration "1.5"\nList\[0\]: time: missing unit in duration "30"`,
+			list:   []time.Duration{0, time.Minute},
+			checks: 3,
+		},
+		{
+			opts: []Option{WithBareDurationUnit(time.Second)},
+			bare: 30 * time.Second,
+			list: []time.Duration{30 * time.Second, time.Minute},
+		},
+		{
+			opts: []Option{WithBareDurationUnit(time.Minute)},
+			bare: 30 * time.Minute,
+			list: []time.Duration{30 * time.Minute, time.Minute},
+		},
+		{
+			opts:   []Option{WithBareDurationUnit(time.Second), WithStrict()},
+			err:    `Bare: duration 30 has no unit, read as 30s\nPointer: duration 1.5 has no unit, read as 1.5s\nList\[0\]: duration 30 has no unit, read as 30s`,
+			bare:   30 * time.Second,
+			list:   []time.Duration{30 * time.Second, time.Minute},
+			checks: 3,
+		},
+	} {
+		foo := &ExampleBareDuration{}
+		err := NewFiller(t.opts...).FillContext(context.Background(), foo)
+		if t.err == "" {
+			c.Assert(err, IsNil)
+		} else {
+			c.Assert(err, ErrorMatches, t.err)
+		}
+		c.Assert(foo.Bare, Equals, t.bare)
+		c.Assert(foo.Unit, Equals, 30*time.Second)
+		c.Assert(foo.Zero, Equals, time.Duration(0))
+		c.Assert(foo.List, DeepEquals, t.list)
+		if t.err == "" {
+			c.Assert(*foo.Pointer, Equals, t.bare/20)
+		}
+		c.Assert(NewFiller(t.opts...).CheckDefaults(&ExampleBareDuration{}), HasLen, t.checks)
+	}
+}
+
+func (s *DurationSuite) TestBareDurationFraction(c *C) {
+	foo := &struct {
+		Tiny time.Duration `default:"0.5"`
+	}{}
+	err := NewFiller(WithBareDurationUnit(time.Nanosecond)).FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Tiny: 0.5 would be truncated in a time.Duration, see WithLossyCoercion`)
+}
@@ -0,0 +1,53 @@
+package godefault
+
+import "reflect"
+
+// FillElement fills v, a pointer to a new element of a defaulted slice or
+// map, the way the elements of the initial fill were, so that values added
+// later get their defaults too:
+//
+//	server := ServerConfig{}
+//	filler.FillElement(&server)
+//	cfg.Servers = append(cfg.Servers, server)
+//
+// Pointers to structs are allocated when nil. The Plan of the element type is
+// compiled on the first call and cached by f, see Compile. Like Fill, it
+// ignores the tags that fail to parse.
+func (f *Filler) FillElement(v interface{}) {
+	value := reflect.ValueOf(v).Elem()
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			if !isStructType(derefType(value.Type())) {
+				return
+			}
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	if !isStructType(value.Type()) {
+		return
+	}
+
+	state := &fillState{recover: f.recover, root: value}
+	f.plan(value.Type()).apply(value, nil, state)
+	f.resolveLookups(state)
+}
+
+// plan returns the Plan of the struct type t, compiled once per Filler.
+func (f *Filler) plan(t reflect.Type) *Plan {
+	if p, ok := f.plans.Load(t); ok {
+		return p.(*Plan)
+	}
+
+	p, _ := f.plans.LoadOrStore(t, f.compile(t, ""))
+	return p.(*Plan)
+}
+
+// derefType returns the type behind any number of pointers of t.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package godefault
+
+// FillNew returns a new T filled with its defaults by filler, or by the
+// default filler when nil, see FillElement:
+//
+//	cfg.Servers = append(cfg.Servers, godefault.FillNew[ServerConfig](filler))
+func FillNew[T any](filler *Filler) T {
+	if filler == nil {
+		filler = getDefaultFiller()
+	}
+
+	var value T
+	filler.FillElement(&value)
+
+	return value
+}
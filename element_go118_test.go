@@ -0,0 +1,21 @@
+//go:build go1.18
+// +build go1.18
+
+package godefault
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *ElementSuite) TestFillNew(c *C) {
+	filler := NewFiller()
+	cfg := &ExampleServers{}
+	filler.Fill(cfg)
+
+	cfg.Servers = append(cfg.Servers, FillNew[ExampleServer](filler))
+	c.Assert(cfg.Servers[1], DeepEquals, cfg.Servers[0])
+	c.Assert(cfg.Servers[1].TLS.MinVersion, Equals, "1.2")
+
+	server := FillNew[*ExampleServer](nil)
+	c.Assert(server.Limits.Conns, Equals, 100)
+}
@@ -0,0 +1,81 @@
+package godefault
+
+import (
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type ElementSuite struct{}
+
+var _ = Suite(&ElementSuite{})
+
+type ExampleServer struct {
+	Addr    string `default:"localhost"`
+	Port    int    `default:"8080"`
+	Limits  ExampleLimits
+	TLS     *ExampleServerTLS `default:"{}"`
+	Aliases []string          `default:"[a,b]"`
+}
+
+type ExampleLimits struct {
+	Conns int `default:"100"`
+}
+
+type ExampleServerTLS struct {
+	MinVersion string `default:"1.2"`
+}
+
+type ExampleServers struct {
+	Servers []ExampleServer `default:"make:1"`
+}
+
+func (s *ElementSuite) TestFillElement(c *C) {
+	filler := NewFiller()
+	cfg := &ExampleServers{}
+	filler.Fill(cfg)
+
+	var server ExampleServer
+	filler.FillElement(&server)
+	cfg.Servers = append(cfg.Servers, server)
+
+	c.Assert(cfg.Servers, HasLen, 2)
+	c.Assert(cfg.Servers[1], DeepEquals, cfg.Servers[0])
+	c.Assert(server.Limits.Conns, Equals, 100)
+	c.Assert(server.TLS, NotNil)
+	c.Assert(server.TLS.MinVersion, Equals, "1.2")
+
+	// Elements don't share memory.
+	cfg.Servers[0].Aliases[0] = "c"
+	c.Assert(cfg.Servers[1].Aliases[0], Equals, "a")
+}
+
+func (s *ElementSuite) TestFillElementKeepsValues(c *C) {
+	server := ExampleServer{Port: 9090}
+	NewFiller().FillElement(&server)
+
+	c.Assert(server.Addr, Equals, "localhost")
+	c.Assert(server.Port, Equals, 9090)
+}
+
+func (s *ElementSuite) TestFillElementPointer(c *C) {
+	var server *ExampleServer
+	NewFiller().FillElement(&server)
+	c.Assert(server, NotNil)
+	c.Assert(server.Limits.Conns, Equals, 100)
+
+	// There is nothing to fill in other types.
+	var n int
+	NewFiller().FillElement(&n)
+	c.Assert(n, Equals, 0)
+}
+
+func (s *ElementSuite) TestFillElementCachesPlan(c *C) {
+	filler := NewFiller()
+	var server ExampleServer
+	filler.FillElement(&server)
+
+	plan, err := filler.Compile(reflect.TypeOf(server))
+	c.Assert(err, IsNil)
+	c.Assert(filler.plan(reflect.TypeOf(server)), Equals, plan)
+}
@@ -0,0 +1,79 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// enums holds the names registered with RegisterEnum, a map[string]int64 by
+// reflect.Type.
+var enums sync.Map
+
+// RegisterEnum registers the names the int-based enum type typ can be
+// defaulted with, usually from the init function of the package declaring
+// it:
+//
+//	type Level int
+//
+//	func init() {
+//	    godefault.RegisterEnum(reflect.TypeOf(Level(0)), map[string]int64{
+//	        "debug": 0, "info": 1, "warn": 2,
+//	    })
+//	}
+//
+//	Level Level `default:"info"`
+//
+// The names are case-sensitive and only apply to fields of type typ, or
+// pointers to it; numbers are still taken as they are. A default that is
+// neither fails the field with an ErrParse listing the names. Registering the
+// same type again replaces its names. RegisterEnum panics when typ is not a
+// signed integer type.
+func RegisterEnum(typ reflect.Type, names map[string]int64) {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		panic(fmt.Sprintf("godefault: RegisterEnum of %s, not a signed integer type", typ))
+	}
+
+	copied := make(map[string]int64, len(names))
+	for name, n := range names {
+		copied[name] = n
+	}
+	enums.Store(typ, copied)
+}
+
+// enumValue returns the value of the name registered for the enum type t,
+// see RegisterEnum. ok is false when t is not registered or doesn't know
+// name.
+func enumValue(name string, t reflect.Type) (n int64, ok bool, err error) {
+	stored, registered := enums.Load(t)
+	if !registered {
+		return 0, false, nil
+	}
+	if n, ok = stored.(map[string]int64)[name]; ok && reflect.Zero(t).OverflowInt(n) {
+		return 0, true, overflowf("%s %s = %d overflows %s", t, name, n, t)
+	}
+
+	return n, ok, nil
+}
+
+// enumError returns the error of a default of the enum type t that is
+// neither a number nor one of its names, err when t is not registered.
+func enumError(value string, t reflect.Type, err error) error {
+	stored, registered := enums.Load(t)
+	if !registered {
+		return err
+	}
+
+	names := stored.(map[string]int64)
+	known := make([]string, 0, len(names))
+	for name := range names {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+
+	return fmt.Errorf("unknown %s name %q, expected a number or one of %s", t, value, strings.Join(known, ", "))
+}
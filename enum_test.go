@@ -0,0 +1,61 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type EnumSuite struct{}
+
+var _ = Suite(&EnumSuite{})
+
+type exampleLevel int
+
+type exampleSmallEnum int8
+
+func init() {
+	RegisterEnum(reflect.TypeOf(exampleLevel(0)), map[string]int64{"debug": 0, "info": 1, "warn": 2})
+	RegisterEnum(reflect.TypeOf(exampleSmallEnum(0)), map[string]int64{"low": 1, "huge": 1000})
+}
+
+type ExampleEnum struct {
+	Level   exampleLevel     `default:"info"`
+	Numeric exampleLevel     `default:"2"`
+	Pointer *exampleLevel    `default:"warn"`
+	Levels  []exampleLevel   `default:"[debug,warn]"`
+	Small   exampleSmallEnum `default:"low"`
+	Plain   int              `default:"7"`
+}
+
+func (s *EnumSuite) TestEnum(c *C) {
+	foo := &ExampleEnum{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Level, Equals, exampleLevel(1))
+	c.Assert(foo.Numeric, Equals, exampleLevel(2))
+	c.Assert(*foo.Pointer, Equals, exampleLevel(2))
+	c.Assert(foo.Levels, DeepEquals, []exampleLevel{0, 2})
+	c.Assert(foo.Small, Equals, exampleSmallEnum(1))
+	c.Assert(foo.Plain, Equals, 7)
+	c.Assert(CheckDefaults(&ExampleEnum{}), HasLen, 0)
+
+	c.Assert(func() { RegisterEnum(reflect.TypeOf(""), nil) }, PanicMatches, `godefault: RegisterEnum of string, not a signed integer type`)
+}
+
+func (s *EnumSuite) TestEnumErrors(c *C) {
+	foo := &struct {
+		Unknown exampleLevel     `default:"verbose"`
+		Huge    exampleSmallEnum `default:"huge"`
+		Plain   int              `default:"info"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Unknown: unknown godefault.exampleLevel name "verbose", expected a number or one of debug, info, warn\n`+
+		`Huge: godefault.exampleSmallEnum huge = 1000 overflows godefault.exampleSmallEnum\n`+
+		`Plain: .*invalid syntax`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+
+	c.Assert(CheckDefaults(foo), HasLen, 3)
+}
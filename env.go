@@ -0,0 +1,356 @@
+package godefault
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sonnt85/gogmap"
+)
+
+// envRefPrefix introduces a default read from an environment variable, with
+// an optional fallback used when the variable is unset or empty:
+//
+//	Port int    `default:"env:PORT:8080"`
+//	Host string `default:"env:HOST"`
+//
+// Integer fields can apply a transform to the resolved value, see
+// splitIntTransform:
+//
+//	AdminPort int `default:"env:PORT:8080|+1"`
+const envRefPrefix = "env:"
+
+// envIndirectPrefix introduces a default read through an environment
+// variable holding the name of the variable to read, with the same fallback
+// and transforms as env: references:
+//
+//	// CONFIG_SOURCE=PRIMARY_DSN PRIMARY_DSN=postgres://db
+//	DSN string `default:"envindirect:CONFIG_SOURCE:sqlite://local"`
+//
+// Exactly two levels are resolved: the value of the second variable is used
+// as it is, even when it names a variable too, so cycles can't loop.
+const envIndirectPrefix = "envindirect:"
+
+// listSepTag names the tag choosing the separator of the lists read from an
+// environment variable into a slice, a comma otherwise. A variable feeding a
+// slice is split on it, the elements trimmed and the empty ones dropped,
+// unless it uses the bracket syntax of the defaults:
+//
+//	// SEED_HOSTS="a, b , c" or SEED_HOSTS=[a,b,c]
+//	Hosts []string `default:"env:SEED_HOSTS:[h1,h2]"`
+//	Paths []string `default:"env:PLUGIN_PATHS" listsep:":"`
+//
+// A whitespace separator splits on runs of any whitespace. The fallback of
+// the reference is a default like any other, brackets required.
+const listSepTag = "listsep"
+
+// The interpretations of a variable set to an empty value, see
+// WithEmptyEnvIsSet and FieldReport.EmptyEnv.
+const (
+	EmptyEnvUnset = "unset"
+	EmptyEnvSet   = "set"
+)
+
+// lookupEnv returns the value of key from gogmap, falling back to the process
+// environment. Empty values count as unset.
+func lookupEnv(key string) (string, bool) {
+	if value := gogmap.Get(key); value != "" {
+		return value, true
+	}
+
+	value := os.Getenv(key)
+	return value, value != ""
+}
+
+// lookupEnv is the lookupEnv of the variables read for field, which follows
+// the policy of its Filler for empty values and records the interpretation of
+// the empty variables it reads. Empty gogmap values always count as unset.
+func (field *FieldData) lookupEnv(key string) (string, bool) {
+	if value := gogmap.Get(key); value != "" {
+		return value, true
+	}
+
+	value, ok := os.LookupEnv(key)
+	if !ok || value != "" {
+		return value, ok
+	}
+	if field.owner().emptyEnvIsSet {
+		field.emptyEnv = EmptyEnvSet
+		return "", true
+	}
+	field.emptyEnv = EmptyEnvUnset
+
+	return "", false
+}
+
+// envUnsetError is the error of a variable key required unset, see
+// WithEnvRequired.
+func envUnsetError(key string) error {
+	return fmt.Errorf("%w: $%s is unset", ErrRequired, key)
+}
+
+// requiresEnvs fails field and reports true when its envs| mapping switches
+// on an unset variable its Filler requires, see WithEnvRequired.
+func requiresEnvs(field *FieldData) bool {
+	if !strings.HasPrefix(field.TagValue, "envs|") || !field.owner().envRequired {
+		return false
+	}
+	key, _, err := parseEnvsValue(field.TagValue)
+	if err != nil {
+		return false
+	}
+	if _, ok := field.lookupEnv(key); ok {
+		return false
+	}
+	field.fail(envUnsetError(key))
+
+	return true
+}
+
+// parseEnvRef splits "env:KEY[:fallback]" into its parts.
+func parseEnvRef(value string) (key, fallback string, ok bool) {
+	if !strings.HasPrefix(value, envRefPrefix) {
+		return "", "", false
+	}
+
+	key = value[len(envRefPrefix):]
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		key, fallback = key[:i], key[i+1:]
+	}
+
+	return key, fallback, key != ""
+}
+
+// resolveEnvRef replaces an env: or envindirect: reference by the value of
+// the variable, or by its fallback when the variable is unset or lookup is
+// nil. The transform of integer fields is kept after the resolved value.
+func resolveEnvRef(value string, t reflect.Type, lookup func(key string) (string, bool)) string {
+	suffix := ""
+	if isIntegerType(t) {
+		value, suffix = splitIntTransform(value)
+	}
+	if strings.HasPrefix(value, envIndirectPrefix) {
+		value = envRefPrefix + value[len(envIndirectPrefix):]
+		lookup = indirectLookup(lookup)
+	}
+
+	key, fallback, ok := parseEnvRef(value)
+	if !ok {
+		return value + suffix
+	}
+	if lookup != nil {
+		if resolved, ok := lookup(key); ok {
+			return resolved + suffix
+		}
+	}
+
+	return fallback + suffix
+}
+
+// envList returns the value of an environment variable feeding field as a
+// list in the bracket syntax, its elements separated by sep, when field is a
+// slice, see listSepTag.
+func envList(field *FieldData, value string, sep rune) string {
+	t := field.Field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice || t.Elem().Kind() == reflect.Uint8 {
+		return value
+	}
+	if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+		return trimmed
+	}
+
+	listSep := ","
+	if s := field.Field.Tag.Get(listSepTag); s != "" {
+		listSep = s
+	}
+	var parts []string
+	if strings.TrimSpace(listSep) == "" {
+		parts = strings.Fields(value)
+	} else {
+		parts = strings.Split(value, listSep)
+	}
+
+	// Quoted elements keep the separators and brackets they contain.
+	elems := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			elems = append(elems, strconv.Quote(part))
+		}
+	}
+
+	return "[" + strings.Join(elems, string(sep)) + "]"
+}
+
+// indirectLookup returns a lookup reading the variable named by the value
+// of the variable it is given.
+func indirectLookup(lookup func(key string) (string, bool)) func(key string) (string, bool) {
+	if lookup == nil {
+		return nil
+	}
+
+	return func(key string) (string, bool) {
+		name, ok := lookup(key)
+		if !ok {
+			return "", false
+		}
+
+		return lookup(name)
+	}
+}
+
+// envEntry is an entry of an envs| mapping: the value used when the variable
+// the mapping switches on equals name.
+type envEntry struct {
+	name  string
+	value string
+}
+
+var (
+	envsUnescaper   = strings.NewReplacer("__oror__", "|", "__orcomma__", ",")
+	envsUnreplacers = strings.NewReplacer("__oror__", "||", "__orcomma__", "|,")
+)
+
+// parseEnvsValue splits an envs| mapping, see parseEnvString, into the
+// variable it switches on and its entries, decoding the base64 ones. It never
+// panics: malformed mappings are reported as errors.
+func parseEnvsValue(value string) (string, []envEntry, error) {
+	parts := splitEnvsParts(strings.TrimPrefix(value, "envs|"))
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("invalid envs value %q, expected envs|[KEY|]name,value|...", value)
+	}
+
+	key := "EnvType"
+	if !strings.Contains(parts[0], ",") {
+		key, parts = envsUnescaper.Replace(parts[0]), parts[1:]
+	}
+	entries, err := parseEnvsEntries(parts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, entries, nil
+}
+
+// splitEnvsParts splits the body of an envs| mapping, or of a hosts| one, on
+// the bars that aren't escaped, the escapes being kept encoded.
+func splitEnvsParts(body string) []string {
+	escaped := strings.ReplaceAll(body, "|,", "__orcomma__")
+	escaped = strings.ReplaceAll(escaped, "||", "__oror__")
+
+	return strings.Split(escaped, "|")
+}
+
+// parseEnvsEntries parses the entries split by splitEnvsParts, decoding the
+// base64 ones.
+func parseEnvsEntries(parts []string) ([]envEntry, error) {
+	entries := make([]envEntry, 0, len(parts))
+	for _, part := range parts {
+		values := strings.Split(part, ",")
+		switch {
+		case len(values) == 2:
+			entries = append(entries, envEntry{name: envsUnescaper.Replace(values[0]), value: envsUnescaper.Replace(values[1])})
+		case len(values) == 3 && values[1] == "":
+			decoded, err := base64.StdEncoding.DecodeString(values[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid envs entry %q: %w", envsUnreplacers.Replace(part), err)
+			}
+			entries = append(entries, envEntry{name: envsUnescaper.Replace(values[0]), value: string(decoded)})
+		default:
+			return nil, fmt.Errorf("invalid envs entry %q, expected name,value or name,,base64", envsUnreplacers.Replace(part))
+		}
+	}
+
+	return entries, nil
+}
+
+// checkEnvsValue validates the grammar of an envs| mapping, see
+// parseEnvString, without reading the environment.
+func checkEnvsValue(value string) error {
+	_, _, err := parseEnvsValue(value)
+	return err
+}
+
+// EnvKeys returns the distinct names of the environment variables the default
+// tags of the struct behind v consult, through env:, envindirect:, jwtclaim:
+// and printf: references and envs| mappings, in declaration order, e.g. to
+// document the variables a deployment may set or to check them before
+// starting. The variables named by the value of an envindirect: variable are
+// only known at fill time and are not listed. It returns nil when v is not a
+// struct.
+//
+//	for _, key := range EnvKeys(&Config{}) {
+//	    if _, ok := os.LookupEnv(key); !ok {
+//	        log.Printf("%s is not set, using the default", key)
+//	    }
+//	}
+func EnvKeys(v interface{}, tagNames ...string) []string {
+	return tagFiller(tagNames).EnvKeys(v)
+}
+
+// EnvKeys is EnvKeys with the tag of f, leaving out the fields f skips, see
+// WithHonorJSONDash.
+func (f *Filler) EnvKeys(v interface{}) []string {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	f.walkType(t, func(tf *FieldInfo) {
+		for _, key := range envKeys(tf.Type, tf.Tag) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	})
+
+	return keys
+}
+
+// envKeys returns the names of the variables the tag value of a field of
+// type t consults, if any.
+func envKeys(t reflect.Type, value string) []string {
+	if strings.HasPrefix(value, "envs|") {
+		key, _, err := parseEnvsValue(value)
+		if err != nil {
+			return nil
+		}
+		return []string{key}
+	}
+	if isJWTClaimRef(value) {
+		key, _, _ := parseJWTClaimRef(value)
+		return []string{key}
+	}
+	if isPrintfRef(value) {
+		_, keys, _ := parsePrintfRef(value)
+		return keys
+	}
+	if isChooseRef(value) {
+		branches, _, _ := parseChooseRef(value)
+		var keys []string
+		for _, branch := range branches {
+			keys = append(keys, envKeys(t, branch.value)...)
+		}
+		return keys
+	}
+
+	if isIntegerType(t) {
+		value, _ = splitIntTransform(value)
+	}
+	if strings.HasPrefix(value, envIndirectPrefix) {
+		value = envRefPrefix + value[len(envIndirectPrefix):]
+	}
+	if key, _, ok := parseEnvRef(value); ok {
+		return []string{key}
+	}
+
+	return nil
+}
@@ -0,0 +1,327 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	. "gopkg.in/check.v1"
+)
+
+type EnvSuite struct{}
+
+var _ = Suite(&EnvSuite{})
+
+type ExampleEnv struct {
+	Port      int     `default:"env:GODEFAULT_TEST_PORT:8080"`
+	AdminPort int     `default:"env:GODEFAULT_TEST_PORT:8080|+1"`
+	Offset    int     `default:"env:GODEFAULT_TEST_PORT:8080|-80"`
+	Double    uint16  `default:"env:GODEFAULT_TEST_PORT:8080|*2"`
+	Host      string  `default:"env:GODEFAULT_TEST_HOST:localhost:8080"`
+	NoDefault string  `default:"env:GODEFAULT_TEST_HOST"`
+	Ratio     float64 `default:"env:GODEFAULT_TEST_RATIO:0.5"`
+	Literal   int     `default:"10|+5"`
+	Pointer   *int    `default:"env:GODEFAULT_TEST_PORT:8080|+2"`
+}
+
+func (s *EnvSuite) TestSetDefaultsEnvUnset(c *C) {
+	foo := &ExampleEnv{}
+	SetDefaults(foo)
+
+	c.Assert(foo.Port, Equals, 8080)
+	c.Assert(foo.AdminPort, Equals, 8081)
+	c.Assert(foo.Offset, Equals, 8000)
+	c.Assert(foo.Double, Equals, uint16(16160))
+	c.Assert(foo.Host, Equals, "localhost:8080")
+	c.Assert(foo.NoDefault, Equals, "")
+	c.Assert(foo.Ratio, Equals, 0.5)
+	c.Assert(foo.Literal, Equals, 15)
+	c.Assert(*foo.Pointer, Equals, 8082)
+}
+
+func (s *EnvSuite) TestSetDefaultsEnvSet(c *C) {
+	os.Setenv("GODEFAULT_TEST_PORT", "9000")
+	os.Setenv("GODEFAULT_TEST_HOST", "example.com")
+	defer os.Unsetenv("GODEFAULT_TEST_PORT")
+	defer os.Unsetenv("GODEFAULT_TEST_HOST")
+
+	foo := &ExampleEnv{}
+	SetDefaults(foo)
+
+	c.Assert(foo.Port, Equals, 9000)
+	c.Assert(foo.AdminPort, Equals, 9001)
+	c.Assert(foo.Offset, Equals, 8920)
+	c.Assert(foo.Double, Equals, uint16(18000))
+	c.Assert(foo.Host, Equals, "example.com")
+	c.Assert(foo.NoDefault, Equals, "example.com")
+}
+
+func (s *EnvSuite) TestFillContextTransformErrors(c *C) {
+	foo := &struct {
+		Small    int8 `default:"127|+1"`
+		Negative uint `default:"5|-6"`
+		NotInt   int  `default:"env:GODEFAULT_TEST_PORT:abc|+1"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Small: 127\|\+1 overflows int8\nNegative: 5\|-6 overflows uint\nNotInt: .*invalid syntax`)
+	c.Assert(foo.Small, Equals, int8(0))
+	c.Assert(foo.Negative, Equals, uint(0))
+}
+
+func (s *EnvSuite) TestCheckDefaultsEnv(c *C) {
+	c.Assert(CheckDefaults(&ExampleEnv{}), HasLen, 0)
+
+	errs := CheckDefaults(&struct {
+		BadFallback int    `default:"env:PORT:abc"`
+		Overflow    int8   `default:"env:PORT:100|*2"`
+		NoKey       string `default:"env::foo"`
+	}{})
+	c.Assert(errs, HasLen, 3)
+}
+
+type ExampleEnvIndirect struct {
+	DSN     string `default:"envindirect:GODEFAULT_TEST_SOURCE:sqlite://local"`
+	Port    int    `default:"envindirect:GODEFAULT_TEST_SOURCE_PORT:8080|+1"`
+	Chained string `default:"envindirect:GODEFAULT_TEST_LOOP_A"`
+}
+
+func (s *EnvSuite) TestSetDefaultsEnvIndirect(c *C) {
+	foo := &ExampleEnvIndirect{}
+	SetDefaults(foo)
+	c.Assert(foo.DSN, Equals, "sqlite://local")
+	c.Assert(foo.Port, Equals, 8081)
+
+	os.Setenv("GODEFAULT_TEST_SOURCE", "GODEFAULT_TEST_PRIMARY")
+	os.Setenv("GODEFAULT_TEST_SOURCE_PORT", "GODEFAULT_TEST_PORT")
+	os.Setenv("GODEFAULT_TEST_PORT", "9000")
+	os.Setenv("GODEFAULT_TEST_LOOP_A", "GODEFAULT_TEST_LOOP_B")
+	os.Setenv("GODEFAULT_TEST_LOOP_B", "GODEFAULT_TEST_LOOP_A")
+	defer os.Unsetenv("GODEFAULT_TEST_SOURCE")
+	defer os.Unsetenv("GODEFAULT_TEST_SOURCE_PORT")
+	defer os.Unsetenv("GODEFAULT_TEST_PORT")
+	defer os.Unsetenv("GODEFAULT_TEST_LOOP_A")
+	defer os.Unsetenv("GODEFAULT_TEST_LOOP_B")
+
+	// The variable named by the first one is unset.
+	foo = &ExampleEnvIndirect{}
+	SetDefaults(foo)
+	c.Assert(foo.DSN, Equals, "sqlite://local")
+	c.Assert(foo.Port, Equals, 9001)
+	c.Assert(foo.Chained, Equals, "GODEFAULT_TEST_LOOP_A")
+
+	os.Setenv("GODEFAULT_TEST_PRIMARY", "postgres://db")
+	defer os.Unsetenv("GODEFAULT_TEST_PRIMARY")
+	foo = &ExampleEnvIndirect{}
+	SetDefaults(foo)
+	c.Assert(foo.DSN, Equals, "postgres://db")
+}
+
+func (s *EnvSuite) TestCheckDefaultsEnvIndirect(c *C) {
+	c.Assert(CheckDefaults(&ExampleEnvIndirect{}), HasLen, 0)
+
+	errs := CheckDefaults(&struct {
+		BadFallback int    `default:"envindirect:PORT:abc"`
+		NoKey       string `default:"envindirect::foo"`
+	}{})
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[1], ErrorMatches, `NoKey: invalid env reference "envindirect::foo", expected envindirect:KEY\[:fallback\]`)
+}
+
+func (s *EnvSuite) TestParseEnvStringMalformed(c *C) {
+	os.Setenv("GODEFAULT_TEST_MODE", "qa")
+	defer os.Unsetenv("GODEFAULT_TEST_MODE")
+
+	// No entry matches: the first one is the default, decoded.
+	c.Assert(parseEnvString("envs|GODEFAULT_TEST_MODE|dev,,MTE=|prod,12"), Equals, "11")
+	c.Assert(parseEnvString("envs|GODEFAULT_TEST_MODE|dev,1|qa,a||b|,c"), Equals, "a|b,c")
+
+	for _, value := range []string{"envs|", "envs|KEY|dev", "envs|KEY|dev,1,2", "envs|KEY|dev,,!!", "envs|KEY|dev,1|"} {
+		c.Assert(parseEnvString(value), Equals, value)
+	}
+}
+
+type ExampleEnvKeys struct {
+	Port    int    `default:"env:PORT:8080|+1"`
+	Host    string `default:"env:HOST"`
+	Mode    string `default:"envs|MODE|dev,a|prod,b"`
+	Default string `default:"envs|dev,a|prod,b"`
+	Plain   string `default:"foo"`
+	Server  struct {
+		Port   int    `default:"env:PORT"`
+		Config string `default:"envindirect:CONFIG_KEY:app.yaml"`
+	}
+	Peers []struct {
+		Token string `default:"env:PEER_TOKEN"`
+	}
+	Broken string `default:"envs|MODE|dev"`
+	Tenant string `default:"jwtclaim:TOKEN:tenant"`
+}
+
+func (s *EnvSuite) TestEnvKeys(c *C) {
+	c.Assert(EnvKeys(&ExampleEnvKeys{}), DeepEquals, []string{"PORT", "HOST", "MODE", "EnvType", "CONFIG_KEY", "PEER_TOKEN", "TOKEN"})
+	c.Assert(EnvKeys(ExampleEnvKeys{}, "other"), HasLen, 0)
+	c.Assert(EnvKeys(42), IsNil)
+}
+
+type ExampleEmptyEnv struct {
+	Host     string `default:"env:GODEFAULT_TEST_EMPTY:localhost"`
+	Indirect string `default:"envindirect:GODEFAULT_TEST_EMPTY_NAME:fallback"`
+	Mode     string `default:"envs|GODEFAULT_TEST_EMPTY|dev,1|prod,2"`
+	Subject  string `default:"jwtclaim:GODEFAULT_TEST_EMPTY_TOKEN:sub"`
+}
+
+func (s *EnvSuite) TestWithEmptyEnvIsSet(c *C) {
+	defer os.Unsetenv("GODEFAULT_TEST_EMPTY")
+	defer os.Unsetenv("GODEFAULT_TEST_EMPTY_NAME")
+	defer os.Unsetenv("GODEFAULT_TEST_EMPTY_TOKEN")
+
+	type result struct {
+		host, indirect, mode, subject string
+	}
+	unset, empty := func() {}, func() {
+		os.Setenv("GODEFAULT_TEST_EMPTY", "")
+		os.Setenv("GODEFAULT_TEST_EMPTY_NAME", "")
+		os.Setenv("GODEFAULT_TEST_EMPTY_TOKEN", "")
+	}
+	nonEmpty := func() {
+		os.Setenv("GODEFAULT_TEST_EMPTY", "prod")
+		os.Setenv("GODEFAULT_TEST_EMPTY_NAME", "GODEFAULT_TEST_EMPTY")
+		os.Setenv("GODEFAULT_TEST_EMPTY_TOKEN", testToken(`{"sub":"alice"}`))
+	}
+	for name, test := range map[string]struct {
+		set      bool
+		setup    func()
+		expected result
+		emptyEnv string
+	}{
+		"unset":                 {false, unset, result{"localhost", "fallback", "1", ""}, ""},
+		"empty":                 {false, empty, result{"localhost", "fallback", "1", ""}, EmptyEnvUnset},
+		"non-empty":             {false, nonEmpty, result{"prod", "prod", "2", "alice"}, ""},
+		"set policy, unset":     {true, unset, result{"localhost", "fallback", "1", ""}, ""},
+		"set policy, empty":     {true, empty, result{"", "fallback", "1", ""}, EmptyEnvSet},
+		"set policy, non-empty": {true, nonEmpty, result{"prod", "prod", "2", "alice"}, ""},
+	} {
+		os.Unsetenv("GODEFAULT_TEST_EMPTY")
+		os.Unsetenv("GODEFAULT_TEST_EMPTY_NAME")
+		os.Unsetenv("GODEFAULT_TEST_EMPTY_TOKEN")
+		test.setup()
+
+		foo := &ExampleEmptyEnv{}
+		report := &FillReport{}
+		c.Assert(NewFiller(WithEmptyEnvIsSet(test.set), WithReport(report)).FillContext(context.Background(), foo), IsNil)
+		c.Assert(result{foo.Host, foo.Indirect, foo.Mode, foo.Subject}, Equals, test.expected, Commentf(name))
+		for _, field := range report.Fields {
+			c.Assert(field.EmptyEnv, Equals, test.emptyEnv, Commentf("%s: %s", name, field.Path))
+		}
+	}
+}
+
+func (s *EnvSuite) TestEnvLayerEmptyEnvIsSet(c *C) {
+	defer os.Unsetenv("GODEFAULT_TEST_HOST")
+
+	for name, test := range map[string]struct {
+		set      bool
+		value    *string
+		expected string
+	}{
+		"unset":                 {false, nil, "localhost"},
+		"empty":                 {false, new(string), "localhost"},
+		"non-empty":             {false, &[]string{"example.com"}[0], "example.com"},
+		"set policy, unset":     {true, nil, "localhost"},
+		"set policy, empty":     {true, new(string), ""},
+		"set policy, non-empty": {true, &[]string{"example.com"}[0], "example.com"},
+	} {
+		os.Unsetenv("GODEFAULT_TEST_HOST")
+		if test.value != nil {
+			os.Setenv("GODEFAULT_TEST_HOST", *test.value)
+		}
+
+		foo := &struct {
+			Host string `default:"localhost"`
+		}{}
+		c.Assert(NewFiller(WithEmptyEnvIsSet(test.set)).Apply(foo, EnvLayer("godefault_test"), TagLayer()), IsNil)
+		c.Assert(foo.Host, Equals, test.expected, Commentf(name))
+	}
+}
+
+type ExampleEnvRequired struct {
+	Token    string `default:"env:GODEFAULT_TEST_REQUIRED_TOKEN"`
+	Port     int    `default:"env:GODEFAULT_TEST_REQUIRED_PORT:8080"`
+	Indirect string `default:"envindirect:GODEFAULT_TEST_REQUIRED_NAME"`
+	Mode     string `default:"envs|GODEFAULT_TEST_REQUIRED_MODE|dev,1|prod,2"`
+}
+
+func (s *EnvSuite) TestWithEnvRequired(c *C) {
+	foo := &ExampleEnvRequired{}
+	c.Assert(NewFiller(WithEnvRequired(false)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(*foo, Equals, ExampleEnvRequired{Port: 8080, Mode: "1"})
+
+	foo = &ExampleEnvRequired{}
+	report := &FillReport{}
+	err := NewFiller(WithEnvRequired(true), WithReport(report)).FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Token: required field is not set: \$GODEFAULT_TEST_REQUIRED_TOKEN is unset\n`+
+		`Indirect: required field is not set: \$GODEFAULT_TEST_REQUIRED_NAME is unset\n`+
+		`Mode: required field is not set: \$GODEFAULT_TEST_REQUIRED_MODE is unset`)
+	c.Assert(errors.Is(err, ErrRequired), Equals, true)
+	c.Assert(*foo, Equals, ExampleEnvRequired{Port: 8080})
+	c.Assert(report.Fields[0].Source, Equals, SourceError)
+
+	os.Setenv("GODEFAULT_TEST_REQUIRED_TOKEN", "secret")
+	os.Setenv("GODEFAULT_TEST_REQUIRED_NAME", "GODEFAULT_TEST_REQUIRED_TOKEN")
+	os.Setenv("GODEFAULT_TEST_REQUIRED_MODE", "prod")
+	defer os.Unsetenv("GODEFAULT_TEST_REQUIRED_TOKEN")
+	defer os.Unsetenv("GODEFAULT_TEST_REQUIRED_NAME")
+	defer os.Unsetenv("GODEFAULT_TEST_REQUIRED_MODE")
+	foo = &ExampleEnvRequired{}
+	c.Assert(NewFiller(WithEnvRequired(true)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(*foo, Equals, ExampleEnvRequired{Token: "secret", Port: 8080, Indirect: "secret", Mode: "2"})
+}
+
+type ExampleEnvList struct {
+	Hosts  []string `default:"env:GODEFAULT_TEST_HOSTS:[h1,h2]"`
+	Ports  []int    `default:"env:GODEFAULT_TEST_PORTS"`
+	Paths  []string `default:"env:GODEFAULT_TEST_PATHS" listsep:":" sep:";"`
+	Words  []string `default:"env:GODEFAULT_TEST_WORDS" listsep:" "`
+	Secret []byte   `default:"env:GODEFAULT_TEST_HOSTS"`
+}
+
+func (s *EnvSuite) TestSetDefaultsEnvList(c *C) {
+	foo := &ExampleEnvList{}
+	SetDefaults(foo)
+	c.Assert(foo.Hosts, DeepEquals, []string{"h1", "h2"})
+	c.Assert(foo.Ports, IsNil)
+
+	for value, expected := range map[string][]string{
+		"a, b , c":    {"a", "b", "c"},
+		"[x,y]":       {"x", "y"},
+		" [x, y] ":    {"x", "y"},
+		"a,,b,":       {"a", "b"},
+		`a"b,[c],d|e`: {`a"b`, "[c]", "d|e"},
+		",":           {},
+	} {
+		os.Setenv("GODEFAULT_TEST_HOSTS", value)
+		foo := &ExampleEnvList{}
+		SetDefaults(foo)
+		c.Assert(foo.Hosts, DeepEquals, expected, Commentf("%s", value))
+		c.Assert(string(foo.Secret), Equals, value, Commentf("%s", value))
+	}
+	os.Unsetenv("GODEFAULT_TEST_HOSTS")
+
+	os.Setenv("GODEFAULT_TEST_PORTS", "80, 443")
+	os.Setenv("GODEFAULT_TEST_PATHS", "/usr/lib;x:/opt/lib")
+	os.Setenv("GODEFAULT_TEST_WORDS", " one\ttwo  three ")
+	defer os.Unsetenv("GODEFAULT_TEST_PORTS")
+	defer os.Unsetenv("GODEFAULT_TEST_PATHS")
+	defer os.Unsetenv("GODEFAULT_TEST_WORDS")
+	foo = &ExampleEnvList{}
+	SetDefaults(foo)
+	c.Assert(foo.Ports, DeepEquals, []int{80, 443})
+	c.Assert(foo.Paths, DeepEquals, []string{"/usr/lib;x", "/opt/lib"})
+	c.Assert(foo.Words, DeepEquals, []string{"one", "two", "three"})
+
+	os.Setenv("GODEFAULT_TEST_PEERS", "a, b")
+	defer os.Unsetenv("GODEFAULT_TEST_PEERS")
+	bar := &struct{ Peers []string }{}
+	c.Assert(Apply(bar, EnvLayer("godefault_test")), IsNil)
+	c.Assert(bar.Peers, DeepEquals, []string{"a", "b"})
+}
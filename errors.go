@@ -0,0 +1,204 @@
+package godefault
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The kinds of the errors reported by the error-returning fills, such as
+// FillContext, for errors.Is. The errors of the fields are FieldErrors, which
+// give the path of the field, see FieldData.Path, and keep the message of
+// the error they wrap; a fill failing several fields returns them all as an
+// AggregateError:
+//
+//	if errors.Is(err, godefault.ErrRequired) {
+//	    log.Fatal(err) // a setting is missing
+//	} else if errors.Is(err, godefault.ErrResolver) {
+//	    log.Print(err) // a source is down, the fallbacks were used
+//	}
+var (
+	// ErrInvalidTarget is a value that can't be filled or inspected, such
+	// as a nil pointer or a pointer to something else than a struct.
+	ErrInvalidTarget = errors.New("invalid target")
+	// ErrParse is a tag value that doesn't parse into its field.
+	ErrParse = errors.New("invalid default value")
+	// ErrOverflow is a tag value out of the range of its field; it is an
+	// ErrParse too.
+	ErrOverflow = errors.New("default value overflows")
+	// ErrRequired is a field tagged `required:"true"` left unset by a strict
+	// fill, see WithStrict.
+	ErrRequired = errors.New("required field is not set")
+	// ErrPolicy is a tag value using a feature the Filler doesn't allow, see
+	// Restrict.
+	ErrPolicy = errors.New("default uses a restricted feature")
+	// ErrResolver is a reference whose source failed, such as a kv: store, a
+	// jwtclaim: token or a generator, see ResolverError.
+	ErrResolver = errors.New("default reference failed to resolve")
+	// ErrUnknownPath is a reference to a field that doesn't exist, such as
+	// the sibling of a ref: or the field of a command line argument.
+	ErrUnknownPath = errors.New("no such field")
+)
+
+// FieldError is the error of a field a fill, or CheckDefaults, failed on.
+type FieldError struct {
+	// Path is the path of the field, see FieldData.Path.
+	Path string
+	// Tag is the raw default tag of the field, "****" for secret fields.
+	Tag string
+	// Err is what went wrong, whose kind errors.Is tells.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ResolverError is the error of the source a reference read, an ErrResolver.
+type ResolverError struct {
+	// Prefix is the prefix of the reference, e.g. "kv:".
+	Prefix string
+	// Err is the error of the source.
+	Err error
+}
+
+func (e *ResolverError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResolverError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ResolverError) Is(target error) bool {
+	return target == ErrResolver
+}
+
+// kindError gives err the kind kind, leaving its message as it is.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// invalidTargetf formats an ErrInvalidTarget.
+func invalidTargetf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrInvalidTarget, err: fmt.Errorf(format, args...)}
+}
+
+// unknownPathf formats an ErrUnknownPath.
+func unknownPathf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrUnknownPath, err: fmt.Errorf(format, args...)}
+}
+
+// parseError gives an error of a parser the kind kind, leaving its message
+// as it is.
+type parseError struct {
+	kind error
+	err  error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *parseError) Unwrap() error {
+	return e.err
+}
+
+func (e *parseError) Is(target error) bool {
+	return target == e.kind || target == ErrParse
+}
+
+// overflowf formats an ErrOverflow.
+func overflowf(format string, args ...interface{}) error {
+	return &parseError{kind: ErrOverflow, err: fmt.Errorf(format, args...)}
+}
+
+// parseErrorOf returns err as an ErrParse, or as an ErrOverflow for the range
+// errors of strconv. ErrResolver errors are returned as they are.
+func parseErrorOf(err error) error {
+	if errors.Is(err, ErrParse) || errors.Is(err, ErrResolver) {
+		return err
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return &parseError{kind: ErrOverflow, err: err}
+	}
+
+	return &parseError{kind: ErrParse, err: err}
+}
+
+// AggregateError holds the errors of the fields a fill failed on, in the
+// order it visited them. Its message has one line per error.
+type AggregateError []error
+
+func (e AggregateError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Paths returns the paths of the fields that failed, in order, once each.
+func (e AggregateError) Paths() []string {
+	var paths []string
+	seen := make(map[string]bool, len(e))
+	for _, err := range e {
+		var fe *FieldError
+		if errors.As(err, &fe) && !seen[fe.Path] {
+			seen[fe.Path] = true
+			paths = append(paths, fe.Path)
+		}
+	}
+
+	return paths
+}
+
+// Is reports whether one of the errors is target, for errors.Is.
+func (e AggregateError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error matching target, for errors.As.
+func (e AggregateError) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinErrors returns nil, or the errors as an AggregateError.
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return AggregateError(errs)
+}
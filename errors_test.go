@@ -0,0 +1,151 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+
+	. "gopkg.in/check.v1"
+)
+
+type ErrorsSuite struct{}
+
+var _ = Suite(&ErrorsSuite{})
+
+type ExampleErrorPaths struct {
+	Servers []struct {
+		Addr int `default:"x"`
+	}
+	Databases map[string]int `default:"{primary:a}"`
+	Ports     []int8         `default:"[1,300]"`
+	Small     int8           `default:"127|+1"`
+	Token     string         `required:"true"`
+}
+
+func (s *ErrorsSuite) TestFieldDataPath(c *C) {
+	foo := &ExampleErrorPaths{Servers: make([]struct {
+		Addr int `default:"x"`
+	}, 3)}
+	err := SetDefaultsContext(context.Background(), foo, WithStrict())
+	c.Assert(err, ErrorMatches, `Servers\[0\]\.Addr: .*\nServers\[1\]\.Addr: .*\nServers\[2\]\.Addr: .*\n`+
+		`Databases\[primary\]: .*\nPorts\[1\]: .*\nSmall: .*\nToken: required field is not set`)
+}
+
+func (s *ErrorsSuite) TestErrorKinds(c *C) {
+	err := SetDefaultsContext(context.Background(), &ExampleErrorPaths{}, WithStrict())
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+	c.Assert(errors.Is(err, ErrRequired), Equals, true)
+
+	var numErr *strconv.NumError
+	c.Assert(errors.As(err, &numErr), Equals, true)
+	c.Assert(numErr.Num, Equals, "a")
+
+	err = SetDefaultsContext(context.Background(), &struct {
+		Int int `default:"x"`
+	}{})
+	c.Assert(err, ErrorMatches, `Int: strconv.ParseInt: parsing "x": invalid syntax`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, false)
+	c.Assert(errors.Is(err, ErrRequired), Equals, false)
+
+	err = SetDefaultsContext(context.Background(), &struct {
+		Int int8 `default:"300"`
+	}{})
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+}
+
+func (s *ErrorsSuite) TestOverflowLeavesZero(c *C) {
+	foo := &struct {
+		Uint8     uint8    `default:"300"`
+		Int8      int8     `default:"-200"`
+		Int64     int64    `default:"9223372036854775808"`
+		Transform uint8    `default:"100|+200"`
+		Float32   float32  `default:"1e40"`
+		Pointer   *uint8   `default:"256"`
+		Elements  []uint16 `default:"[1,70000]"`
+		Big       *float32 `default:"-1e40"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+	c.Assert(err.(AggregateError), HasLen, 8)
+
+	c.Assert(foo.Uint8, Equals, uint8(0))
+	c.Assert(foo.Int8, Equals, int8(0))
+	c.Assert(foo.Int64, Equals, int64(0))
+	c.Assert(foo.Transform, Equals, uint8(0))
+	c.Assert(foo.Float32, Equals, float32(0))
+	c.Assert(foo.Pointer, IsNil)
+	c.Assert(foo.Elements, DeepEquals, []uint16{1, 0})
+	c.Assert(foo.Big, IsNil)
+}
+
+func (s *ErrorsSuite) TestPathIsCached(c *C) {
+	parent := &FieldData{Field: reflect.StructField{Name: "Servers"}}
+	elem := parent.element(reflect.ValueOf(0), "2")
+	field := &FieldData{Field: reflect.StructField{Name: "Addr"}, Parent: elem}
+	c.Assert(field.Path(), Equals, "Servers[2].Addr")
+
+	parent.Field.Name = "Other"
+	c.Assert(field.Path(), Equals, "Servers[2].Addr")
+}
+
+func (s *ErrorsSuite) TestErrorTaxonomy(c *C) {
+	foo := &struct {
+		Int    int    `default:"x"`
+		Small  int8   `default:"300"`
+		Ref    string `default:"ref:Unknown"`
+		Var    string `default:"var:godefault.Unregistered"`
+		KV     string `default:"kv:nope://key"`
+		Token  string `default:"env:GODEFAULT_TEST_UNSET" secret:"true" required:"true"`
+		Ignore string `default:"ok"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo, WithStrict())
+	c.Assert(err, ErrorMatches, `Int: .*invalid syntax\n`+
+		`Small: value 300 overflows int8\n`+
+		`Var: var:godefault.Unregistered: no such registered variable\n`+
+		`KV: kv:nope://key: no key-value source for scheme "nope"\n`+
+		`Token: required field is not set\n`+
+		`Ref: ref:Unknown: no such sibling field`)
+
+	var agg AggregateError
+	c.Assert(errors.As(err, &agg), Equals, true)
+	c.Assert(agg.Paths(), DeepEquals, []string{"Int", "Small", "Var", "KV", "Token", "Ref"})
+
+	kinds := []error{ErrParse, ErrOverflow, ErrResolver, ErrResolver, ErrRequired, ErrUnknownPath}
+	for i, fieldErr := range agg {
+		c.Assert(errors.Is(fieldErr, kinds[i]), Equals, true, Commentf("%v", fieldErr))
+	}
+	c.Assert(errors.Is(agg[0], ErrResolver), Equals, false)
+	c.Assert(errors.Is(agg[2], ErrParse), Equals, false)
+
+	var fe *FieldError
+	c.Assert(errors.As(agg[0], &fe), Equals, true)
+	c.Assert(fe.Path, Equals, "Int")
+	c.Assert(fe.Tag, Equals, "x")
+	c.Assert(errors.As(agg[4], &fe), Equals, true)
+	c.Assert(fe.Tag, Equals, secretValue)
+
+	var re *ResolverError
+	c.Assert(errors.As(agg[3], &re), Equals, true)
+	c.Assert(re.Prefix, Equals, kvPrefix)
+
+	// A single failure is an AggregateError too.
+	err = SetDefaultsContext(context.Background(), &struct {
+		Int int `default:"x"`
+	}{})
+	c.Assert(errors.As(err, &agg), Equals, true)
+	c.Assert(agg.Paths(), DeepEquals, []string{"Int"})
+
+	c.Assert(errors.Is(SetDefaultsContext(context.Background(), struct{}{}), ErrInvalidTarget), Equals, true)
+	c.Assert(errors.Is(Apply(ExampleErrorPaths{}), ErrInvalidTarget), Equals, true)
+	c.Assert(errors.Is(ApplyArgs(&ExampleErrorPaths{}, []string{"--nope=1"}), ErrUnknownPath), Equals, true)
+	_, err = Compile(reflect.TypeOf(0))
+	c.Assert(errors.Is(err, ErrInvalidTarget), Equals, true)
+
+	errs := CheckDefaults(foo)
+	c.Assert(errors.As(errs[0], &fe), Equals, true)
+	c.Assert(fe.Path, Equals, "Int")
+}
@@ -0,0 +1,320 @@
+package godefault
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exprPrefix introduces the default of a number computed from its sibling
+// fields once they are filled, see PhaseDerived:
+//
+//	MaxOpenConns int           `default:"env:MAX_CONNS:20"`
+//	MaxIdleConns int           `default:"expr:MaxOpenConns/2"`
+//	BurstLimit   float64       `default:"expr:(RateLimit+1)*2"`
+//	Deadline     time.Duration `default:"expr:Timeout*3+500ms"`
+//
+// Expressions are made of +, -, *, /, parentheses, numbers, durations such as
+// 500ms, and the names of integer, float or duration siblings, durations
+// counting nanoseconds. They are evaluated exactly, the result truncated
+// toward zero for integer and duration fields, and must fit the field.
+// Siblings are filled before the fields referring to them, whatever the
+// order they are declared in; a cycle of references fails the fields.
+const exprPrefix = "expr:"
+
+func isExprRef(value string) bool {
+	return strings.HasPrefix(value, exprPrefix)
+}
+
+// exprNode is a node of a parsed expression, see exprPrefix.
+type exprNode interface {
+	eval(lookup func(name string) (*big.Rat, error)) (*big.Rat, error)
+}
+
+type exprNumber struct{ value *big.Rat }
+
+type exprName struct{ name string }
+
+type exprNeg struct{ x exprNode }
+
+type exprBinary struct {
+	op   byte
+	x, y exprNode
+}
+
+func (n exprNumber) eval(func(string) (*big.Rat, error)) (*big.Rat, error) {
+	return n.value, nil
+}
+
+func (n exprName) eval(lookup func(string) (*big.Rat, error)) (*big.Rat, error) {
+	return lookup(n.name)
+}
+
+func (n exprNeg) eval(lookup func(string) (*big.Rat, error)) (*big.Rat, error) {
+	x, err := n.x.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Rat).Neg(x), nil
+}
+
+func (n exprBinary) eval(lookup func(string) (*big.Rat, error)) (*big.Rat, error) {
+	x, err := n.x.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	y, err := n.y.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case '+':
+		return new(big.Rat).Add(x, y), nil
+	case '-':
+		return new(big.Rat).Sub(x, y), nil
+	case '*':
+		return new(big.Rat).Mul(x, y), nil
+	}
+	if y.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+
+	return new(big.Rat).Quo(x, y), nil
+}
+
+// exprParser parses an expression by recursive descent:
+//
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary }
+//	unary   = "-" unary | "(" sum ")" | number | duration | name
+type exprParser struct {
+	src   string
+	pos   int
+	names []string
+}
+
+// parseExprRef parses an expr: default, also returning the names of the
+// siblings it refers to, in order of appearance.
+func parseExprRef(value string) (exprNode, []string, error) {
+	p := &exprParser{src: value[len(exprPrefix):]}
+	node, err := p.sum()
+	if err == nil && p.skipSpaces() < len(p.src) {
+		err = fmt.Errorf("unexpected %q", p.src[p.pos:])
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid expression %q: %w", value, err)
+	}
+
+	return node, p.names, nil
+}
+
+// skipSpaces moves past the spaces at the position of p, returning the new
+// position.
+func (p *exprParser) skipSpaces() int {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+
+	return p.pos
+}
+
+func (p *exprParser) sum() (exprNode, error) {
+	x, err := p.product()
+	for err == nil && p.skipSpaces() < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+		op := p.src[p.pos]
+		p.pos++
+		var y exprNode
+		y, err = p.product()
+		x = exprBinary{op: op, x: x, y: y}
+	}
+
+	return x, err
+}
+
+func (p *exprParser) product() (exprNode, error) {
+	x, err := p.unary()
+	for err == nil && p.skipSpaces() < len(p.src) && (p.src[p.pos] == '*' || p.src[p.pos] == '/') {
+		op := p.src[p.pos]
+		p.pos++
+		var y exprNode
+		y, err = p.unary()
+		x = exprBinary{op: op, x: x, y: y}
+	}
+
+	return x, err
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if p.skipSpaces() == len(p.src) {
+		return nil, fmt.Errorf("unexpected end")
+	}
+
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case c == '-':
+		p.pos++
+		x, err := p.unary()
+		return exprNeg{x: x}, err
+	case c == '(':
+		p.pos++
+		x, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpaces() == len(p.src) || p.src[p.pos] != ')' {
+			return nil, fmt.Errorf("missing ) after %q", p.src[start:p.pos])
+		}
+		p.pos++
+		return x, nil
+	case c >= '0' && c <= '9' || c == '.':
+		// A number directly followed by letters is a duration, e.g. 1m30s.
+		for p.pos < len(p.src) && isExprWordByte(p.src[p.pos]) {
+			p.pos++
+		}
+		word := p.src[start:p.pos]
+		if n, ok := new(big.Rat).SetString(word); ok && !strings.ContainsAny(word, "/eE") {
+			return exprNumber{value: n}, nil
+		}
+		d, err := parseDurationValue(word)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", word)
+		}
+		return exprNumber{value: new(big.Rat).SetInt64(int64(d))}, nil
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		for p.pos < len(p.src) && isExprWordByte(p.src[p.pos]) && p.src[p.pos] != '.' {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		p.names = append(p.names, name)
+		return exprName{name: name}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %q", p.src[p.pos:])
+}
+
+// isExprWordByte reports whether c can be part of a number, a duration or a
+// name.
+func isExprWordByte(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// isExprType reports whether fields of type t can have an expr: default.
+func isExprType(t reflect.Type) bool {
+	t = derefType(t)
+	return t == durationType || isIntegerType(t) || isFloatType(t)
+}
+
+// checkExprRef validates the expr: default of a field of type t. Siblings
+// are only known at fill time, the syntax is checked.
+func checkExprRef(t reflect.Type, value string) error {
+	if !isExprType(t) {
+		return fmt.Errorf("%s: %s is not a number or a duration", value, t)
+	}
+	_, _, err := parseExprRef(value)
+
+	return err
+}
+
+// resolveExprRef evaluates the expr: default of field against its siblings,
+// returning the result formatted for the filler of the field.
+func resolveExprRef(field *FieldData) string {
+	t := derefType(field.Field.Type)
+	if !isExprType(t) {
+		field.fail(fmt.Errorf("%s: %s is not a number or a duration", field.TagValue, t))
+		return ""
+	}
+	node, names, err := parseExprRef(field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return ""
+	}
+	if !field.siblings.IsValid() {
+		field.fail(unknownPathf("%s: no such sibling field", field.TagValue))
+		return ""
+	}
+	if name, ok := derivedCycle(field, names); ok {
+		field.fail(fmt.Errorf("%s: %s refers back to %s", field.TagValue, name, field.Field.Name))
+		return ""
+	}
+
+	result, err := node.eval(func(name string) (*big.Rat, error) {
+		return exprSibling(field.siblings, name)
+	})
+	if err != nil {
+		field.fail(fmt.Errorf("%s: %w", field.TagValue, err))
+		return ""
+	}
+	value, err := formatExprResult(result, t)
+	if err != nil {
+		field.fail(err)
+		return ""
+	}
+
+	return value
+}
+
+// exprSibling returns the value of the sibling field name of an expression.
+func exprSibling(siblings reflect.Value, name string) (*big.Rat, error) {
+	sf, ok := siblings.Type().FieldByName(name)
+	if !ok || sf.PkgPath != "" || len(sf.Index) != 1 {
+		return nil, unknownPathf("%s: no such sibling field", name)
+	}
+	v := siblings.Field(sf.Index[0])
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("%s is nil", name)
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%s is %g", name, f)
+		}
+		return new(big.Rat).SetFloat64(f), nil
+	}
+
+	return nil, fmt.Errorf("%s of type %s is not a number", name, v.Type())
+}
+
+// formatExprResult formats the result of an expression for a field of type
+// t, failing with an ErrOverflow when it doesn't fit.
+func formatExprResult(result *big.Rat, t reflect.Type) (string, error) {
+	if isFloatType(t) {
+		f, _ := result.Float64()
+		if math.IsInf(f, 0) || t.Kind() == reflect.Float32 && math.Abs(f) > math.MaxFloat32 {
+			return "", overflowf("expression result %s overflows %s", result.FloatString(3), t)
+		}
+		return strconv.FormatFloat(f, 'g', -1, t.Bits()), nil
+	}
+
+	n := new(big.Int).Quo(result.Num(), result.Denom())
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n.Sign() < 0 || n.BitLen() > t.Bits() {
+			return "", overflowf("expression result %s overflows %s", n, t)
+		}
+		return n.String(), nil
+	}
+	if !n.IsInt64() || n.BitLen() > t.Bits()-1 && n.Cmp(new(big.Int).Lsh(big.NewInt(-1), uint(t.Bits()-1))) != 0 {
+		return "", overflowf("expression result %s overflows %s", n, t)
+	}
+	if t == durationType {
+		return time.Duration(n.Int64()).String(), nil
+	}
+
+	return n.String(), nil
+}
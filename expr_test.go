@@ -0,0 +1,91 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ExprSuite struct{}
+
+var _ = Suite(&ExprSuite{})
+
+type ExampleExpr struct {
+	MaxIdleConns int           `default:"expr:MaxOpenConns/2"`
+	MaxOpenConns int           `default:"env:GODEFAULT_TEST_UNSET:20"`
+	Chained      int           `default:"expr:MaxIdleConns + 1"`
+	RateLimit    float64       `default:"1.5"`
+	BurstLimit   float32       `default:"expr:RateLimit*2"`
+	Timeout      time.Duration `default:"10s"`
+	Deadline     time.Duration `default:"expr:Timeout*3+500ms"`
+	Shards       uint8         `default:"expr:(MaxOpenConns - 4) / 4"`
+	Negative     *int64        `default:"expr:-(Shards*2)"`
+	Truncated    int           `default:"expr:7/2*2"`
+	Ratio        float64       `default:"expr:Timeout/1m"`
+}
+
+func (s *ExprSuite) TestExpr(c *C) {
+	foo := &ExampleExpr{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.MaxIdleConns, Equals, 10)
+	c.Assert(foo.Chained, Equals, 11)
+	c.Assert(foo.BurstLimit, Equals, float32(3))
+	c.Assert(foo.Deadline, Equals, 30*time.Second+500*time.Millisecond)
+	c.Assert(foo.Shards, Equals, uint8(4))
+	c.Assert(*foo.Negative, Equals, int64(-8))
+	c.Assert(foo.Truncated, Equals, 7)
+	c.Assert(foo.Ratio, Equals, 1.0/6)
+
+	// Values already set are used, and kept.
+	foo = &ExampleExpr{MaxOpenConns: 8, MaxIdleConns: 1}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+	c.Assert(foo.MaxIdleConns, Equals, 1)
+	c.Assert(foo.Chained, Equals, 2)
+	c.Assert(foo.Shards, Equals, uint8(1))
+
+	plan, err := Compile(reflect.TypeOf(ExampleExpr{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleExpr{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Chained, Equals, 11)
+
+	c.Assert(TagPhase("expr:A/2"), Equals, PhaseDerived)
+	c.Assert(TagFeatures(reflect.TypeOf(0), "expr:A/2"), DeepEquals, []Feature{FeatureReferences})
+	c.Assert(CheckDefaults(&ExampleExpr{}), HasLen, 0)
+}
+
+func (s *ExprSuite) TestExprErrors(c *C) {
+	foo := &struct {
+		First    int    `default:"expr:Second+1"`
+		Second   int    `default:"expr:Third*2"`
+		Third    int    `default:"expr:First"`
+		Zero     int    `default:"0"`
+		Divided  int    `default:"expr:10/Zero"`
+		Name     string `default:"foo"`
+		Mismatch int    `default:"expr:Name*2"`
+		Missing  int    `default:"expr:Unknown+1"`
+		Small    int8   `default:"expr:100*2"`
+		Unsigned uint   `default:"expr:Zero-1"`
+		Syntax   int    `default:"expr:(1+"`
+		String   string `default:"expr:1"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Third: expr:First: First refers back to Third\n`+
+		`Second: expr:Third\*2: Third refers back to Second\n`+
+		`First: expr:Second\+1: Second refers back to First\n`+
+		`Divided: expr:10/Zero: division by zero\n`+
+		`Mismatch: expr:Name\*2: Name of type string is not a number\n`+
+		`Missing: expr:Unknown\+1: Unknown: no such sibling field\n`+
+		`Small: expression result 200 overflows int8\n`+
+		`Unsigned: expression result -1 overflows uint\n`+
+		`Syntax: invalid expression "expr:\(1\+": unexpected end\n`+
+		`String: expr:1: string is not a number or a duration`)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+	c.Assert(foo.Small, Equals, int8(0))
+
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+}
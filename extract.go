@@ -0,0 +1,83 @@
+package godefault
+
+// ExtractDefaults returns the defaults of the struct behind v as nested maps
+// keyed like a config file, ready to be marshaled to YAML, TOML or JSON. Keys
+// are named by the first of the yaml, toml and json tags present on a field
+// (see WithNameTags), falling back to the field name, and fields excluded
+// with "-" are left out, as are the fields of struct slice elements and
+// struct map values, which have no instance to describe. Values are typed when they parse
+// statically, and secret defaults are "****".
+//
+//	type Config struct {
+//	    Server struct {
+//	        BindAddr string `yaml:"bind_addr" default:"0.0.0.0"`
+//	    } `yaml:"server"`
+//	}
+//
+//	ExtractDefaults(&Config{}) // map[server:map[bind_addr:0.0.0.0]]
+func ExtractDefaults(v interface{}, opts ...Option) (map[string]interface{}, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	filler := NewFiller(opts...)
+	defaults := make(map[string]interface{})
+	filler.walkType(t, func(tf *FieldInfo) {
+		if !tf.HasTag || tf.Tag == "" || tf.Excluded || tf.InElement {
+			return
+		}
+
+		m := defaults
+		for _, name := range tf.Names[:len(tf.Names)-1] {
+			inner, ok := m[name].(map[string]interface{})
+			if !ok {
+				inner = make(map[string]interface{})
+				m[name] = inner
+			}
+			m = inner
+		}
+
+		value := schemaDefault(tf.Field.Type, tf.Tag, separatorOf(tf.Field))
+		if isSecret(tf.Field) {
+			value = secretValue
+		}
+		m[tf.Names[len(tf.Names)-1]] = value
+	})
+
+	return defaults, nil
+}
+
+// MarshalDefaults encodes the result of ExtractDefaults with marshal, e.g.
+// yaml.Marshal or json.Marshal, which keeps the package free of encoders.
+func MarshalDefaults(v interface{}, marshal func(interface{}) ([]byte, error), opts ...Option) ([]byte, error) {
+	defaults, err := ExtractDefaults(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(defaults)
+}
+
+// ExtractEnv returns the defaults of the struct behind v keyed by their
+// envconfig-style variable names: prefix and the keys of ExtractDefaults,
+// upper-cased and joined with "_". The yaml key server.bind_addr is
+// MYAPP_SERVER_BIND_ADDR for the prefix "myapp". Values are the raw tags,
+// secret ones being "****".
+func ExtractEnv(v interface{}, prefix string, opts ...Option) (map[string]string, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	filler := NewFiller(opts...)
+	env := make(map[string]string)
+	filler.walkType(t, func(tf *FieldInfo) {
+		if !tf.HasTag || tf.Tag == "" || tf.Excluded || tf.InElement {
+			return
+		}
+		env[envName(prefix, tf.Names)] = redact(tf.Field, tf.Tag)
+	})
+
+	return env, nil
+}
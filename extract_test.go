@@ -0,0 +1,112 @@
+package godefault
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type ExtractSuite struct{}
+
+var _ = Suite(&ExtractSuite{})
+
+type ExampleExternal struct {
+	Server struct {
+		BindAddr string `yaml:"bind_addr" json:"bindAddr" default:"0.0.0.0"`
+		Port     int    `toml:"port_number" json:"port,omitempty" default:"8080"`
+		Debug    bool   `json:"-" yaml:"debug" default:"true"`
+	} `yaml:"server" json:"srv"`
+	Internal string `yaml:"-" json:"internal" default:"hidden"`
+	Dash     string `json:"-," default:"dash"`
+	Token    string `yaml:"token" default:"s3cr3t" secret:"true"`
+	Untagged int    `default:"1"`
+	Empty    string `default:""`
+	Peers    []Child
+	ExampleEmbedded
+}
+
+type ExampleEmbedded struct {
+	Region string `yaml:"region,omitempty" default:"eu"`
+}
+
+func (s *ExtractSuite) TestExtractDefaults(c *C) {
+	defaults, err := ExtractDefaults(&ExampleExternal{})
+	c.Assert(err, IsNil)
+	c.Assert(defaults, DeepEquals, map[string]interface{}{
+		"server": map[string]interface{}{
+			"bind_addr":   "0.0.0.0",
+			"port_number": int64(8080),
+			"debug":       true,
+		},
+		"-":        "dash",
+		"token":    secretValue,
+		"Untagged": int64(1),
+		"region":   "eu",
+	})
+}
+
+func (s *ExtractSuite) TestExtractDefaultsNameTags(c *C) {
+	defaults, err := ExtractDefaults(&ExampleExternal{}, WithNameTags("json"))
+	c.Assert(err, IsNil)
+	c.Assert(defaults, DeepEquals, map[string]interface{}{
+		"srv": map[string]interface{}{
+			"bindAddr": "0.0.0.0",
+			"port":     int64(8080),
+		},
+		"internal": "hidden",
+		"-":        "dash",
+		"Token":    secretValue,
+		"Untagged": int64(1),
+		"Region":   "eu",
+	})
+}
+
+func (s *ExtractSuite) TestMarshalDefaults(c *C) {
+	data, err := MarshalDefaults(&ExampleEmbedded{}, json.Marshal)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `{"region":"eu"}`)
+
+	_, err = MarshalDefaults("foo", json.Marshal)
+	c.Assert(err, NotNil)
+}
+
+func (s *ExtractSuite) TestExtractEnv(c *C) {
+	env, err := ExtractEnv(&ExampleExternal{}, "myapp")
+	c.Assert(err, IsNil)
+	c.Assert(env, DeepEquals, map[string]string{
+		"MYAPP_SERVER_BIND_ADDR":   "0.0.0.0",
+		"MYAPP_SERVER_PORT_NUMBER": "8080",
+		"MYAPP_SERVER_DEBUG":       "true",
+		"MYAPP__":                  "dash",
+		"MYAPP_TOKEN":              secretValue,
+		"MYAPP_UNTAGGED":           "1",
+		"MYAPP_REGION":             "eu",
+	})
+}
+
+func (s *ExtractSuite) TestGenerateDocExternalNames(c *C) {
+	doc, err := GenerateDoc(&ExampleExternal{})
+	c.Assert(err, IsNil)
+	c.Assert(doc, Equals, "| Field | Type | Default |\n"+
+		"| --- | --- | --- |\n"+
+		"| server.bind_addr | string | `0.0.0.0` |\n"+
+		"| server.port_number | int | `8080` |\n"+
+		"| server.debug | bool | `true` |\n"+
+		"| - | string | `dash` |\n"+
+		"| token | string | `****` |\n"+
+		"| Untagged | int | `1` |\n"+
+		"| Empty | string | `` |\n"+
+		"| Peers[].Age | int | `10` |\n"+
+		"| region | string | `eu` |\n")
+
+	data, err := GenerateJSONSchema(&ExampleExternal{})
+	c.Assert(err, IsNil)
+
+	var schema map[string]interface{}
+	c.Assert(json.Unmarshal(data, &schema), IsNil)
+	properties := schema["properties"].(map[string]interface{})
+	c.Assert(properties["server"], NotNil)
+	c.Assert(properties["region"], NotNil)
+	c.Assert(properties["Internal"], IsNil)
+	c.Assert(properties["ExampleEmbedded"], IsNil)
+}
@@ -0,0 +1,201 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Feature is a mechanism a default tag can use to read its value from
+// outside the struct declaring it, or from another of its fields, see
+// Restrict. Literals, durations, json: values, data URIs and the like use
+// none; choose: defaults use those of their branches and of their seed.
+type Feature string
+
+const (
+	// FeatureEnv are the env:, envindirect:, jwtclaim: and printf:
+	// references.
+	FeatureEnv Feature = "env"
+	// FeatureEnvs are the envs| mappings.
+	FeatureEnvs Feature = "envs"
+	// FeaturePlaceholders are the date placeholders, e.g. {{date:0,0,-1}},
+	// and the startup time of time.Time fields.
+	FeaturePlaceholders Feature = "placeholders"
+	// FeatureVar are the var: and buildvar: references.
+	FeatureVar Feature = "var"
+	// FeatureKV are the kv: references.
+	FeatureKV Feature = "kv"
+	// FeatureHost are the host: and hosts| mappings, and the choose:
+	// defaults seeded from the hostname.
+	FeatureHost Feature = "host"
+	// FeatureCPU are the processor count expressions of integer fields.
+	FeatureCPU Feature = "cpu"
+	// FeatureFreePort is the freeport keyword of integer fields, which
+	// listens on the network.
+	FeatureFreePort Feature = "freeport"
+	// FeatureFile are the file: defaults of []byte fields.
+	FeatureFile Feature = "file"
+	// FeatureGenerators are the gen: defaults, produced anew by their
+	// registered generator.
+	FeatureGenerators Feature = "gen"
+	// FeatureRandom are the choose: defaults without a seedfrom tag, drawn
+	// at random.
+	FeatureRandom Feature = "random"
+	// FeatureReferences are the references to other fields: ref:, len:,
+	// expr:, lookup: and ratio:.
+	FeatureReferences Feature = "references"
+)
+
+// Restrict allows the default tags filled or checked by f to use features
+// only, failing the fields whose tag uses another one, the value of the
+// field left alone. A Filler allows every feature unless restricted; calling
+// Restrict without features allows none. It is meant to be called once,
+// before f is used:
+//
+//	filler := godefault.NewFiller()
+//	filler.Restrict(godefault.FeatureEnv)
+//	err := filler.FillContext(ctx, &cfg) // fails on kv: references
+//
+// The features of a tag include those of its fallbacks, whether they are
+// used or not, e.g. var:Version||env:VERSION uses FeatureVar and FeatureEnv.
+// The errors are ErrPolicy ones, see also (*Filler).CheckDefaults. Restrict
+// panics on the frozen default filler, see FreezeDefaultFiller.
+func (f *Filler) Restrict(features ...Feature) {
+	if f.frozen != nil {
+		panic("godefault: Restrict on the frozen default filler, use a Filler of your own")
+	}
+	f.allowed = make(map[Feature]bool, len(features))
+	for _, feature := range features {
+		f.allowed[feature] = true
+	}
+}
+
+// TagFeatures returns the features the default tag value of a field of type
+// t uses, in the order they are resolved, e.g. to document them. The json:
+// default of a struct uses those of the defaults it gives to its fields. A
+// choose: default, whose seedfrom tag isn't known here, is drawn at random;
+// FieldInfo.Features tell those of a field, its seed included.
+func TagFeatures(t reflect.Type, value string) []Feature {
+	return tagFeatures(t, value, "")
+}
+
+// fieldFeatures returns the features the default tag value of sf uses, those
+// of the seed of a choose: default included.
+func fieldFeatures(sf reflect.StructField, value string) []Feature {
+	return tagFeatures(sf.Type, value, sf.Tag.Get(seedFromTag))
+}
+
+// tagFeatures returns the features the default tag value of a field of type
+// t uses, the picks of its choose: defaults seeded by seed, see seedFromTag.
+func tagFeatures(t reflect.Type, value string, seed string) []Feature {
+	var features []Feature
+	add := func(feature Feature) {
+		for _, f := range features {
+			if f == feature {
+				return
+			}
+		}
+		features = append(features, feature)
+	}
+
+	if isStructModeType(t) && isInheritedDefault(value) {
+		tags, err := inheritedDefaults(t, value)
+		if err != nil {
+			return nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if tag, ok := tags[t.Field(i).Name]; ok {
+				for _, feature := range fieldFeatures(t.Field(i), tag) {
+					add(feature)
+				}
+			}
+		}
+		return features
+	}
+
+	// Follow the fallbacks, in the order of resolveTagValue.
+	for value != "" {
+		next := ""
+		switch {
+		case isLenRef(value) || isSiblingRef(value) || isExprRef(value) || isDeferredTag(value):
+			add(FeatureReferences)
+		case isChooseRef(value):
+			switch seed {
+			case "":
+				add(FeatureRandom)
+			case hostnameSeed:
+				add(FeatureHost)
+			}
+			branches, _, _ := parseChooseRef(value)
+			for _, branch := range branches {
+				for _, feature := range tagFeatures(t, branch.value, seed) {
+					add(feature)
+				}
+			}
+		case isVarRef(value):
+			add(FeatureVar)
+			_, _, next, _ = parseVarRef(value)
+		case strings.HasPrefix(value, envRefPrefix) || strings.HasPrefix(value, envIndirectPrefix):
+			add(FeatureEnv)
+			next = resolveEnvRef(value, t, nil)
+		case isJWTClaimRef(value) || isPrintfRef(value):
+			add(FeatureEnv)
+		case isKVRef(value):
+			add(FeatureKV)
+			_, _, next, _ = parseKVRef(value)
+		case isHostRef(value):
+			add(FeatureHost)
+		case isGenRef(value):
+			add(FeatureGenerators)
+		case strings.HasPrefix(value, "envs|"):
+			add(FeatureEnvs)
+		case isIntegerType(t) && isCPUExpr(value):
+			add(FeatureCPU)
+		case isIntegerType(t) && isFreePort(value):
+			add(FeatureFreePort)
+		case isBytesType(t) && strings.HasPrefix(value, filePrefix):
+			add(FeatureFile)
+		}
+		if strings.Contains(value, "{{") && placeholderPattern.MatchString(value) || t == timeType && (value == startupKeyword || isDayKeyword(value)) {
+			add(FeaturePlaceholders)
+		}
+		if next == value {
+			break
+		}
+		value = next
+	}
+
+	return features
+}
+
+// checkFeatures returns an ErrPolicy when the default tag value of sf uses a
+// feature f doesn't allow, see Restrict.
+func (f *Filler) checkFeatures(sf reflect.StructField, value string) error {
+	if f.allowed == nil {
+		return nil
+	}
+	for _, feature := range fieldFeatures(sf, value) {
+		if !f.allowed[feature] {
+			return fmt.Errorf("%w: %s", ErrPolicy, feature)
+		}
+	}
+
+	return nil
+}
+
+// restricts fails field and reports true when its tag uses a feature its
+// filler doesn't allow. The json: default of a struct is left to its fields,
+// which are checked as they are filled.
+func restricts(field *FieldData) bool {
+	t := field.Field.Type
+	if field.TagValue == "" || isStructModeType(t) && isInheritedDefault(field.TagValue) {
+		return false
+	}
+	if err := field.owner().checkFeatures(field.Field, field.TagValue); err != nil {
+		field.fail(err)
+		field.source = SourceRestricted
+		return true
+	}
+
+	return false
+}
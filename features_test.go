@@ -0,0 +1,110 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type FeaturesSuite struct{}
+
+var _ = Suite(&FeaturesSuite{})
+
+type ExampleRestrictDatabase struct {
+	Host string `default:"localhost"`
+	Port int    `default:"5432"`
+}
+
+type ExampleRestrict struct {
+	Name     string                  `default:"app"`
+	Port     int                     `default:"env:GODEFAULT_TEST_RESTRICT_PORT:8080"`
+	Zone     string                  `default:"kv:config/zone:eu"`
+	Version  string                  `default:"var:main.Version||kv:config/version"`
+	Started  string                  `default:"{{date:0,0,0}}"`
+	Workers  int                     `default:"numcpu"`
+	Database ExampleRestrictDatabase `default:"json:{\"host\":\"kv:config/db\"}"`
+}
+
+func (s *FeaturesSuite) TestRestrict(c *C) {
+	filler := NewFiller(WithKeyValueSource("", MapSource{"config/zone": "us", "config/db": "db.internal"}))
+	filler.Restrict(FeatureEnv, FeatureVar, FeatureCPU)
+
+	foo := &ExampleRestrict{}
+	err := filler.FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Zone: default uses a restricted feature: kv\n`+
+		`Version: default uses a restricted feature: kv\n`+
+		`Started: default uses a restricted feature: placeholders\n`+
+		`Database.Host: default uses a restricted feature: kv`)
+	c.Assert(errors.Is(err, ErrPolicy), Equals, true)
+	c.Assert(errors.Is(err, ErrParse), Equals, false)
+
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.Port, Equals, 8080)
+	c.Assert(foo.Zone, Equals, "")
+	c.Assert(foo.Version, Equals, "")
+	c.Assert(foo.Started, Equals, "")
+	c.Assert(foo.Workers > 0, Equals, true)
+	c.Assert(foo.Database, DeepEquals, ExampleRestrictDatabase{Port: 5432})
+
+	errs := filler.CheckDefaults(&ExampleRestrict{})
+	c.Assert(errs, HasLen, 4)
+	c.Assert(errs[3], ErrorMatches, `Database: default uses a restricted feature: kv`)
+}
+
+func (s *FeaturesSuite) TestRestrictNone(c *C) {
+	filler := NewFiller()
+	c.Assert(filler.CheckDefaults(&ExampleRestrict{}), HasLen, 0)
+
+	filler.Restrict()
+	c.Assert(filler.CheckDefaults(&ExampleRestrict{}), HasLen, 6)
+	c.Assert(CheckDefaults(&ExampleRestrict{}), HasLen, 0)
+}
+
+func (s *FeaturesSuite) TestTagFeatures(c *C) {
+	stringType := reflect.TypeOf("")
+	for value, expected := range map[string][]Feature{
+		"":                                 nil,
+		"literal":                          nil,
+		"json:{\"a\":1}":                   nil,
+		"env:PORT:8080":                    {FeatureEnv},
+		"envindirect:NAME":                 {FeatureEnv},
+		"jwtclaim:TOKEN:sub":               {FeatureEnv},
+		"env:ZONE:kv:config/zone":          {FeatureEnv, FeatureKV},
+		"var:main.Version||env:VERSION":    {FeatureVar, FeatureEnv},
+		"buildvar:Version||{{date:0,0,0}}": {FeatureVar, FeaturePlaceholders},
+		"kv:config/zone:env:ZONE":          {FeatureKV, FeatureEnv},
+		"envs|prod,a|dev,b":                {FeatureEnvs},
+		"host:web-*=a,*=b":                 {FeatureHost},
+		"hosts|*,a":                        {FeatureHost},
+		"{{date:0,0,0}}":                   {FeaturePlaceholders},
+		"ref:Base":                         {FeatureReferences},
+		"lookup:Server.Host":               {FeatureReferences},
+		"gen:uuid":                         {FeatureGenerators},
+		"choose:a=1,env:B=1":               {FeatureRandom, FeatureEnv},
+		"numcpu":                           nil,
+	} {
+		c.Assert(TagFeatures(stringType, value), DeepEquals, expected, Commentf("%s", value))
+	}
+
+	c.Assert(TagFeatures(reflect.TypeOf(0), "numcpu*2|+1"), DeepEquals, []Feature{FeatureCPU})
+	c.Assert(TagFeatures(reflect.TypeOf(0), "len:Items"), DeepEquals, []Feature{FeatureReferences})
+	c.Assert(TagFeatures(reflect.TypeOf(time.Duration(0)), "ratio:Timeout*2"), DeepEquals, []Feature{FeatureReferences})
+	c.Assert(TagFeatures(reflect.TypeOf(ExampleRestrictDatabase{}), `json:{"host":"env:HOST","port":"kv:port"}`), DeepEquals, []Feature{FeatureEnv, FeatureKV})
+
+	fields, err := ListDefaultFields(&ExampleRestrict{})
+	c.Assert(err, IsNil)
+	c.Assert(fields[1].Features, DeepEquals, []Feature{FeatureEnv})
+	c.Assert(fields[0].Features, IsNil)
+
+	fields, err = ListDefaultFields(&struct {
+		ID     string `default:"server-1"`
+		Engine string `default:"choose:v1=90,v2=10" seedfrom:"ID"`
+		Cache  string `default:"choose:lru=50,arc=50" seedfrom:"hostname"`
+	}{})
+	c.Assert(err, IsNil)
+	c.Assert(fields[1].Features, IsNil)
+	c.Assert(fields[2].Features, DeepEquals, []Feature{FeatureHost})
+}
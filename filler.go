@@ -1,8 +1,12 @@
 package godefault
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 type FieldData struct {
@@ -10,6 +14,210 @@ type FieldData struct {
 	Value    reflect.Value
 	TagValue string
 	Parent   *FieldData
+
+	filler   *Filler
+	state    *fillState
+	resolved bool
+	// notTag is set when TagValue doesn't come from a tag, but is a
+	// container element or was given to Assign.
+	notTag bool
+	// literal is set when TagValue was given to Assign, which the fillers
+	// parse as it is, without the references and placeholders of the tags.
+	literal bool
+	// names are the external names of the field and its parents, set by
+	// Apply for the fields that layers can address by key.
+	names []string
+	// siblings is the struct holding the field, see lenRefPrefix.
+	siblings reflect.Value
+	// source is set by resolveTagValue when the tag resolved to a value read
+	// from the environment or computed from a placeholder.
+	source string
+	// sourceName is the variable or key the source read, see recordMeta.
+	sourceName string
+	// emptyEnv is the interpretation of the last empty environment variable
+	// read for the field, see FieldReport.EmptyEnv.
+	emptyEnv string
+	// choice is the branch picked by a choose: default, see
+	// FieldReport.Choice.
+	choice string
+	// elem is the index of a slice element, or the key of a map value, in
+	// brackets, e.g. "[2]".
+	elem string
+	path string
+}
+
+// fillState is shared by every field visited during a single Fill call.
+type fillState struct {
+	ctx  context.Context
+	done <-chan struct{}
+	err  error
+	errs []error
+
+	// recover turns the panics of the fillers into errors, see WithRecover.
+	recover bool
+	// assigned holds the paths of the fields set before the fill, e.g. by
+	// ApplyArgs, which keep their value even when it is zero.
+	assigned map[string]bool
+	// root is the struct being filled, lookups the fields whose lookup:
+	// reference is resolved against it once the fill is done.
+	root    reflect.Value
+	lookups []*FieldData
+	// meta is the meta field of root, looked up once, see metaTag.
+	meta      reflect.Value
+	metaFound bool
+	// scratch holds the values shared by the fillers, created by the first
+	// one using it, see Scratch.
+	scratch *Scratch
+}
+
+// fail records that field couldn't be filled. The errors are returned by
+// FillContext and ignored by Fill.
+func (field *FieldData) fail(err error) {
+	if field.state == nil {
+		field.state = &fillState{}
+	}
+	field.state.errs = append(field.state.errs, &FieldError{Path: field.Path(), Tag: field.rawTag(), Err: err})
+}
+
+// errCount returns the number of errors recorded by the fill so far.
+func (field *FieldData) errCount() int {
+	if field.state == nil {
+		return 0
+	}
+
+	return len(field.state.errs)
+}
+
+// check fails the field when parsing its tag value returned err, as an
+// ErrParse, or an ErrOverflow for values out of range. Empty tags are never
+// an error, they leave the zero value.
+func (field *FieldData) check(err error) {
+	if err != nil && field.TagValue != "" {
+		field.fail(parseErrorOf(err))
+	}
+}
+
+// aborted reports whether the fill has to stop, which happens once its
+// context is done; the error is recorded against field the first time.
+// Contexts that can't be cancelled are never checked.
+func (s *fillState) aborted(field *FieldData) bool {
+	if s == nil {
+		return false
+	}
+	if s.err != nil {
+		return true
+	}
+	if s.done == nil {
+		return false
+	}
+
+	select {
+	case <-s.done:
+		s.err = &FieldError{Path: field.Path(), Tag: field.rawTag(), Err: s.ctx.Err()}
+		return true
+	default:
+		return false
+	}
+}
+
+// isAssigned reports whether field was set before the fill.
+func (s *fillState) isAssigned(field *FieldData) bool {
+	return s != nil && len(s.assigned) != 0 && s.assigned[field.Path()]
+}
+
+// Context returns the context of the fill the field belongs to, see
+// SetDefaultsContext. Fillers doing I/O should give up when it is done.
+func (field *FieldData) Context() context.Context {
+	if field.state != nil && field.state.ctx != nil {
+		return field.state.ctx
+	}
+
+	return context.Background()
+}
+
+// Path returns the path of the field from the root of the fill, the names of
+// the fields dotted and the elements of slices and maps indexed, e.g.
+// "Servers[2].Addr" or "Databases[primary].PoolSize". It is computed once.
+func (field *FieldData) Path() string {
+	if field.path != "" {
+		return field.path
+	}
+
+	var parts []string
+	for f := field; f != nil; f = f.Parent {
+		switch {
+		case f.elem != "":
+			parts = append(parts, f.elem)
+		case f.Field.Name != "":
+			parts = append(parts, f.Field.Name)
+		}
+	}
+
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		if b.Len() != 0 && parts[i][0] != '[' {
+			b.WriteByte('.')
+		}
+		b.WriteString(parts[i])
+	}
+	field.path = b.String()
+
+	return field.path
+}
+
+// rawTag returns the default tag declared for field, that of the field
+// holding it for elements, redacted for secret fields, see FieldError.Tag.
+func (field *FieldData) rawTag() string {
+	f := field
+	for f.elem != "" && f.Parent != nil {
+		f = f.Parent
+	}
+
+	return redact(f.Field, f.Field.Tag.Get(field.owner().Tag))
+}
+
+// element returns the FieldData of the element of the slice or map field at
+// key, holding value, for the fields of struct elements or for an element
+// filled from a raw value.
+func (field *FieldData) element(value reflect.Value, key string) *FieldData {
+	return &FieldData{
+		Value:   value,
+		Field:   reflect.StructField{Type: value.Type()},
+		Parent:  field,
+		filler:  field.filler,
+		state:   field.state,
+		notTag:  true,
+		literal: field.literal,
+		elem:    "[" + key + "]",
+	}
+}
+
+// visit records that the pointer p is being filled and reports whether it
+// was already visited, which means the data is cyclic.
+func (field *FieldData) visit(p uintptr) bool {
+	visited := field.Scratch().Memoize(visitedKey{}, func() interface{} {
+		return make(map[uintptr]bool)
+	}).(map[uintptr]bool)
+	if visited[p] {
+		return true
+	}
+	visited[p] = true
+
+	return false
+}
+
+// visitedKey is the key of the pointers being filled in the Scratch, see
+// visit.
+type visitedKey struct{}
+
+// owner returns the Filler the field is being filled by, so that nested
+// values are filled with the same configuration.
+func (field *FieldData) owner() *Filler {
+	if field.filler != nil {
+		return field.filler
+	}
+
+	return sharedFiller()
 }
 
 type FillerFunc func(field *FieldData)
@@ -21,6 +229,53 @@ type Filler struct {
 	FuncByType map[TypeHash]FillerFunc
 	FuncByKind map[reflect.Kind]FillerFunc
 	Tag        string
+
+	protoCompat   bool
+	strict        bool
+	nameTags      []string
+	postValidate  func(path string, value reflect.Value, field reflect.StructField) error
+	report        *FillReport
+	logger        func(e FillEvent)
+	unexported    bool
+	recover       bool
+	panicStack    bool
+	preprocess    func(fieldPath, rawTag string) string
+	filter        func(fd *FieldData) bool
+	jsonNumber    bool
+	regexps       *sync.Map
+	emptyEnvIsSet bool
+	envRequired   bool
+	portLookup    bool
+	rand          *lockedRand
+	honorJSONDash bool
+	kinds         map[reflect.Kind]bool
+	makeChannels  bool
+	maxSliceFill  int
+	compat        Compat
+	weakTyping    bool
+	lossyCoercion bool
+	marker        string
+	kvSources     map[string]KeyValueSource
+	scratch       []scratchValue
+	// frozen holds the fillers of the shared filler once frozen, see
+	// FreezeDefaultFiller.
+	frozen *frozenFuncs
+	// allowed holds the features set by Restrict, nil allowing them all.
+	allowed map[Feature]bool
+	// opaque holds the types registered with RegisterOpaque.
+	opaque map[reflect.Type]bool
+	// replay holds the fields of the Trace replayed, by path, see
+	// ReplayTrace.
+	replay map[string]TraceField
+	// weakDurationUnit is the unit of WithWeakDurationUnit, 0 for seconds.
+	weakDurationUnit time.Duration
+	// bareDurationUnit is the unit of WithBareDurationUnit, 0 for none.
+	bareDurationUnit time.Duration
+	// plans holds the Plans compiled by f, by struct type, see FillElement.
+	plans sync.Map
+	// builtins holds the fillers installed by newDefaultFiller, which a Plan
+	// knows to depend on nothing but the tag value.
+	builtins map[uintptr]bool
 }
 
 // Fill apply all the functions contained on Filler, setting all the possible
@@ -28,6 +283,9 @@ type Filler struct {
 func (f *Filler) Fill(variable interface{}) {
 	fields := f.getFields(variable)
 	f.SetDefaultValues(fields)
+	if len(fields) != 0 {
+		f.resolveLookups(fields[0].state)
+	}
 }
 
 func (f *Filler) getFields(variable interface{}) []*FieldData {
@@ -37,20 +295,46 @@ func (f *Filler) getFields(variable interface{}) []*FieldData {
 }
 
 func (f *Filler) GetFieldsFromValue(valueObject reflect.Value, parent *FieldData) []*FieldData {
+	state := &fillState{recover: f.recover, root: valueObject}
+	if parent != nil && parent.state != nil {
+		state = parent.state
+	}
+
+	return f.getFieldsFromValue(valueObject, parent, state)
+}
+
+func (f *Filler) getFieldsFromValue(valueObject reflect.Value, parent *FieldData, state *fillState) []*FieldData {
 	typeObject := valueObject.Type()
 
+	proto := f.protoCompat || isProtoMessage(valueObject)
 	count := valueObject.NumField()
 	var results []*FieldData
 	for i := 0; i < count; i++ {
 		value := valueObject.Field(i)
 		field := typeObject.Field(i)
 
-		if value.CanSet() {
+		if proto {
+			if strings.HasPrefix(field.Name, "XXX_") {
+				continue
+			}
+			if _, ok := field.Tag.Lookup("protobuf_oneof"); ok {
+				results = append(results, f.getOneofFields(value, parent, state)...)
+				continue
+			}
+		}
+
+		if isMetaField(field) {
+			continue
+		}
+		if value, ok := f.settable(value); ok {
 			results = append(results, &FieldData{
 				Value:    value,
 				Field:    field,
 				TagValue: field.Tag.Get(f.Tag),
 				Parent:   parent,
+				filler:   f,
+				state:    state,
+				siblings: valueObject,
 			})
 		}
 	}
@@ -58,56 +342,144 @@ func (f *Filler) GetFieldsFromValue(valueObject reflect.Value, parent *FieldData
 	return results
 }
 
+// settable returns value, a struct field, when it can be set, which for an
+// unexported field requires WithUnsafeUnexported.
+func (f *Filler) settable(value reflect.Value) (reflect.Value, bool) {
+	if value.CanSet() {
+		return value, true
+	}
+	if f.unexported {
+		return unexportedValue(value)
+	}
+
+	return value, false
+}
+
 func (f *Filler) SetDefaultValues(fields []*FieldData) {
-	for _, field := range fields {
-		if field.TagValue == "-" { //ignore
+	for _, field := range derivedLast(fields) {
+		if field.state.aborted(field) {
+			return
+		}
+		if field.TagValue == "-" || !f.keeps(field) { //ignore
 			continue
 		}
-		if f.isEmpty(field) {
+		if skip := f.skipSource(field.Field); skip != "" {
+			f.skipped(field, skip)
+			continue
+		}
+		source := SourcePreset
+		// Structs given a default by their type still get the defaults of
+		// their fields.
+		if f.isEmpty(field) && !field.state.isAssigned(field) && f.setMethodDefault(field) && !f.isDescended(field.Field.Type) {
+			source = SourceMethod
+		} else if f.isEmpty(field) && !field.state.isAssigned(field) {
+			errs := len(field.state.errs)
 			f.SetDefaultValue(field)
+			if field.state.aborted(field) {
+				return
+			}
+			source = tagSource(field, len(field.state.errs) != errs)
 		}
+		f.visited(field, source)
 	}
 }
 
+// keeps reports whether field passes the filter set by WithFieldFilter and
+// carries the marker of WithRequireMarker.
+func (f *Filler) keeps(field *FieldData) bool {
+	return f.marks(field.Field) && (f.filter == nil || f.filter(field))
+}
+
 func (f *Filler) isEmpty(field *FieldData) bool {
-	switch field.Value.Kind() {
+	return isEmptyValue(field.Value)
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
 	case reflect.Bool:
-		return !field.Value.Bool()
+		return !value.Bool()
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return field.Value.Int() == 0
+		return value.Int() == 0
 	case reflect.Float32, reflect.Float64:
-		return field.Value.Float() == .0
+		return value.Float() == .0
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return field.Value.Uint() == 0
+		return value.Uint() == 0
 	case reflect.Slice:
-		switch field.Value.Type().Elem().Kind() {
+		switch value.Type().Elem().Kind() {
 		case reflect.Struct:
 			// always assume the structs in the slice is empty and can be filled
 			// the actually struct filling logic should take care of the rest
 			return true
 		default:
-			return field.Value.Len() == 0
+			return value.Len() == 0
 		}
 	case reflect.String:
-		return field.Value.String() == ""
+		return value.String() == ""
+	case reflect.Map:
+		return value.Len() == 0
+	case reflect.Func, reflect.Chan:
+		return value.IsNil()
 	}
 	return true
 }
 
 func (f *Filler) SetDefaultValue(field *FieldData) {
+	if field.recovers() {
+		defer field.recoverPanic()
+	}
+	if f.replay != nil && f.replays(field) {
+		return
+	}
+	if field.literal {
+		// Zero sentinels let the layers clear a field, see zeroSentinel.
+		if isZeroSentinel(field.Value.Type(), field.TagValue) {
+			setZero(field)
+			return
+		}
+		if filler := f.getFunction(field); filler != nil {
+			filler(field)
+		}
+		return
+	}
+	resolveTagValue(field)
+	if isZeroSentinel(field.Value.Type(), field.TagValue) {
+		setZero(field)
+		return
+	}
+	if deferLookup(field) {
+		return
+	}
+	if isLookupRef(field.TagValue) || isRatioRef(field.TagValue) {
+		// Not part of a fill, there is no root to resolve against.
+		field.fail(fmt.Errorf("%s: no fill to resolve against", field.TagValue))
+		return
+	}
+	if isSiblingRef(field.TagValue) {
+		resolveSiblingRef(field)
+		return
+	}
+	if filler := f.getFunction(field); filler != nil {
+		filler(field)
+	}
+}
+
+func (f *Filler) getFunction(field *FieldData) FillerFunc {
 	getters := []func(field *FieldData) FillerFunc{
 		f.getFunctionByName,
+		f.getFunctionByDecoder,
 		f.getFunctionByType,
+		f.getFunctionByOpaque,
 		f.getFunctionByKind,
 	}
 
 	for _, getter := range getters {
 		filler := getter(field)
 		if filler != nil {
-			filler(field)
-			return
+			return filler
 		}
 	}
+
+	return nil
 }
 
 func (f *Filler) getFunctionByName(field *FieldData) FillerFunc {
@@ -119,6 +491,22 @@ func (f *Filler) getFunctionByName(field *FieldData) FillerFunc {
 }
 
 func (f *Filler) getFunctionByType(field *FieldData) FillerFunc {
+	// GetTypeHash doesn't tell *T from T, so pointers go through the
+	// reflect.Ptr filler when there is one, which dispatches the element.
+	if field.Field.Type.Kind() == reflect.Ptr && f.FuncByKind[reflect.Ptr] != nil {
+		return nil
+	}
+
+	// The hash of unnamed types, e.g. struct literals or []int, is always
+	// ".", so they can't have a filler of their own.
+	t := field.Field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return nil
+	}
+
 	if f, ok := f.FuncByType[GetTypeHash(field.Field.Type)]; ok {
 		return f
 	}
@@ -0,0 +1,61 @@
+package godefault
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// freePortKeyword is the default of integer fields set to a TCP port free
+// on the machine, for test servers run in parallel:
+//
+//	Port int `default:"freeport"`
+//
+// The port is found by listening on :0 and closing the listener once the
+// system picked one, so another process may take it before the field is
+// used; servers should bind it promptly, and retry on a conflict. The ports
+// of one fill are distinct. A port that can't be found fails the field, an
+// error the error-returning fills, such as FillContext, report. The field
+// must hold up to 65535.
+const freePortKeyword = "freeport"
+
+// freePortAttempts bounds the listens for a port not given yet in the fill.
+const freePortAttempts = 10
+
+// freePortKey is the key of a port given in the fill in the Scratch.
+type freePortKey int
+
+// isFreePort reports whether value is the freePortKeyword.
+func isFreePort(value string) bool {
+	return value == freePortKeyword
+}
+
+// resolveFreePort returns a free TCP port for field, one the fill didn't
+// give another field yet, see freePortKeyword.
+func resolveFreePort(field *FieldData) (string, error) {
+	for i := 0; i < freePortAttempts; i++ {
+		port, err := freePort()
+		if err != nil {
+			return "", err
+		}
+		key := freePortKey(port)
+		if field.Scratch().Value(key) != nil {
+			continue
+		}
+		field.Scratch().SetValue(key, true)
+		return strconv.Itoa(port), nil
+	}
+
+	return "", fmt.Errorf("no free port found in %d attempts", freePortAttempts)
+}
+
+// freePort asks the system for a free TCP port.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, fmt.Errorf("finding a free port: %w", err)
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
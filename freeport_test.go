@@ -0,0 +1,43 @@
+package godefault
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+
+	. "gopkg.in/check.v1"
+)
+
+type FreePortSuite struct{}
+
+var _ = Suite(&FreePortSuite{})
+
+type ExampleFreePort struct {
+	Port    int    `default:"freeport"`
+	Admin   uint16 `default:"freeport"`
+	Pointer *int   `default:"env:GODEFAULT_TEST_PORT:freeport"`
+	Name    string `default:"freeport"`
+	Small   int8   `default:"freeport"`
+}
+
+func (s *FreePortSuite) TestSetDefaultsFreePort(c *C) {
+	foo := &ExampleFreePort{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Small: value \d+ overflows int8`)
+
+	c.Assert(foo.Port, Not(Equals), 0)
+	c.Assert(foo.Admin, Not(Equals), uint16(0))
+	c.Assert(*foo.Pointer, Not(Equals), 0)
+	c.Assert(foo.Name, Equals, "freeport")
+	ports := map[int]bool{foo.Port: true, int(foo.Admin): true, *foo.Pointer: true}
+	c.Assert(ports, HasLen, 3)
+
+	// The port is released, it can be bound.
+	l, err := net.Listen("tcp", ":"+strconv.Itoa(foo.Port))
+	c.Assert(err, IsNil)
+	l.Close()
+
+	c.Assert(CheckDefaults(foo), HasLen, 1)
+	c.Assert(TagFeatures(reflect.TypeOf(0), "freeport"), DeepEquals, []Feature{FeatureFreePort})
+}
@@ -0,0 +1,75 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FreezeDefaultFiller freezes the filler shared by SetDefaults and the other
+// package level functions: from then on, the shared filler panics when it is
+// restricted or its FuncByName, FuncByType or FuncByKind fillers are changed.
+// An application calls it once, e.g. from main, so that no dependency can
+// change what the others fill; a library customizes a Filler of its own
+// instead, see NewFiller.
+func FreezeDefaultFiller() {
+	f := getDefaultFiller()
+	if f.frozen == nil {
+		f.frozen = &frozenFuncs{
+			byName: copyFuncs(f.FuncByName).(map[string]FillerFunc),
+			byType: copyFuncs(f.FuncByType).(map[TypeHash]FillerFunc),
+			byKind: copyFuncs(f.FuncByKind).(map[reflect.Kind]FillerFunc),
+		}
+	}
+}
+
+// frozenFuncs holds the fillers of a frozen Filler, as they were when it was
+// frozen.
+type frozenFuncs struct {
+	byName map[string]FillerFunc
+	byType map[TypeHash]FillerFunc
+	byKind map[reflect.Kind]FillerFunc
+}
+
+func copyFuncs(funcs interface{}) interface{} {
+	m := reflect.ValueOf(funcs)
+	c := reflect.MakeMapWithSize(m.Type(), m.Len())
+	for _, k := range m.MapKeys() {
+		c.SetMapIndex(k, m.MapIndex(k))
+	}
+
+	return c.Interface()
+}
+
+// sameFuncs reports whether the funcs map holds the frozen fillers, funcs
+// compared by their code pointer.
+func sameFuncs(funcs, frozen interface{}) bool {
+	m, old := reflect.ValueOf(funcs), reflect.ValueOf(frozen)
+	if m.Len() != old.Len() {
+		return false
+	}
+	for _, k := range m.MapKeys() {
+		fn := old.MapIndex(k)
+		if !fn.IsValid() || fn.Pointer() != m.MapIndex(k).Pointer() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkFrozen panics when the fillers of a frozen f changed since it was
+// frozen.
+func (f *Filler) checkFrozen() {
+	if f.frozen == nil {
+		return
+	}
+	for name, same := range map[string]bool{
+		"FuncByName": sameFuncs(f.FuncByName, f.frozen.byName),
+		"FuncByType": sameFuncs(f.FuncByType, f.frozen.byType),
+		"FuncByKind": sameFuncs(f.FuncByKind, f.frozen.byKind),
+	} {
+		if !same {
+			panic(fmt.Sprintf("godefault: %s of the frozen default filler changed, use a Filler of your own", name))
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package godefault
+
+import (
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type FreezeSuite struct{}
+
+var _ = Suite(&FreezeSuite{})
+
+type ExampleFreeze struct {
+	Name string `default:"app"`
+	Port int    `default:"8080"`
+}
+
+func (s *FreezeSuite) TestFreezeDefaultFiller(c *C) {
+	FreezeDefaultFiller()
+	defer func() { getDefaultFiller().frozen = nil }()
+	FreezeDefaultFiller()
+
+	foo := &ExampleFreeze{}
+	SetDefaults(foo)
+	c.Assert(foo, DeepEquals, &ExampleFreeze{Name: "app", Port: 8080})
+
+	c.Assert(func() { getDefaultFiller().Restrict(FeatureEnv) }, PanicMatches, `godefault: Restrict on the frozen default filler.*`)
+	c.Assert(getDefaultFiller().allowed, IsNil)
+
+	byName := defaultFiller.FuncByName
+	defaultFiller.FuncByName = map[string]FillerFunc{"Name": func(field *FieldData) {}}
+	c.Assert(func() { SetDefaults(&ExampleFreeze{}) }, PanicMatches, `godefault: FuncByName of the frozen default filler changed.*`)
+	defaultFiller.FuncByName = byName
+
+	hash := GetTypeHash(reflect.TypeOf(""))
+	fill := defaultFiller.FuncByType[hash]
+	defaultFiller.FuncByType[hash] = func(field *FieldData) {}
+	c.Assert(func() { SetDefaults(&ExampleFreeze{}) }, PanicMatches, `godefault: FuncByType of the frozen default filler changed.*`)
+	delete(defaultFiller.FuncByType, hash)
+	if fill != nil {
+		defaultFiller.FuncByType[hash] = fill
+	}
+
+	NewFiller().Restrict(FeatureEnv)
+	SetDefaults(&ExampleFreeze{})
+}
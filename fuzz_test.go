@@ -0,0 +1,312 @@
+//go:build go1.18
+// +build go1.18
+
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The parsers of this package handle tags that may come from override files,
+// so none of them may panic, whatever the input, see tags.go. Run one target
+// at a time with
+//
+//	go test -run XXX -fuzz FuzzParseEnvString
+
+func FuzzParseEnvString(f *testing.F) {
+	for _, seed := range []string{
+		"envs|EnvType1|dev,MTE=|prod,12|stg,aBc|,||DeFgHiJkLmNoP",
+		"envs|dev,11|prod,12|stg,13",
+		"envs|KEY|dev,,MTE=|prod,x",
+		"envs|KEY|dev",
+		"envs|KEY|dev,1,2|prod",
+		"envs|,|",
+		"envs|||,|",
+		"envs|",
+		"envs",
+		"[a,b]|c",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		result := parseEnvString(value)
+		if checkEnvsValue(value) != nil && result != value {
+			t.Errorf("parseEnvString(%q) = %q, expected the malformed value unchanged", value, result)
+		}
+	})
+}
+
+// FuzzParseDateTimeString checks that date and time placeholders are only
+// touched when they are well formed, see checkPlaceholders.
+func FuzzParseDateTimeString(f *testing.F) {
+	for _, seed := range []string{
+		"{{date:1,-5,10}}",
+		"{{time:1,-5,10}}",
+		"{{date:,,}} at {{time:-1,,}}",
+		"{{ .Name }} {{time:1,-5,10}}",
+		"{{date:1,x,0}}",
+		"on {{week:1,0,0}}",
+		"{{date:99999999999999999999,0,0}}",
+		"{{time:9223372036854775807,9223372036854775807,0}}",
+		"{{date:1,2}}",
+		"{{",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		result := parseDateTimeString(value)
+		if !strings.Contains(value, "{{") && result != value {
+			t.Errorf("parseDateTimeString(%q) = %q, expected the value unchanged", value, result)
+		}
+	})
+}
+
+func FuzzParseDateTime(f *testing.F) {
+	for _, seed := range []string{
+		"2020-08-10 12:55:10",
+		"2020-08-10 2006-01-02",
+		"12:55 10-08-2020 15:04 02-01-2006",
+		"2020-08-10T12:55:10Z",
+		"Mon Aug 10 12:55:10 UTC 2020",
+		"3:04PM",
+		"yesterday",
+		"a b c d e f",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		parsed, err := parseDateTime(value)
+		if err != nil && !parsed.IsZero() {
+			t.Errorf("parseDateTime(%q) = %v with error %v, expected the zero time", value, parsed, err)
+		}
+	})
+}
+
+// FuzzSplitSliceTag checks that the bracket syntax round trips: joining the
+// elements back, quoted, gives the same elements.
+func FuzzSplitSliceTag(f *testing.F) {
+	for _, seed := range []string{
+		"[a,b,c]",
+		"[1,2,3]",
+		"[]",
+		"[a|,b,c]",
+		"[,]",
+		"[[a],[b]]",
+		"[[1,2],{a:[3]}]",
+		"[[a,b}]",
+		`[["]",x],[`,
+		`["a,b", "c]d", ""]`,
+		`["a\"b" ,c]`,
+		`["a`,
+		`["a"b]`,
+		"1,2",
+		"[",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		elems, ok, err := splitSliceTag(value)
+		if !ok || err != nil {
+			if elems != nil {
+				t.Errorf("splitSliceTag(%q) = %q, expected no elements", value, elems)
+			}
+			return
+		}
+		if len(elems) == 0 {
+			return
+		}
+		quoted := make([]string, len(elems))
+		for i, elem := range elems {
+			quoted[i] = strconv.Quote(elem)
+		}
+		joined := "[" + strings.Join(quoted, ",") + "]"
+		if again, _, err := splitSliceTag(joined); err != nil || !reflect.DeepEqual(again, elems) {
+			t.Errorf("splitSliceTag(%q) = %q, joined back as %q it is %q, %v", value, elems, joined, again, err)
+		}
+	})
+}
+
+func FuzzSplitTagList(f *testing.F) {
+	for _, seed := range []string{"a;b", "a||b|c", "a  b\tc", `"a;b";c`, "k:v|:w;x:y"} {
+		for _, sep := range ";| ,→" {
+			f.Add(seed, sep, false)
+			f.Add(seed, sep, true)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, body string, sep rune, entries bool) {
+		result, err := splitTagList(body, entries, sep)
+		if err != nil && result != nil {
+			t.Errorf("splitTagList(%q, %t, %q) = %q with error %v, expected no elements", body, entries, sep, result, err)
+		}
+	})
+}
+
+func FuzzSplitMapTag(f *testing.F) {
+	for _, seed := range []string{
+		"{a:1,b:2}",
+		"{a:true}",
+		"{eu:,us:}",
+		"{a|:b:c|,d}",
+		`{"a:b": "c,d"}`,
+		`{a:"b":c}`,
+		"{a}",
+		"{}",
+		"{",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		entries, ok, err := splitMapTag(value)
+		if (!ok || err != nil) && entries != nil {
+			t.Errorf("splitMapTag(%q) = %q with an error, expected no entries", value, entries)
+		}
+	})
+}
+
+func FuzzParseBytesValue(f *testing.F) {
+	for _, seed := range []string{
+		"data:image/png;base64,iVBORw0KGgo=",
+		"data:,hello%20world",
+		"data:;base64,!!",
+		"data:,%zz",
+		"data:",
+		"hex:00ff",
+		"hex:0",
+		"plain",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		data, err := parseBytesValue(value)
+		if err != nil && data != nil {
+			t.Errorf("parseBytesValue(%q) = %q with error %v, expected no data", value, data, err)
+		}
+		encoded := false
+		for _, prefix := range []string{dataURIPrefix, base64Prefix, hexPrefix} {
+			encoded = encoded || strings.HasPrefix(value, prefix)
+		}
+		if !encoded && string(data) != value {
+			t.Errorf("parseBytesValue(%q) = %q, expected the value as it is", value, data)
+		}
+	})
+}
+
+// fuzzTypes are the types FuzzCheckTagValue validates values against, one
+// per parser of the default filler.
+var fuzzTypes = []reflect.Type{
+	reflect.TypeOf(int8(0)),
+	reflect.TypeOf(0),
+	reflect.TypeOf(uint16(0)),
+	reflect.TypeOf(uint64(0)),
+	reflect.TypeOf(float32(0)),
+	reflect.TypeOf(false),
+	reflect.TypeOf(""),
+	reflect.TypeOf(time.Duration(0)),
+	reflect.TypeOf(time.Time{}),
+	reflect.TypeOf([]byte(nil)),
+	reflect.TypeOf([]int(nil)),
+	reflect.TypeOf(map[string]uint(nil)),
+	reflect.TypeOf(map[int][]string(nil)),
+	reflect.TypeOf((*int)(nil)),
+	reflect.TypeOf(map[string]interface{}(nil)),
+}
+
+// FuzzCheckTagValue feeds every parser of the default filler through
+// CheckDefaults' entry point, and the integer ones directly, so that
+// transforms, quantities, processor counts and references are covered.
+func FuzzCheckTagValue(f *testing.F) {
+	for _, seed := range []string{
+		"33",
+		"300",
+		"-1",
+		"1e40",
+		"true",
+		"2m3s",
+		"[a,b,c]",
+		"[1,a]",
+		"{a:1,b:2}",
+		"{1:[a|,b]}",
+		"env:PORT:8080",
+		"env:PORT:8080|+1",
+		"env:HOST:localhost",
+		"envindirect:CONFIG_KEY:8080",
+		"env:READERS:numcpu-1",
+		"numcpu*2",
+		"host:^prod-.*=production,^stage-.*=staging,*=development",
+		"host:[a-=x",
+		"gomaxprocs/0",
+		"len:Peers|*2",
+		"q:1.5Ki",
+		"q:1e64|*9223372036854775807",
+		"9223372036854775807|+1",
+		"18446744073709551615|*2",
+		"envs|KEY|dev,1|prod,2",
+		"{{date:1,-5,10}}",
+		"data:image/png;base64,iVBORw0KGgo=",
+	} {
+		for i := range fuzzTypes {
+			f.Add(seed, uint8(i))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, value string, i uint8) {
+		typ := fuzzTypes[int(i)%len(fuzzTypes)]
+		checkTagValue(typ, value)
+		if isIntegerType(typ) {
+			parseIntValue(value, reflect.TypeOf(int16(0)))
+			parseUintValue(value, reflect.TypeOf(uint16(0)))
+			resolveCPUExpr(value)
+		}
+	})
+}
+
+// FuzzFill fills a struct holding a field of every fuzzTypes type, all tagged
+// with the same value, and checks that no filler panics: Fill has to survive
+// any tag, and FillContext must report errors rather than recovered panics.
+func FuzzFill(f *testing.F) {
+	for _, seed := range []string{
+		"1",
+		"[1,2]",
+		"{a:1}",
+		"env:PORT:8080|+1",
+		"len:F10",
+		"envs|KEY|dev,1|prod,2",
+		"{{date:1,-5,10}} {{time:1,-5,10}}",
+		"2020-08-10 12:55:10",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		fields := make([]reflect.StructField, len(fuzzTypes))
+		for i, typ := range fuzzTypes {
+			fields[i] = reflect.StructField{
+				Name: "F" + strconv.Itoa(i),
+				Type: typ,
+				Tag:  reflect.StructTag("default:" + strconv.Quote(value)),
+			}
+		}
+		typ := reflect.StructOf(fields)
+
+		SetDefaults(reflect.New(typ).Interface())
+		err := NewFiller(WithStrict()).FillContext(context.Background(), reflect.New(typ).Interface())
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			t.Errorf("filling tag %q panicked: %v", value, panicErr.Value)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package godefault
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// genPrefix introduces a default produced by a generator registered with
+// RegisterGenerator, run anew for every field and every fill:
+//
+//	RequestID string `default:"gen:uuid"`
+//
+// A #label makes the fields of a fill that use the same generator and label
+// share one value, generated once per fill, e.g. linked IDs:
+//
+//	TraceID string `default:"gen:uuid#trace"`
+//	LogID   string `default:"gen:uuid#trace"` // same as TraceID
+//
+// The uuid generator, random version 4 UUIDs, is built in.
+const genPrefix = "gen:"
+
+var genPattern = regexp.MustCompile(`^gen:(\w+)(?:#(\w+))?$`)
+
+// generators holds the generators registered with RegisterGenerator, by
+// name.
+var generators sync.Map
+
+func init() {
+	RegisterGenerator("uuid", newUUID)
+}
+
+// RegisterGenerator registers generate as the generator gen:name defaults
+// run, usually from an init function:
+//
+//	godefault.RegisterGenerator("nonce", func() (string, error) {
+//	    return strconv.FormatInt(time.Now().UnixNano(), 36), nil
+//	})
+//
+// The value generated is parsed like a default tag. Registering the same name
+// again replaces the generator.
+func RegisterGenerator(name string, generate func() (string, error)) {
+	generators.Store(name, generate)
+}
+
+func isGenRef(value string) bool {
+	return strings.HasPrefix(value, genPrefix)
+}
+
+// parseGenRef splits a gen: default into the name of its generator and its
+// label, if any.
+func parseGenRef(value string) (name, label string, err error) {
+	match := genPattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", "", fmt.Errorf("invalid generator %q, expected %sname[#label]", value, genPrefix)
+	}
+	if _, ok := generators.Load(match[1]); !ok {
+		return "", "", &ResolverError{Prefix: genPrefix, Err: fmt.Errorf("%s%s: no such registered generator", genPrefix, match[1])}
+	}
+
+	return match[1], match[2], nil
+}
+
+// resolveGenRef runs the generator of the gen: default of field, or reuses
+// the value its label got earlier in the fill.
+func resolveGenRef(field *FieldData) (string, error) {
+	name, label, err := parseGenRef(field.TagValue)
+	if err != nil {
+		return "", err
+	}
+
+	key := generatedKey{name: name, label: label}
+	if label != "" {
+		if value, ok := field.Scratch().Value(key).(string); ok {
+			return value, nil
+		}
+	}
+	generate, _ := generators.Load(name)
+	value, err := generate.(func() (string, error))()
+	if err != nil {
+		return "", &ResolverError{Prefix: genPrefix, Err: fmt.Errorf("%s%s: %w", genPrefix, name, err)}
+	}
+	if label != "" {
+		field.Scratch().SetValue(key, value)
+	}
+
+	return value, nil
+}
+
+// generatedKey is the key of the value of a labelled gen: default in the
+// Scratch, by generator and label.
+type generatedKey struct {
+	name, label string
+}
+
+// newUUID returns a random, version 4, UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,81 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+type GenSuite struct{}
+
+var _ = Suite(&GenSuite{})
+
+type ExampleGenChild struct {
+	TraceID string `default:"gen:uuid#trace"`
+}
+
+type ExampleGen struct {
+	RequestID string `default:"gen:uuid"`
+	Other     string `default:"gen:uuid"`
+	TraceID   string `default:"gen:uuid#trace"`
+	LogID     string `default:"gen:uuid#trace"`
+	SpanID    string `default:"gen:uuid#span"`
+	Child     ExampleGenChild
+	Children  []ExampleGenChild `default:"make:2"`
+}
+
+func (s *GenSuite) TestGen(c *C) {
+	foo := &ExampleGen{}
+	report := &FillReport{}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.RequestID, Matches, `[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}`)
+	c.Assert(foo.Other, Not(Equals), foo.RequestID)
+	c.Assert(foo.LogID, Equals, foo.TraceID)
+	c.Assert(foo.SpanID, Not(Equals), foo.TraceID)
+	c.Assert(foo.Child.TraceID, Equals, foo.TraceID)
+	c.Assert(foo.Children[1].TraceID, Equals, foo.TraceID)
+	c.Assert(report.Fields[0].Source, Equals, SourceGenerated)
+
+	// Labels are shared within a fill only.
+	bar := &ExampleGen{}
+	SetDefaults(bar)
+	c.Assert(bar.TraceID, Not(Equals), foo.TraceID)
+	c.Assert(bar.LogID, Equals, bar.TraceID)
+
+	c.Assert(CheckDefaults(&ExampleGen{}), HasLen, 0)
+}
+
+func (s *GenSuite) TestRegisterGenerator(c *C) {
+	n := 0
+	RegisterGenerator("godefault_test_counter", func() (string, error) {
+		n++
+		return string(rune('0' + n)), nil
+	})
+	RegisterGenerator("godefault_test_broken", func() (string, error) {
+		return "", errors.New("out of entropy")
+	})
+
+	foo := &struct {
+		First   int    `default:"gen:godefault_test_counter"`
+		Second  int    `default:"gen:godefault_test_counter"`
+		Shared  int    `default:"gen:godefault_test_counter#a"`
+		Again   int    `default:"gen:godefault_test_counter#a"`
+		Broken  string `default:"gen:godefault_test_broken"`
+		Unknown string `default:"gen:nope"`
+		Invalid string `default:"gen:uuid#"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Broken: gen:godefault_test_broken: out of entropy\n`+
+		`Unknown: gen:nope: no such registered generator\n`+
+		`Invalid: invalid generator "gen:uuid#", expected gen:name\[#label\]`)
+
+	c.Assert(foo.First, Equals, 1)
+	c.Assert(foo.Second, Equals, 2)
+	c.Assert(foo.Shared, Equals, 3)
+	c.Assert(foo.Again, Equals, 3)
+	c.Assert(foo.Broken, Equals, "")
+
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+}
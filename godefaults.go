@@ -1,16 +1,13 @@
 package godefault
 
 import (
-	"encoding/base64"
 	"fmt"
-	"os"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-
-	"github.com/sonnt85/gogmap"
 )
 
 // Applies the default values to the struct object, the struct type must have
@@ -31,12 +28,28 @@ func SetDefaults(variable interface{}, tagNames ...string) {
 	getDefaultFiller(tagNames...).Fill(variable)
 }
 
-var defaultFiller *Filler = nil
+// defaultFiller is the filler shared by SetDefaults and the other package
+// level functions. It is never exposed, so that no dependency can change what
+// the others fill: customizations go to a Filler of their own, see NewFiller
+// and FreezeDefaultFiller.
+var (
+	defaultFiller     *Filler
+	defaultFillerOnce sync.Once
+)
 
+// getDefaultFiller returns the shared filler, created on first use with the
+// tag names of that first call, once checked it wasn't changed since frozen.
 func getDefaultFiller(tagNames ...string) *Filler {
-	if defaultFiller == nil {
+	f := sharedFiller(tagNames...)
+	f.checkFrozen()
+
+	return f
+}
+
+func sharedFiller(tagNames ...string) *Filler {
+	defaultFillerOnce.Do(func() {
 		defaultFiller = newDefaultFiller(tagNames...)
-	}
+	})
 
 	return defaultFiller
 }
@@ -65,113 +78,142 @@ func getDefaultFiller(tagNames ...string) *Filler {
 // - envStr: The environment variable string to parse.
 //
 // Return value:
-// - retstr: The value of the parsed environment variable, or a default value if not found.
-func parseEnvString(envStr string) (retstr string) {
-	// envs|[envkey|]env1,env1_value_[base64]|env2,,env2_value_[base64]|...
-	retstr = envStr
-	prefix := "envs|"
-	if strings.HasPrefix(envStr, prefix) {
-		envStr = envStr[len(prefix):]
-	} else {
-		return
+//   - The value of the entry matching the environment variable, or the value of the
+//     first entry when the variable is unset or matches none. Malformed strings are
+//     returned unchanged; the function never panics.
+func parseEnvString(envStr string) string {
+	return resolveEnvsValue(envStr, lookupEnv)
+}
+
+// resolveEnvsValue is parseEnvString reading the variable with lookup.
+func resolveEnvsValue(envStr string, lookup func(key string) (string, bool)) string {
+	if !strings.HasPrefix(envStr, "envs|") {
+		return envStr
 	}
-	envStrTmp := strings.ReplaceAll(envStr, "|,", "__orcomma__")
-	envStrTmp = strings.ReplaceAll(envStrTmp, "||", "__oror__")
-	parts := strings.Split(envStrTmp, "|")
-	if envStrTmp != envStr {
-		defer func() {
-			retstr = strings.ReplaceAll(retstr, "__oror__", "|")
-			retstr = strings.ReplaceAll(retstr, "__orcomma__", ",")
-		}()
+
+	// Malformed mappings are kept as they are, see CheckDefaults.
+	key, entries, err := parseEnvsValue(envStr)
+	if err != nil {
+		return envStr
 	}
-	// parts := strings.Split(envStr, "|")
-	if len(parts) < 2 {
-		return
+
+	if value, ok := lookup(key); ok {
+		for _, entry := range entries {
+			if entry.name == value {
+				return entry.value
+			}
+		}
 	}
 
-	// Extract the environment key from the first part (before the colon)
-	separatorChar := ","
+	// The first entry is the default.
+	return entries[0].value
+}
 
-	key := parts[0]
-	if strings.Contains(key, separatorChar) {
-		key = "EnvType"
-	} else {
-		parts = parts[1:]
+// timeLayouts are the layouts parseDateTime tries, in this order, on values
+// that aren't followed by their layout.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	time.ANSIC,
+	time.UnixDate,
+	time.RFC822,
+	time.RFC850,
+	time.Kitchen,
+}
+
+// startupKeyword is the time.Time default resolving to startTime, the same
+// on every fill of the process, e.g. for a boot time or session ID:
+//
+//	BootedAt time.Time `default:"startup"`
+const startupKeyword = "startup"
+
+// dayKeywords are the time.Time defaults resolving to midnight of a day
+// relative to the day of the fill, by offset in days, in local time or in the
+// location following an @, e.g. "tomorrow@UTC" or "today@Europe/Paris".
+var dayKeywords = map[string]int{"yesterday": -1, "today": 0, "tomorrow": 1}
+
+// startTime is captured when the package is initialized, i.e. on its first
+// import, which is close enough to the start of the process.
+var startTime = time.Now()
+
+// parseDateTime parses a time.Time default: a value followed by its layout,
+// as many words each, e.g. "10/08/2020 12:55 02/01/2006 15:04", a value in
+// one of timeLayouts, startupKeyword or one of dayKeywords. A value of more
+// than two words is first tried with an explicit layout.
+func parseDateTime(dateTimeString string) (time.Time, error) {
+	if dateTimeString == startupKeyword {
+		return startTime, nil
 	}
-	// value := os.Getenv(key)
-	value := gogmap.Get(key)
-	if value == "" {
-		value = os.Getenv(key)
+	if isDayKeyword(dateTimeString) {
+		return parseDayKeyword(dateTimeString)
 	}
-	defaultValue := ""
-	// Loop through the remaining parts to find the matching environment variable
-	for i, part := range parts {
-		envValues := strings.Split(part, separatorChar)
 
-		if len(envValues) != 2 && len(envValues) != 3 {
-			return
-		}
-		if i == 0 {
-			defaultValue = envValues[1]
-			if value == "" {
-				value = envValues[0]
-			}
+	parts := strings.Fields(dateTimeString)
+	var layoutErr error
+	if len(parts) > 2 {
+		layout := strings.Join(parts[len(parts)/2:], " ")
+		value := strings.Join(parts[:len(parts)/2], " ")
+		parsedTime, err := time.Parse(layout, value)
+		if err == nil {
+			return parsedTime, nil
 		}
-		// Check if the current environment key matches the specified key
-		if envValues[0] == value {
-			if len(envValues) == 3 {
-				if envValues[1] == "" {
-					if decodedBytes, err := base64.StdEncoding.DecodeString(envValues[2]); err == nil {
-						return string(decodedBytes)
-					}
-				}
-				return
-			}
-			retstr = envValues[1]
-			return
+		layoutErr = err
+	}
 
+	for _, layout := range timeLayouts {
+		if parsedTime, err := time.Parse(layout, dateTimeString); err == nil {
+			return parsedTime, nil
 		}
 	}
-	retstr = defaultValue
-	return
-}
+	if layoutErr != nil {
+		return time.Time{}, layoutErr
+	}
 
-// parseDateTimeString parses a string consisting of two parts: a layout and a time value.
-// If a layout is provided, it uses that layout to parse the time value. If no layout is
-// provided, it uses the default layout "2006-01-02 15:04:05".
-func parseDateTime(dateTimeString string) (time.Time, error) {
-	// Split the string into layout and value using a space as the separator
-	// 	parts := strings.Split(dateTimeString, " ")
-	parts := strings.Fields(dateTimeString)
+	return time.Time{}, fmt.Errorf("invalid string: %s", dateTimeString)
+}
 
-	if len(parts) < 2 {
-		return time.Time{}, fmt.Errorf("invalid string: %s", dateTimeString)
+// isDayKeyword reports whether value is one of dayKeywords, with or without
+// a location.
+func isDayKeyword(value string) bool {
+	if i := strings.IndexByte(value, '@'); i >= 0 {
+		value = value[:i]
 	}
+	_, ok := dayKeywords[value]
 
-	layout := "2006-01-02 15:04:05"
-	value := dateTimeString
-	if len(parts) > 2 {
-		layout = strings.Join(parts[len(parts)/2:], " ")
-		value = strings.Join(parts[:len(parts)/2], " ")
-	}
+	return ok
+}
 
-	parsedTime, err := time.Parse(layout, value)
-	if err != nil {
-		return time.Time{}, err
+// parseDayKeyword returns midnight of the day value, one of dayKeywords,
+// stands for.
+func parseDayKeyword(value string) (time.Time, error) {
+	loc := time.Local
+	if i := strings.IndexByte(value, '@'); i >= 0 {
+		if value[i+1:] == "" {
+			return time.Time{}, fmt.Errorf("invalid string: %s, expected a location after @", value)
+		}
+		var err error
+		if loc, err = time.LoadLocation(value[i+1:]); err != nil {
+			return time.Time{}, fmt.Errorf("invalid location in %q: %w", value, err)
+		}
+		value = value[:i]
 	}
 
-	return parsedTime, nil
+	year, month, day := time.Now().In(loc).Date()
+
+	return time.Date(year, month, day+dayKeywords[value], 0, 0, 0, 0, loc), nil
 }
 
 func newDefaultFiller(tagNames ...string) *Filler {
 	funcs := make(map[reflect.Kind]FillerFunc, 0)
 	funcs[reflect.Bool] = func(field *FieldData) {
-		value, _ := strconv.ParseBool(field.TagValue)
+		value, err := parseBoolValue(field.TagValue)
+		field.check(err)
 		field.Value.SetBool(value)
 	}
 
 	funcs[reflect.Int] = func(field *FieldData) {
-		value, _ := strconv.ParseInt(field.TagValue, 10, 64)
+		value, err := parseIntValue(field.TagValue, field.Value.Type())
+		field.check(err)
 		field.Value.SetInt(value)
 	}
 
@@ -179,24 +221,34 @@ func newDefaultFiller(tagNames ...string) *Filler {
 	funcs[reflect.Int16] = funcs[reflect.Int]
 	funcs[reflect.Int32] = funcs[reflect.Int]
 	funcs[reflect.Int64] = func(field *FieldData) {
-		if field.Field.Type == reflect.TypeOf(time.Second) {
-			value, _ := time.ParseDuration(field.TagValue)
+		if field.Field.Type == durationType {
+			value, err := parseFieldDuration(field, field.TagValue)
+			field.check(err)
+			if err == nil {
+				value = clampDuration(field, value)
+			}
 			field.Value.Set(reflect.ValueOf(value))
 		} else {
-			value, _ := strconv.ParseInt(field.TagValue, 10, 64)
+			value, err := parseIntValue(field.TagValue, field.Value.Type())
+			field.check(err)
 			field.Value.SetInt(value)
 		}
 	}
 
 	funcs[reflect.Float32] = func(field *FieldData) {
-		value, _ := strconv.ParseFloat(field.TagValue, 64)
+		value, err := parseFloatValue(field.TagValue, field.Value.Type())
+		field.check(err)
+		if err == nil {
+			value = clampFloat(field, value)
+		}
 		field.Value.SetFloat(value)
 	}
 
 	funcs[reflect.Float64] = funcs[reflect.Float32]
 
 	funcs[reflect.Uint] = func(field *FieldData) {
-		value, _ := strconv.ParseUint(field.TagValue, 10, 64)
+		value, err := parseUintValue(field.TagValue, field.Value.Type())
+		field.check(err)
 		field.Value.SetUint(value)
 	}
 
@@ -209,80 +261,249 @@ func newDefaultFiller(tagNames ...string) *Filler {
 		if field.TagValue == "-," {
 			field.TagValue = "-"
 		}
-		tagValue := parseEnvString(field.TagValue)
-		if tagValue == field.TagValue {
-			tagValue = parseDateTimeString(field.TagValue)
+		if requiresEnvs(field) {
+			return
+		}
+		tagValue := field.TagValue
+		if !field.literal {
+			tagValue = resolveEnvsValue(field.TagValue, field.lookupEnv)
+			if tagValue == field.TagValue {
+				tagValue = parseDateTimeString(field.TagValue)
+			}
 		}
 		field.Value.SetString(tagValue)
+		validateHostPort(field)
 	}
 
 	funcs[reflect.Struct] = func(field *FieldData) {
-		fields := getDefaultFiller().GetFieldsFromValue(field.Value, nil)
-		getDefaultFiller().SetDefaultValues(fields)
+		if isStructModeType(field.Value.Type()) && skipsStruct(field) {
+			return
+		}
+		filler := field.owner()
+		fields := filler.GetFieldsFromValue(field.Value, field)
+		if inheritDefaults(field, fields) {
+			filler.SetDefaultValues(fields)
+		}
 	}
 
+	// Nil pointers are allocated only when there is a default to put behind
+	// them, at every level of pointers to pointers. Set pointers to structs
+	// and to struct slices are descended into.
+	funcs[reflect.Ptr] = func(field *FieldData) {
+		filler := field.owner()
+		// p is the last set pointer, every one of them is recorded so that
+		// cyclic data terminates.
+		p := field.Value
+		for !p.IsNil() && p.Elem().Kind() == reflect.Ptr {
+			if field.visit(p.Pointer()) {
+				return
+			}
+			p = p.Elem()
+		}
+		if !p.IsNil() {
+			elemType := p.Type().Elem()
+			structSlice := elemType.Kind() == reflect.Slice && elemType.Elem().Kind() == reflect.Struct
+			if (!filler.isStructType(elemType) && !structSlice) || field.visit(p.Pointer()) {
+				return
+			}
+			if structSlice {
+				elem := pointee(field, p)
+				if fn := filler.getFunction(elem); fn != nil {
+					fn(elem)
+				}
+				return
+			}
+			fields := filler.GetFieldsFromValue(p.Elem(), field)
+			filler.SetDefaultValues(fields)
+			return
+		}
+		if field.TagValue == "" {
+			return
+		}
+
+		// The missing levels are allocated at once, then set if the value
+		// they lead to was filled.
+		value := reflect.New(p.Type().Elem())
+		leaf := value
+		for leaf.Elem().Kind() == reflect.Ptr {
+			next := reflect.New(leaf.Elem().Type().Elem())
+			leaf.Elem().Set(next)
+			leaf = next
+		}
+		elem := pointee(field, leaf)
+		if isStructModeType(elem.Value.Type()) && filler.isStructType(elem.Value.Type()) && !(field.owner().compat == CompatCreasty && isJSONValue(elem.TagValue)) && !isDecodedValue(elem.TagValue) {
+			// Any tag allocates a struct, it has no mode, but for the JSON
+			// objects of CompatCreasty and the values of decoders.
+			elem.TagValue = ""
+		}
+		if fn := filler.getFunction(elem); fn != nil {
+			errs := elem.errCount()
+			fn(elem)
+			// Values that failed, and slices the tag didn't make, stay behind
+			// a nil pointer.
+			if elem.errCount() != errs || (leaf.Elem().Kind() == reflect.Slice && leaf.Elem().IsNil()) {
+				return
+			}
+			p.Set(value)
+		}
+	}
+
+	// Keys and values are filled like fields of their own type, e.g.
+	// {1:a,2:b} for a map[int]string. Entries with an invalid key are left
+	// out.
+	funcs[reflect.Map] = func(field *FieldData) {
+		if isLooseType(field.Value.Type()) {
+			fillJSON(field)
+			return
+		}
+		sep, ok := separator(field)
+		if !ok {
+			return
+		}
+		entries, ok, err := splitMapTagSep(field.TagValue, sep)
+		field.check(err)
+		if !ok || err != nil {
+			return
+		}
+
+		t := field.Value.Type()
+		result := reflect.MakeMapWithSize(t, len(entries))
+		for _, entry := range entries {
+			if err := checkTagValue(t.Key(), entry.key); err != nil {
+				field.check(fmt.Errorf("invalid map key: %w", err))
+				continue
+			}
+			result.SetMapIndex(fillElement(field, t.Key(), entry.key, entry.key), fillElement(field, t.Elem(), entry.value, entry.key))
+		}
+		field.Value.Set(result)
+	}
+
+	funcs[reflect.Func] = fillFunc
+	funcs[reflect.Array] = fillArray
+	funcs[reflect.Chan] = fillChan
+
 	types := make(map[TypeHash]FillerFunc, 1)
 	types["time.Duration"] = func(field *FieldData) {
-		d, _ := time.ParseDuration(field.TagValue)
+		d, err := parseFieldDuration(field, field.TagValue)
+		field.check(err)
+		if err == nil {
+			d = clampDuration(field, d)
+		}
 		field.Value.Set(reflect.ValueOf(d))
 	}
+	types["regexp.Regexp"] = fillRegexp
+	types["sync.Map"] = fillSyncMap
+	types[GetTypeHash(hostPortType)] = fillHostPort
 	types["time.Time"] = func(field *FieldData) {
-		d, _ := parseDateTime(field.TagValue)
+		d, err := parseDateTime(field.TagValue)
+		field.check(err)
 		field.Value.Set(reflect.ValueOf(d))
 	}
+	for _, t := range nullTypes {
+		types[GetTypeHash(t)] = fillNull
+	}
 	funcs[reflect.Slice] = func(field *FieldData) {
 		k := field.Value.Type().Elem().Kind()
 		switch k {
 		case reflect.Uint8:
-			if field.Value.Bytes() != nil {
-				return
-			}
-			field.Value.SetBytes([]byte(field.TagValue))
+			fillBytes(field)
 		case reflect.Struct:
-			count := field.Value.Len()
+			if isJSONValue(field.TagValue) && field.Value.Len() == 0 {
+				fillJSONSlice(field)
+			}
+			makeSlice(field)
+			filler := field.owner()
+			count := filler.sliceFillCount(field.Value.Len())
 			for i := 0; i < count; i++ {
-				fields := getDefaultFiller().GetFieldsFromValue(field.Value.Index(i), nil)
-				getDefaultFiller().SetDefaultValues(fields)
+				elem := field.Value.Index(i)
+				fields := filler.GetFieldsFromValue(elem, field.element(elem, strconv.Itoa(i)))
+				filler.SetDefaultValues(fields)
+			}
+		case reflect.Interface:
+			if isLooseType(field.Value.Type()) {
+				fillJSON(field)
 			}
 		default:
+			if isMakeLen(field.TagValue) {
+				makeSlice(field)
+				return
+			}
+			if isJSONValue(field.TagValue) {
+				fillJSONSlice(field)
+				return
+			}
 			//处理形如 [1,2,3,4]
-			reg := regexp.MustCompile(`^\[(.*)\]$`)
-			matchs := reg.FindStringSubmatch(field.TagValue)
-			if len(matchs) != 2 {
+			sep, ok := separator(field)
+			if !ok {
+				return
+			}
+			defaultValue, ok, err := splitSliceTagSep(field.TagValue, sep)
+			field.check(err)
+			if !ok || err != nil {
 				return
 			}
-			if matchs[1] == "" {
+			if len(defaultValue) == 0 {
 				field.Value.Set(reflect.MakeSlice(field.Value.Type(), 0, 0))
 			} else {
-				match1 := strings.ReplaceAll(matchs[1], "|,", "__orcomma__") //
-				defaultValue := strings.Split(match1, ",")
 				result := reflect.MakeSlice(field.Value.Type(), len(defaultValue), len(defaultValue))
 				for i := 0; i < len(defaultValue); i++ {
-					itemValue := result.Index(i)
-					defaultValue[i] = strings.ReplaceAll(defaultValue[i], "__orcomma__", ",")
-					item := &FieldData{
-						Value:    itemValue,
-						Field:    reflect.StructField{},
-						TagValue: defaultValue[i],
-						Parent:   nil,
-					}
+					item := field.element(result.Index(i), strconv.Itoa(i))
+					item.TagValue = defaultValue[i]
 					funcs[k](item)
 				}
 				field.Value.Set(result)
+				dedupSlice(field)
 			}
 		}
 	}
-	tagname := "default"
-	if len(tagNames) != 0 {
-		tagname = tagNames[0]
+	builtins := make(map[uintptr]bool)
+	for _, fn := range funcs {
+		builtins[funcPointer(fn)] = true
+	}
+	for _, fn := range types {
+		builtins[funcPointer(fn)] = true
+	}
+
+	return &Filler{FuncByKind: funcs, FuncByType: types, Tag: tagNameOf(tagNames), builtins: builtins, nameTags: defaultNameTags}
+}
+
+// pointee returns the FieldData of the value the pointer field points to,
+// p, filled from the tag of field, which is already resolved.
+func pointee(field *FieldData, p reflect.Value) *FieldData {
+	elemField := field.Field
+	elemField.Type = p.Type().Elem()
+
+	return &FieldData{
+		Value:    p.Elem(),
+		Field:    elemField,
+		TagValue: field.TagValue,
+		Parent:   field.Parent,
+		filler:   field.owner(),
+		state:    field.state,
+		resolved: true,
+		literal:  field.literal,
 	}
-	return &Filler{FuncByKind: funcs, FuncByType: types, Tag: tagname}
+}
+
+// dateTimePattern matches the {{date:Y,M,D}} and {{time:h,m,s}} placeholders,
+// offsets from the current local time, or from the time in a location given
+// after an @, e.g. {{date:0,0,1@UTC}} or {{time:0,0,0@Europe/Paris}}.
+var dateTimePattern = regexp.MustCompile(`\{\{(\w+\:(?:-|)\d*,(?:-|)\d*,(?:-|)\d*)(?:@([^{}@]+))?\}\}`)
+
+// fillElement returns a value of type t filled from the raw value, as the
+// element of the container field at key.
+func fillElement(field *FieldData, t reflect.Type, raw, key string) reflect.Value {
+	value := reflect.New(t).Elem()
+	elem := field.element(value, key)
+	elem.TagValue = raw
+	field.owner().SetDefaultValue(elem)
+
+	return value
 }
 
 func parseDateTimeString(data string) string {
 
-	pattern := regexp.MustCompile(`\{\{(\w+\:(?:-|)\d*,(?:-|)\d*,(?:-|)\d*)\}\}`)
-	matches := pattern.FindAllStringSubmatch(data, -1) // matches is [][]string
+	matches := dateTimePattern.FindAllStringSubmatch(data, -1) // matches is [][]string
 	for _, match := range matches {
 
 		tags := strings.Split(match[1], ":")
@@ -295,15 +516,24 @@ func parseDateTimeString(data string) string {
 					num, _ := strconv.ParseInt(valueString, 10, 64)
 					values[key] = int(num)
 				}
+				now := time.Now()
+				if match[2] != "" {
+					// Unknown locations leave the placeholder as it is.
+					loc, err := time.LoadLocation(match[2])
+					if err != nil {
+						continue
+					}
+					now = now.In(loc)
+				}
 
 				switch tags[0] {
 
 				case "date":
-					str := time.Now().AddDate(values[0], values[1], values[2]).Format("2006-01-02")
+					str := now.AddDate(values[0], values[1], values[2]).Format("2006-01-02")
 					data = strings.Replace(data, match[0], str, -1)
 					break
 				case "time":
-					str := time.Now().Add((time.Duration(values[0]) * time.Hour) +
+					str := now.Add((time.Duration(values[0]) * time.Hour) +
 						(time.Duration(values[1]) * time.Minute) +
 						(time.Duration(values[2]) * time.Second)).Format("15:04:05")
 					data = strings.Replace(data, match[0], str, -1)
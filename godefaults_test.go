@@ -1,7 +1,9 @@
 package godefault
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -133,6 +135,272 @@ func (s *DefaultsSuite) TestSetDefaultsWithValues(c *C) {
 	c.Assert(foo.Children[1].Age, Equals, 2)
 }
 
+type ExamplePointers struct {
+	Int      *int    `default:"42"`
+	String   *string `default:"foo"`
+	Untagged *int
+	Child    *Child
+	Ints     *[]int `default:"[1,2]"`
+	Self     *ExamplePointers
+}
+
+func (s *DefaultsSuite) TestSetDefaultsPointers(c *C) {
+	foo := &ExamplePointers{Child: &Child{}}
+	foo.Self = foo
+	SetDefaults(foo)
+
+	c.Assert(*foo.Int, Equals, 42)
+	c.Assert(*foo.String, Equals, "foo")
+	c.Assert(foo.Untagged, IsNil)
+	c.Assert(foo.Child.Age, Equals, 10)
+	c.Assert(*foo.Ints, DeepEquals, []int{1, 2})
+	c.Assert(foo.Self, Equals, foo)
+}
+
+type ExampleTriState struct {
+	Enabled  *bool `default:"true"`
+	Disabled *bool `default:"false"`
+	Unset    *bool
+	Empty    *bool `default:""`
+	Env      *bool `default:"env:GODEFAULT_TEST_UNSET"`
+	Preset   *bool `default:"true"`
+	Invalid  *bool `default:"maybe"`
+}
+
+func (s *DefaultsSuite) TestSetDefaultsTriState(c *C) {
+	preset := false
+	foo := &ExampleTriState{Preset: &preset}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Invalid: strconv.ParseBool: parsing "maybe": invalid syntax`)
+
+	c.Assert(*foo.Enabled, Equals, true)
+	c.Assert(*foo.Disabled, Equals, false)
+	c.Assert(foo.Unset, IsNil)
+	c.Assert(foo.Empty, IsNil)
+	c.Assert(foo.Env, IsNil)
+	c.Assert(foo.Preset, Equals, &preset)
+	c.Assert(preset, Equals, false)
+	c.Assert(foo.Invalid, IsNil)
+}
+
+type RetryPolicy struct {
+	Attempts int `default:"3"`
+	Backoff  *time.Duration
+}
+
+type ExamplePointerLevels struct {
+	Int      **int `default:"42"`
+	Untagged **int
+	Retry    **RetryPolicy `default:"{}"`
+	Preset   **RetryPolicy
+	Deep     ***[]string `default:"[a,b]"`
+	Self     **ExamplePointerLevels
+}
+
+func (s *DefaultsSuite) TestSetDefaultsPointerLevels(c *C) {
+	policy := &RetryPolicy{}
+	foo := &ExamplePointerLevels{Preset: &policy}
+	self := foo
+	foo.Self = &self
+	SetDefaults(foo)
+
+	c.Assert(**foo.Int, Equals, 42)
+	c.Assert(foo.Untagged, IsNil)
+	c.Assert((**foo.Retry).Attempts, Equals, 3)
+	c.Assert(policy.Attempts, Equals, 3)
+	c.Assert(***foo.Deep, DeepEquals, []string{"a", "b"})
+	c.Assert(*foo.Self, Equals, foo)
+}
+
+func (s *DefaultsSuite) TestSetDefaultsPointerLevelsPartial(c *C) {
+	var level *int
+	foo := &ExamplePointerLevels{Int: &level}
+	SetDefaults(foo)
+
+	c.Assert(foo.Int, Equals, &level)
+	c.Assert(*level, Equals, 42)
+}
+
+type ExampleMaps struct {
+	Strings   map[string]string        `default:"{a:1,b:http://x|,y}"`
+	Ints      map[int]string           `default:"{1:a,2:b}"`
+	Bools     map[bool]int             `default:"{true:1,false:0}"`
+	Durations map[string]time.Duration `default:"{short:1s,long:1m}"`
+	Colon     map[string]int           `default:"{a|:b:1}"`
+	Empty     map[string]int           `default:"{}"`
+	Untagged  map[string]int
+	Preset    map[string]int `default:"{a:1}"`
+	Invalid   map[int]string `default:"{1:a,x:b}"`
+}
+
+func (s *DefaultsSuite) TestSetDefaultsMaps(c *C) {
+	foo := &ExampleMaps{Preset: map[string]int{"b": 2}}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Invalid: invalid map key: strconv.ParseInt: parsing "x": invalid syntax`)
+
+	c.Assert(foo.Strings, DeepEquals, map[string]string{"a": "1", "b": "http://x,y"})
+	c.Assert(foo.Ints, DeepEquals, map[int]string{1: "a", 2: "b"})
+	c.Assert(foo.Bools, DeepEquals, map[bool]int{true: 1, false: 0})
+	c.Assert(foo.Durations, DeepEquals, map[string]time.Duration{"short": time.Second, "long": time.Minute})
+	c.Assert(foo.Colon, DeepEquals, map[string]int{"a:b": 1})
+	c.Assert(foo.Empty, DeepEquals, map[string]int{})
+	c.Assert(foo.Untagged, IsNil)
+	c.Assert(foo.Preset, DeepEquals, map[string]int{"b": 2})
+	c.Assert(foo.Invalid, DeepEquals, map[int]string{1: "a"})
+}
+
+type ExampleDataURI struct {
+	Icon    []byte `default:"data:image/png;base64,iVBORw0KGgo="`
+	Text    []byte `default:"data:text/plain,hello%20world"`
+	Empty   []byte `default:"data:,"`
+	Literal []byte `default:"database"`
+	NoComma []byte `default:"data:text/plain"`
+	Base64  []byte `default:"data:;base64,!!"`
+}
+
+func (s *DefaultsSuite) TestSetDefaultsDataURI(c *C) {
+	foo := &ExampleDataURI{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `NoComma: invalid data URI "data:text/plain", expected .*\nBase64: invalid data URI payload: illegal base64 data at input byte 0`)
+
+	c.Assert(foo.Icon, DeepEquals, []byte("\x89PNG\r\n\x1a\n"))
+	c.Assert(string(foo.Text), Equals, "hello world")
+	c.Assert(foo.Empty, DeepEquals, []byte{})
+	c.Assert(string(foo.Literal), Equals, "database")
+	c.Assert(foo.NoComma, IsNil)
+	c.Assert(foo.Base64, IsNil)
+
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+}
+
+type ExampleBase64 struct {
+	Key     []byte   `default:"base64:aGVsbG8="`
+	Certs   [][]byte `default:"[base64:AAA=,base64:AQI=]"`
+	Mixed   [][]byte `default:"[base64:AAA=,base64:!!,plain]"`
+	Invalid []byte   `default:"base64:AAA"`
+}
+
+func (s *DefaultsSuite) TestSetDefaultsBase64(c *C) {
+	foo := &ExampleBase64{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Mixed\[1\]: invalid base64 value "base64:!!": illegal base64 data at input byte 0\nInvalid: invalid base64 value "base64:AAA": illegal base64 data at input byte 0`)
+
+	c.Assert(string(foo.Key), Equals, "hello")
+	c.Assert(foo.Certs, DeepEquals, [][]byte{{0, 0}, {1, 2}})
+	c.Assert(foo.Mixed, DeepEquals, [][]byte{{0, 0}, nil, []byte("plain")})
+	c.Assert(foo.Invalid, IsNil)
+
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+}
+
+type ExampleDateLocation struct {
+	UTC     string `default:"backup-{{date:0,0,1@UTC}}.tar"`
+	Tokyo   string `default:"{{date:0,0,0@Asia/Tokyo}} {{time:0,0,0@Asia/Tokyo}}"`
+	Unknown string `default:"{{date:0,0,0@Mars/Olympus}}"`
+}
+
+func (s *DefaultsSuite) TestSetDefaultsDateLocation(c *C) {
+	foo := &ExampleDateLocation{}
+	SetDefaults(foo)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	c.Assert(err, IsNil)
+	c.Assert(foo.UTC, Equals, "backup-"+time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")+".tar")
+	c.Assert(foo.Tokyo, Equals, time.Now().In(tokyo).Format("2006-01-02 15:04:05"))
+	c.Assert(foo.Unknown, Equals, "{{date:0,0,0@Mars/Olympus}}")
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Unknown: invalid placeholder "{{date:0,0,0@Mars/Olympus}}": unknown location "Mars/Olympus"`)
+}
+
+func (s *DefaultsSuite) TestSetDefaultsTimeLayouts(c *C) {
+	for value, expected := range map[string]string{
+		"2020-08-10 12:55:10":               "2020-08-10T12:55:10Z",
+		"2020-08-10T12:55:10+02:00":         "2020-08-10T12:55:10+02:00",
+		"2020-08-10T12:55:10.5Z":            "2020-08-10T12:55:10.5Z",
+		"Mon Aug 10 12:55:10 2020":          "2020-08-10T12:55:10Z",
+		"Mon Aug 10 12:55:10 UTC 2020":      "2020-08-10T12:55:10Z",
+		"10 Aug 20 12:55 UTC":               "2020-08-10T12:55:00Z",
+		"Monday, 10-Aug-20 12:55:10 UTC":    "2020-08-10T12:55:10Z",
+		"3:04PM":                            "0000-01-01T15:04:00Z",
+		"10/08/2020 12:55 02/01/2006 15:04": "2020-08-10T12:55:00Z",
+		"Aug 10, 2020 Jan 2, 2006":          "2020-08-10T00:00:00Z",
+	} {
+		parsed, err := parseDateTime(value)
+		c.Assert(err, IsNil, Commentf("%s", value))
+		c.Assert(parsed.Format(time.RFC3339Nano), Equals, expected, Commentf("%s", value))
+	}
+
+	_, err := parseDateTime("someday")
+	c.Assert(err, ErrorMatches, "invalid string: someday")
+	_, err = parseDateTime("a b c d")
+	c.Assert(err, ErrorMatches, `parsing time "a b" as "c d": .*`)
+}
+
+type ExampleStartup struct {
+	BootedAt time.Time  `default:"startup"`
+	Pointer  *time.Time `default:"startup"`
+}
+
+func (s *DefaultsSuite) TestSetDefaultsStartup(c *C) {
+	foo := &ExampleStartup{}
+	SetDefaults(foo)
+	c.Assert(foo.BootedAt.Equal(startTime), Equals, true)
+	c.Assert(foo.BootedAt.After(time.Now()), Equals, false)
+	c.Assert(foo.Pointer.Equal(startTime), Equals, true)
+
+	time.Sleep(time.Millisecond)
+	bar := &ExampleStartup{}
+	SetDefaults(bar)
+	c.Assert(bar.BootedAt, Equals, foo.BootedAt)
+
+	c.Assert(CheckDefaults(bar), HasLen, 0)
+	c.Assert(TagFeatures(timeType, "startup"), DeepEquals, []Feature{FeaturePlaceholders})
+	c.Assert(TagFeatures(durationType, "startup"), IsNil)
+}
+
+type ExampleDays struct {
+	Today     time.Time  `default:"today"`
+	Yesterday time.Time  `default:"yesterday"`
+	Tomorrow  *time.Time `default:"tomorrow@UTC"`
+	Paris     time.Time  `default:"today@Europe/Paris"`
+}
+
+func (s *DefaultsSuite) TestSetDefaultsDayKeywords(c *C) {
+	foo := &ExampleDays{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	midnight := func(loc *time.Location, days int) time.Time {
+		year, month, day := time.Now().In(loc).Date()
+		return time.Date(year, month, day+days, 0, 0, 0, 0, loc)
+	}
+	c.Assert(foo.Today, DeepEquals, midnight(time.Local, 0))
+	c.Assert(foo.Yesterday, DeepEquals, midnight(time.Local, -1))
+	c.Assert(*foo.Tomorrow, DeepEquals, midnight(time.UTC, 1))
+	paris, err := time.LoadLocation("Europe/Paris")
+	c.Assert(err, IsNil)
+	c.Assert(foo.Paris.Equal(midnight(paris, 0)), Equals, true)
+
+	// Plans resolve them on every fill.
+	plan, err := Compile(reflect.TypeOf(ExampleDays{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleDays{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Today, DeepEquals, midnight(time.Local, 0))
+	c.Assert(isStaticTag("today"), Equals, false)
+
+	c.Assert(CheckDefaults(foo), HasLen, 0)
+	c.Assert(TagFeatures(timeType, "yesterday"), DeepEquals, []Feature{FeaturePlaceholders})
+
+	_, err = parseDateTime("today@Nowhere/Land")
+	c.Assert(err, ErrorMatches, `invalid location in "today@Nowhere/Land": .*`)
+	_, err = parseDateTime("today@")
+	c.Assert(err, ErrorMatches, `invalid string: today@, expected a location after @`)
+	_, err = parseDateTime("Today")
+	c.Assert(err, ErrorMatches, `invalid string: Today`)
+}
+
 func (s *DefaultsSuite) BenchmarkLogic(c *C) {
 	for i := 0; i < c.N; i++ {
 		foo := &ExampleBasic{}
@@ -0,0 +1,87 @@
+// Package godefaulttest provides test helpers for the defaults declared with
+// the godefault package, kept apart so that it doesn't import testing.
+package godefaulttest
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sonnt85/godefault"
+)
+
+// SnapshotEqual fails t when the godefault.Snapshot of v differs from the
+// golden file, listing the lines that changed. Set
+// GODEFAULT_UPDATE_SNAPSHOTS=1 to write the golden file instead, e.g. when it
+// is created or the change is intended:
+//
+//	func TestDefaults(t *testing.T) {
+//	    godefaulttest.SnapshotEqual(t, "testdata/config.golden", &Config{})
+//	}
+func SnapshotEqual(t testing.TB, golden string, v interface{}) {
+	t.Helper()
+
+	snapshot, err := godefault.Snapshot(v)
+	if err != nil {
+		t.Errorf("godefault: invalid defaults: %v", err)
+		return
+	}
+	if os.Getenv("GODEFAULT_UPDATE_SNAPSHOTS") != "" {
+		if err := ioutil.WriteFile(golden, []byte(snapshot), 0644); err != nil {
+			t.Errorf("godefault: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Errorf("godefault: %v, set GODEFAULT_UPDATE_SNAPSHOTS=1 to create it", err)
+		return
+	}
+	if diff := snapshotDiff(string(want), snapshot); diff != "" {
+		t.Errorf("godefault: defaults differ from %s (-want +got):\n%s", golden, diff)
+	}
+}
+
+// snapshotDiff lists the lines of want missing from got, prefixed with "-",
+// and those of got missing from want, prefixed with "+".
+func snapshotDiff(want, got string) string {
+	var diff []string
+	for _, line := range missingLines(want, got) {
+		diff = append(diff, "-"+line)
+	}
+	for _, line := range missingLines(got, want) {
+		diff = append(diff, "+"+line)
+	}
+
+	return strings.Join(diff, "\n")
+}
+
+// missingLines returns the lines of a that b doesn't have, as many times as
+// a has more of them.
+func missingLines(a, b string) []string {
+	others := make(map[string]int)
+	for _, line := range snapshotLines(b) {
+		others[line]++
+	}
+
+	var missing []string
+	for _, line := range snapshotLines(a) {
+		if others[line] > 0 {
+			others[line]--
+			continue
+		}
+		missing = append(missing, line)
+	}
+
+	return missing
+}
+
+func snapshotLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,80 @@
+package godefaulttest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type exampleConfig struct {
+	Name    string        `default:"app"`
+	Port    int           `default:"env:GODEFAULT_TEST_SNAPSHOT_PORT:8080"`
+	Timeout time.Duration `default:"90s"`
+}
+
+// recordingTB records the failures of SnapshotEqual.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func TestSnapshotEqual(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godefault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "config.golden")
+
+	tb := &recordingTB{TB: t}
+	SnapshotEqual(tb, golden, &exampleConfig{})
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], "GODEFAULT_UPDATE_SNAPSHOTS=1") {
+		t.Fatalf("missing golden file: %q", tb.failures)
+	}
+
+	os.Setenv("GODEFAULT_UPDATE_SNAPSHOTS", "1")
+	SnapshotEqual(t, golden, &exampleConfig{})
+	os.Unsetenv("GODEFAULT_UPDATE_SNAPSHOTS")
+	SnapshotEqual(t, golden, &exampleConfig{})
+
+	tb = &recordingTB{TB: t}
+	SnapshotEqual(tb, golden, &struct {
+		Name string `default:"app"`
+	}{})
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], "(-want +got):\n-Port (tag) = env:GODEFAULT_TEST_SNAPSHOT_PORT:8080\n-Timeout = 1m30s") ||
+		strings.Contains(tb.failures[0], "\n+") {
+		t.Fatalf("unexpected failures: %q", tb.failures)
+	}
+
+	tb = &recordingTB{TB: t}
+	SnapshotEqual(tb, golden, &struct {
+		Name string `default:"many"`
+		Port int    `default:"many"`
+	}{})
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], "invalid defaults") {
+		t.Fatalf("unexpected failures: %q", tb.failures)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	for _, test := range []struct {
+		want, got, diff string
+	}{
+		{"a = 1\n", "a = 1\n", ""},
+		{"a = 1\nb = 2\n", "a = 1\nb = 3\nc = 4\n", "-b = 2\n+b = 3\n+c = 4"},
+		{"", "a = 1\n", "+a = 1"},
+		{"a = 1\na = 1\n", "a = 1\n", "-a = 1"},
+	} {
+		if diff := snapshotDiff(test.want, test.got); diff != test.diff {
+			t.Errorf("snapshotDiff(%q, %q) = %q, want %q", test.want, test.got, diff, test.diff)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package godefault
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// hostPrefix introduces a default picked after the hostname of the machine,
+// from rules pattern=value matched in order, "*" matching any host:
+//
+//	Stage string `default:"host:^prod-.*=production,^stage-.*=staging,*=development"`
+//
+// Patterns are regexps, a literal comma in a pattern or a value is written
+// "|,", or the rule quoted like a slice element, see splitTagList. A
+// hostname matching no rule resolves to nothing, as does one that can't be
+// read, unless there is a "*" rule.
+const hostPrefix = "host:"
+
+// hostsPrefix introduces the envs| syntax of host: defaults, the hostname
+// taking the place of the variable:
+//
+//	DataDir string `default:"hosts|db-*,/data|~^gpu[0-9]+$,/scratch|*,/var/lib/app"`
+//
+// Entries are pattern,value, or pattern,,base64, escaped like envs| mappings
+// are. Patterns are globs, see path.Match, or regexps after a "~".
+const hostsPrefix = "hosts|"
+
+// hostname is os.Hostname, replaced by the tests.
+var hostname = os.Hostname
+
+type hostRule struct {
+	// pattern and glob are unset for the "*" rule.
+	pattern *regexp.Regexp
+	glob    string
+	value   string
+}
+
+// matches reports whether the rule matches the hostname name.
+func (rule hostRule) matches(name string) bool {
+	switch {
+	case rule.pattern != nil:
+		return rule.pattern.MatchString(name)
+	case rule.glob != "":
+		matched, _ := path.Match(rule.glob, name)
+		return matched
+	}
+
+	return true
+}
+
+// isHostRef reports whether value is a host: default, in either syntax.
+func isHostRef(value string) bool {
+	return strings.HasPrefix(value, hostPrefix) || strings.HasPrefix(value, hostsPrefix)
+}
+
+// parseHostRules parses the rules of a host: default.
+func parseHostRules(value string) ([]hostRule, error) {
+	if strings.HasPrefix(value, hostsPrefix) {
+		return parseHostsMapping(value)
+	}
+
+	elems, err := splitTagList(strings.TrimPrefix(value, hostPrefix), false, defaultSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host rules %q: %w", value, err)
+	}
+	rules := make([]hostRule, 0, len(elems))
+	for _, entry := range elems {
+		elem := entry.value
+		i := strings.IndexByte(elem, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid host rule %q, expected pattern=value", elem)
+		}
+
+		rule := hostRule{value: elem[i+1:]}
+		if pattern := elem[:i]; pattern != "*" {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid host rule %q: %w", elem, err)
+			}
+			rule.pattern = re
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// parseHostsMapping parses the entries of a hosts| default into rules.
+func parseHostsMapping(value string) ([]hostRule, error) {
+	parts := splitEnvsParts(strings.TrimPrefix(value, hostsPrefix))
+	if parts[0] == "" {
+		return nil, fmt.Errorf("invalid hosts value %q, expected %spattern,value|...", value, hostsPrefix)
+	}
+	entries, err := parseEnvsEntries(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]hostRule, 0, len(entries))
+	for _, entry := range entries {
+		rule := hostRule{value: entry.value}
+		switch pattern := entry.name; {
+		case pattern == "*":
+		case strings.HasPrefix(pattern, "~"):
+			if rule.pattern, err = regexp.Compile(pattern[1:]); err != nil {
+				return nil, fmt.Errorf("invalid hosts pattern %q: %w", pattern, err)
+			}
+		default:
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid hosts pattern %q: %w", pattern, err)
+			}
+			rule.glob = pattern
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// resolveHostRef returns the value of the first rule of the host: default
+// matching the hostname.
+func resolveHostRef(value string) (string, error) {
+	rules, err := parseHostRules(value)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := hostname()
+	for _, rule := range rules {
+		if (rule.pattern == nil && rule.glob == "") || (err == nil && rule.matches(name)) {
+			return rule.value, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,126 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	. "gopkg.in/check.v1"
+)
+
+type HostSuite struct{}
+
+var _ = Suite(&HostSuite{})
+
+type ExampleHost struct {
+	Stage    string `default:"host:^prod-.*=production,^stage-.*=staging,*=development"`
+	Replicas int    `default:"host:^prod-=3,*=1"`
+	Region   string `default:"host:-eu-=eu|,west,-us-=us"`
+	Env      string `default:"env:GODEFAULT_TEST_STAGE:host:^prod-=production"`
+}
+
+func withHostname(name string, err error) func() {
+	hostname = func() (string, error) { return name, err }
+	return func() { hostname = os.Hostname }
+}
+
+func (s *HostSuite) TestHost(c *C) {
+	defer withHostname("prod-eu-1", nil)()
+
+	foo := &ExampleHost{}
+	SetDefaults(foo)
+	c.Assert(foo.Stage, Equals, "production")
+	c.Assert(foo.Replicas, Equals, 3)
+	c.Assert(foo.Region, Equals, "eu,west")
+	c.Assert(foo.Env, Equals, "production")
+}
+
+func (s *HostSuite) TestHostFallback(c *C) {
+	defer withHostname("laptop", nil)()
+
+	foo := &ExampleHost{}
+	SetDefaults(foo)
+	c.Assert(foo.Stage, Equals, "development")
+	c.Assert(foo.Replicas, Equals, 1)
+	c.Assert(foo.Region, Equals, "")
+
+	defer withHostname("", errors.New("no hostname"))()
+	bar := &ExampleHost{}
+	SetDefaults(bar)
+	c.Assert(bar.Stage, Equals, "development")
+}
+
+func (s *HostSuite) TestHostReport(c *C) {
+	defer withHostname("stage-1", nil)()
+
+	report := &FillReport{}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), &ExampleHost{}), IsNil)
+	c.Assert(report.Fields[0].Source, Equals, SourceHost)
+	c.Assert(report.Fields[2].Source, Equals, SourceNone)
+}
+
+func (s *HostSuite) TestHostErrors(c *C) {
+	foo := &struct {
+		Rule    string `default:"host:prod"`
+		Pattern string `default:"host:[a-=x"`
+		Value   int    `default:"host:*=many"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Rule: invalid host rule "prod", expected pattern=value\n`+
+		`Pattern: invalid host rule "\[a-=x": error parsing regexp: .*\nValue: strconv.ParseInt: .*`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+
+	c.Assert(CheckDefaults(foo), HasLen, 3)
+	c.Assert(CheckDefaults(&ExampleHost{}), HasLen, 0)
+}
+
+type ExampleHosts struct {
+	DataDir string `default:"hosts|db-*,/data|~^gpu[0-9]+$,/scratch|*,/var/lib/app"`
+	NUMA    int    `default:"hosts|db-?-eu,2|db-*,1"`
+	Tags    string `default:"hosts|~^gpu[0-9]{1|,2}$,a|,b|*,,Yyxk"`
+	Bars    string `default:"hosts|db-1-eu,x||y"`
+}
+
+func (s *HostSuite) TestHosts(c *C) {
+	for name, expected := range map[string]ExampleHosts{
+		"db-1-eu": {"/data", 2, "c,d", "x|y"},
+		"db-12":   {"/data", 1, "c,d", ""},
+		"gpu7":    {"/scratch", 0, "a,b", ""},
+		"laptop":  {"/var/lib/app", 0, "c,d", ""},
+	} {
+		restore := withHostname(name, nil)
+		foo := &ExampleHosts{}
+		report := &FillReport{}
+		c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+		restore()
+		c.Assert(*foo, Equals, expected, Commentf(name))
+		c.Assert(report.Fields[0].Source, Equals, SourceHost)
+	}
+
+	defer withHostname("", errors.New("no hostname"))()
+	foo := &ExampleHosts{}
+	SetDefaults(foo)
+	c.Assert(foo.DataDir, Equals, "/var/lib/app")
+	c.Assert(foo.NUMA, Equals, 0)
+}
+
+func (s *HostSuite) TestHostsErrors(c *C) {
+	defer withHostname("db-1", nil)()
+
+	foo := &struct {
+		Empty  string `default:"hosts|"`
+		Entry  string `default:"hosts|db-*"`
+		Glob   string `default:"hosts|db-[,x"`
+		Regexp string `default:"hosts|~db-(,x"`
+		Value  int    `default:"hosts|db-*,many"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Empty: invalid hosts value "hosts\|", expected hosts\|pattern,value\|\.\.\.\n`+
+		`Entry: invalid envs entry "db-\*", expected name,value or name,,base64\n`+
+		`Glob: invalid hosts pattern "db-\[": syntax error in pattern\n`+
+		`Regexp: invalid hosts pattern "~db-\(": error parsing regexp: .*\n`+
+		`Value: strconv.ParseInt: .*`)
+
+	c.Assert(CheckDefaults(foo), HasLen, 5)
+	c.Assert(CheckDefaults(&ExampleHosts{}), HasLen, 0)
+}
@@ -0,0 +1,122 @@
+package godefault
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+)
+
+// HostPort is a network address split into its host and port, filled from a
+// host:port default:
+//
+//	Listen HostPort `default:"0.0.0.0:8080"`   // {0.0.0.0 8080}
+//	Peer   HostPort `default:"[::1]:9000"`     // {::1 9000}
+//	Web    HostPort `default:"localhost:http"` // {localhost 80}, WithPortLookup
+//
+// IPv6 hosts are written in brackets, which the Host field doesn't keep.
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// String joins h back into a host:port address, brackets included for IPv6
+// hosts.
+func (h HostPort) String() string {
+	return net.JoinHostPort(h.Host, strconv.Itoa(h.Port))
+}
+
+var hostPortType = reflect.TypeOf(HostPort{})
+
+// hostPortTag names the tag validating the host:port default of a string
+// field, the field left empty when the default isn't one:
+//
+//	Addr string `default:"localhost:8080" hostport:"true"`
+const hostPortTag = "hostport"
+
+// WithPortLookup accepts service names as the ports of host:port defaults,
+// such as localhost:http, resolved with net.LookupPort when the field is
+// filled. Without it, ports are numbers.
+func WithPortLookup() Option {
+	return func(f *Filler) {
+		f.portLookup = true
+	}
+}
+
+// parseHostPort splits a host:port address, resolving service name ports
+// with net.LookupPort when lookup is set. Ports are numbers from 0 to 65535.
+func parseHostPort(value string, lookup bool) (HostPort, error) {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return HostPort{}, err
+	}
+
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err == nil {
+		return HostPort{Host: host, Port: int(n)}, nil
+	}
+	if !lookup || port == "" || isDigits(port) {
+		return HostPort{}, fmt.Errorf("invalid port %q of %q, expected a number from 0 to 65535", port, value)
+	}
+	number, err := net.LookupPort("tcp", port)
+	if err != nil {
+		return HostPort{}, err
+	}
+
+	return HostPort{Host: host, Port: number}, nil
+}
+
+// checkHostPort validates a host:port default without resolving anything:
+// service name ports are only known at fill time, see WithPortLookup.
+func checkHostPort(value string) error {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil || port != "" && !isDigits(port) {
+		return err
+	}
+	_, err = parseHostPort(value, false)
+
+	return err
+}
+
+// isDigits reports whether s only holds ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fillHostPort fills a HostPort field from its host:port default.
+func fillHostPort(field *FieldData) {
+	if field.TagValue == "" || !field.Value.IsZero() {
+		return
+	}
+
+	address, err := parseHostPort(field.TagValue, field.owner().portLookup)
+	field.check(err)
+	if err == nil {
+		field.Value.Set(reflect.ValueOf(address))
+	}
+}
+
+// isHostPort reports whether the string default of sf is validated as a
+// host:port address, see hostPortTag.
+func isHostPort(sf reflect.StructField) bool {
+	hostPort, _ := strconv.ParseBool(sf.Tag.Get(hostPortTag))
+	return hostPort
+}
+
+// validateHostPort fails field, and empties it, when it is tagged hostport
+// and doesn't hold a host:port address.
+func validateHostPort(field *FieldData) {
+	if field.notTag || !isHostPort(field.Field) {
+		return
+	}
+	if _, err := parseHostPort(field.Value.String(), field.owner().portLookup); err != nil {
+		field.check(err)
+		field.Value.SetString("")
+	}
+}
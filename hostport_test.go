@@ -0,0 +1,69 @@
+package godefault
+
+import (
+	"context"
+
+	. "gopkg.in/check.v1"
+)
+
+type HostPortSuite struct{}
+
+var _ = Suite(&HostPortSuite{})
+
+type ExampleHostPort struct {
+	Listen  HostPort  `default:"0.0.0.0:8080"`
+	Peer    HostPort  `default:"[::1]:9000"`
+	Any     *HostPort `default:":443"`
+	Preset  HostPort  `default:"localhost:1"`
+	Addr    string    `default:"db.internal:5432" hostport:"true"`
+	Plain   string    `default:"not an address"`
+	Untyped HostPort
+}
+
+func (s *HostPortSuite) TestHostPort(c *C) {
+	foo := &ExampleHostPort{Preset: HostPort{"example.com", 2}}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Listen, Equals, HostPort{"0.0.0.0", 8080})
+	c.Assert(foo.Peer, Equals, HostPort{"::1", 9000})
+	c.Assert(foo.Peer.String(), Equals, "[::1]:9000")
+	c.Assert(*foo.Any, Equals, HostPort{"", 443})
+	c.Assert(foo.Preset, Equals, HostPort{"example.com", 2})
+	c.Assert(foo.Addr, Equals, "db.internal:5432")
+	c.Assert(foo.Plain, Equals, "not an address")
+	c.Assert(foo.Untyped, Equals, HostPort{})
+
+	c.Assert(CheckDefaults(&ExampleHostPort{}), HasLen, 0)
+}
+
+func (s *HostPortSuite) TestHostPortErrors(c *C) {
+	foo := &struct {
+		NoPort  HostPort `default:"localhost"`
+		Range   HostPort `default:"localhost:65536"`
+		Service HostPort `default:"localhost:http"`
+		Addr    string   `default:"::1:80" hostport:"true"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `NoPort: address localhost: missing port in address\n`+
+		`Range: invalid port "65536" of "localhost:65536", expected a number from 0 to 65535\n`+
+		`Service: invalid port "http" of "localhost:http", expected a number from 0 to 65535\n`+
+		`Addr: address ::1:80: too many colons in address`)
+	c.Assert(foo.NoPort, Equals, HostPort{})
+	c.Assert(foo.Addr, Equals, "")
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 3)
+	c.Assert(errs[2], ErrorMatches, `Addr: address ::1:80: too many colons in address`)
+}
+
+func (s *HostPortSuite) TestWithPortLookup(c *C) {
+	foo := &struct {
+		Web     HostPort `default:"localhost:http"`
+		Addr    string   `default:"localhost:https" hostport:"true"`
+		Unknown HostPort `default:"localhost:no-such-service"`
+	}{}
+	err := NewFiller(WithPortLookup()).FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Unknown: .*no-such-service.*`)
+	c.Assert(foo.Web, Equals, HostPort{"localhost", 80})
+	c.Assert(foo.Addr, Equals, "localhost:https")
+}
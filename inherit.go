@@ -0,0 +1,170 @@
+package godefault
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The json: default of a struct field overrides the defaults of the fields
+// its object names, the others keeping their own tags:
+//
+//	Primary Database `default:"json:{\"port\":5433,\"pool\":{\"size\":20}}"`
+//
+// Fields are named like encoding/json does, by their json tag or their Go
+// name, case-insensitively. Objects and arrays are given to structs, slices
+// and loosely typed fields as json: defaults, so that nested structs merge
+// the same way, and to typed maps as they are; any other value is parsed like
+// a default tag, strings unquoted. null leaves a field without a default.
+
+// isInheritedDefault reports whether the default tag of a struct field
+// overrides the defaults of its fields, a json: or a csv: one.
+func isInheritedDefault(value string) bool {
+	return isJSONValue(value) || isCSVDefault(value)
+}
+
+// inheritedDefaults decodes the json: or csv: default of a struct of type t
+// into the default tags it gives to the fields, by Go name.
+func inheritedDefaults(t reflect.Type, value string) (map[string]string, error) {
+	if isCSVDefault(value) {
+		return csvDefaults(t, value)
+	}
+	raws, err := parseJSONValue(value, reflect.TypeOf(map[string]json.RawMessage(nil)), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are taken in sorted order, so that the errors, and the key that
+	// wins among those naming the same field, don't depend on map order.
+	objects := raws.Interface().(map[string]json.RawMessage)
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := make(map[string]string, len(objects))
+	for _, key := range keys {
+		raw := objects[key]
+		sf, ok := jsonField(t, key)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s value: no field %q in %s", jsonPrefix, key, t)
+		}
+		tags[sf.Name] = inheritedTag(sf.Type, raw)
+	}
+
+	return tags, nil
+}
+
+// jsonField returns the exported field of t that encoding/json would decode
+// key into, preferring an exact match.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var folded *reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name, _, ok := externalName(sf, []string{"json"})
+		switch {
+		case !ok:
+		case name == key:
+			return sf, true
+		case folded == nil && strings.EqualFold(name, key):
+			folded = &sf
+		}
+	}
+	if folded != nil {
+		return *folded, true
+	}
+
+	return reflect.StructField{}, false
+}
+
+// inheritedTag returns the default tag a field of type t inherits from the
+// JSON value raw.
+func inheritedTag(t reflect.Type, raw json.RawMessage) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var s string
+	switch {
+	case string(raw) == "null":
+		return ""
+	case json.Unmarshal(raw, &s) == nil:
+		return s
+	case raw[0] != '{' && raw[0] != '[':
+		return string(raw)
+	case t.Kind() == reflect.Map && !isLooseType(t):
+		// The brace syntax accepts the quoted keys and values.
+		return string(raw)
+	}
+
+	return jsonPrefix + string(raw)
+}
+
+// inheritDefaults gives fields, those of the struct field parent, the
+// defaults set by the json: or csv: default of parent. It fails parent and
+// reports false when the default can't be decoded, the fields being left
+// alone then.
+func inheritDefaults(parent *FieldData, fields []*FieldData) bool {
+	if parent == nil || !isInheritedDefault(parent.TagValue) {
+		return true
+	}
+
+	tags, err := inheritedDefaults(parent.Value.Type(), parent.TagValue)
+	if err == nil && parent.owner().strict && isCSVDefault(parent.TagValue) {
+		err = checkCSVCount(parent.Value.Type(), parent.TagValue)
+	}
+	if err != nil {
+		parent.fail(parseErrorOf(err))
+		return false
+	}
+	for _, field := range fields {
+		if tag, ok := tags[field.Field.Name]; ok {
+			field.TagValue = tag
+		}
+	}
+
+	return true
+}
+
+// checkInheritedDefaults validates the defaults the json: or csv: default
+// value of a struct of type t gives to its fields.
+func checkInheritedDefaults(t reflect.Type, value string) error {
+	tags, err := inheritedDefaults(t, value)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := tags[sf.Name]
+		if !ok || tag == "" {
+			continue
+		}
+		if isStructModeType(sf.Type) {
+			err = checkStructMode(tag)
+			if err == nil && isInheritedDefault(tag) {
+				err = checkInheritedDefaults(sf.Type, tag)
+			}
+		} else {
+			var sep rune
+			sep, err = fieldSeparator(sf)
+			if err == nil {
+				err = checkTagValueSep(sf.Type, tag, sep)
+			}
+		}
+		if err != nil && isSecret(sf) {
+			err = fmt.Errorf("invalid %s value %s", sf.Type, secretValue)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", sf.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type InheritSuite struct{}
+
+var _ = Suite(&InheritSuite{})
+
+type ExampleDatabase struct {
+	Host    string        `default:"localhost"`
+	Port    int           `json:"port" default:"5432"`
+	Timeout time.Duration `default:"5s"`
+	Tags    []string      `default:"[a]"`
+	Labels  map[string]int
+	Pool    struct {
+		Size int `json:"size" default:"10"`
+		Idle int `default:"2"`
+	} `json:"pool"`
+}
+
+type ExampleInherit struct {
+	Primary ExampleDatabase
+	Replica ExampleDatabase `default:"json:{\"port\":5433,\"timeout\":\"1s\",\"Tags\":[\"b\",\"c\"],\"labels\":{\"x\":1},\"pool\":{\"size\":20},\"host\":null}"`
+}
+
+func (s *InheritSuite) TestInheritDefaults(c *C) {
+	foo := &ExampleInherit{}
+	c.Assert(NewFiller().FillContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Primary.Host, Equals, "localhost")
+	c.Assert(foo.Primary.Port, Equals, 5432)
+	c.Assert(foo.Primary.Pool.Size, Equals, 10)
+
+	c.Assert(foo.Replica.Host, Equals, "")
+	c.Assert(foo.Replica.Port, Equals, 5433)
+	c.Assert(foo.Replica.Timeout, Equals, time.Second)
+	c.Assert(foo.Replica.Tags, DeepEquals, []string{"b", "c"})
+	c.Assert(foo.Replica.Labels, DeepEquals, map[string]int{"x": 1})
+	c.Assert(foo.Replica.Pool.Size, Equals, 20)
+	c.Assert(foo.Replica.Pool.Idle, Equals, 2)
+}
+
+func (s *InheritSuite) TestInheritDefaultsPreset(c *C) {
+	foo := &ExampleInherit{}
+	foo.Replica.Port = 6000
+	c.Assert(NewFiller().FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Replica.Port, Equals, 6000)
+	c.Assert(foo.Replica.Pool.Size, Equals, 20)
+}
+
+func (s *InheritSuite) TestInheritDefaultsPlan(c *C) {
+	plan, err := NewFiller().Compile(reflect.TypeOf(ExampleInherit{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleInherit{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Replica.Port, Equals, 5433)
+	c.Assert(foo.Replica.Pool.Size, Equals, 20)
+}
+
+func (s *InheritSuite) TestInheritDefaultsApply(c *C) {
+	foo := &ExampleInherit{}
+	c.Assert(Apply(foo, MapLayer(map[string]string{"Replica.port": "7000"}), TagLayer()), IsNil)
+	c.Assert(foo.Primary.Port, Equals, 5432)
+	c.Assert(foo.Replica.Port, Equals, 7000)
+	c.Assert(foo.Replica.Timeout, Equals, time.Second)
+	c.Assert(foo.Replica.Pool.Size, Equals, 20)
+}
+
+func (s *InheritSuite) TestCheckInheritedDefaults(c *C) {
+	t := reflect.TypeOf(ExampleDatabase{})
+	for name, test := range map[string]struct {
+		tag      string
+		expected string
+	}{
+		"not an object": {`json:[1]`, `invalid json: value: json: cannot unmarshal array .*`},
+		"no JSON":       {`json:{`, `invalid json: value: unexpected EOF`},
+		"unknown field": {`json:{"Name":"x"}`, `invalid json: value: no field "Name" in godefault.ExampleDatabase`},
+		"invalid value": {`json:{"port":"x"}`, `Port: strconv.ParseInt: parsing "x": invalid syntax`},
+		"nested":        {`json:{"pool":{"Idle":"x"}}`, `Pool: Idle: strconv.ParseInt: parsing "x": invalid syntax`},
+	} {
+		c.Assert(checkInheritedDefaults(t, test.tag), ErrorMatches, test.expected, Commentf(name))
+	}
+	c.Assert(checkInheritedDefaults(t, `json:{"PORT":1,"pool":{"size":3},"Labels":{"a":2}}`), IsNil)
+}
+
+type ExampleInheritInvalid struct {
+	Replica ExampleDatabase `default:"json:{\"port\":\"x\",\"Pool\":{\"Idle\":3}}"`
+	Unknown ExampleDatabase `default:"json:{\"Name\":\"x\"}"`
+}
+
+func (s *InheritSuite) TestInheritDefaultsErrors(c *C) {
+	foo := &ExampleInheritInvalid{}
+	err := NewFiller().FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Replica.Port: strconv.ParseInt: parsing "x": invalid syntax\n`+
+		`Unknown: invalid json: value: no field "Name" in godefault.ExampleDatabase`)
+	c.Assert(foo.Replica.Pool.Idle, Equals, 3)
+	c.Assert(foo.Unknown.Port, Equals, 0)
+
+	errs := CheckDefaults(&ExampleInheritInvalid{})
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `Replica: Port: strconv.ParseInt: parsing "x": invalid syntax`)
+	c.Assert(CheckDefaults(&ExampleInherit{}), HasLen, 0)
+}
@@ -0,0 +1,363 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldInfo describes a field reached while walking a struct type
+// statically, that is without a value to fill, see ListDefaultFields.
+type FieldInfo struct {
+	// Path is the dotted Go path of the field, e.g. "Server.Peers[].Port".
+	// Fields of struct slice elements follow "[]", those of struct map values
+	// "{}".
+	Path  string
+	Field reflect.StructField
+	Type  reflect.Type
+	// Index is the index sequence of the field for reflect's FieldByIndex,
+	// from the root struct or, for fields of slice and map elements, from the
+	// element. It goes through pointers, which may be nil in a value.
+	Index  []int
+	Tag    string
+	HasTag bool
+	// Transform is the integer transform ending the tag, e.g. "|+1", see
+	// splitIntTransform.
+	Transform string
+	// Phase is the step of the fill in which the tag is resolved.
+	Phase Phase
+	// Features are the features the tag uses, the seed of its choose:
+	// default included, see TagFeatures.
+	Features []Feature
+	Required bool
+	Secret   bool
+	// Names are the external names of the field and its parents, see
+	// externalName, the name of a struct slice being followed by "[]" and
+	// the one of a struct map by "{}".
+	Names []string
+	// Excluded is set when the field or a parent is excluded from config
+	// files by its external name tag.
+	Excluded bool
+	// InElement is set for fields of struct slice elements and struct map
+	// values.
+	InElement bool
+}
+
+// Key returns the dotted external path of the field, e.g. "server.peers[].port".
+func (tf *FieldInfo) Key() string {
+	return strings.Join(tf.Names, ".")
+}
+
+// walkType visits the settable fields of the struct type t in declaration
+// order. It descends the same way the default filler does: into nested
+// structs (except time.Time), pointers to structs at any depth and the
+// element type of struct slices, which is rendered as "Parent[].Field", after
+// visiting the slice when it has a tag. Maps of structs are visited, then
+// their value type is, as "Parent{}.Field". nameTags are the external name
+// tags, by priority.
+func walkType(t reflect.Type, tagName string, nameTags []string, visit func(tf *FieldInfo)) {
+	w := &typeWalker{tagName: tagName, nameTags: nameTags, visiting: make(map[reflect.Type]bool), visit: visit}
+	w.walk(t, &FieldInfo{})
+}
+
+// tagFiller returns the Filler describing the tag the functions taking
+// tagNames read, with the default name tags. It has no fillers, it is only
+// meant for walkType.
+func tagFiller(tagNames []string) *Filler {
+	return &Filler{Tag: tagNameOf(tagNames), nameTags: defaultNameTags}
+}
+
+// walkType is walkType with the tag and the name tags of f, leaving out the
+// fields f skips, see WithHonorJSONDash.
+func (f *Filler) walkType(t reflect.Type, visit func(tf *FieldInfo)) {
+	w := &typeWalker{tagName: f.Tag, nameTags: f.nameTags, visiting: make(map[reflect.Type]bool), visit: visit, filler: f}
+	w.walk(t, &FieldInfo{})
+}
+
+type typeWalker struct {
+	tagName  string
+	nameTags []string
+	visiting map[reflect.Type]bool
+	visit    func(tf *FieldInfo)
+	// structs visits the tagged struct fields too, before their fields.
+	structs bool
+	// pointers visits the tagged pointer to struct fields too, before their
+	// fields.
+	pointers bool
+	// filler, when set, leaves out the fields it skips.
+	filler *Filler
+}
+
+func (w *typeWalker) walk(t reflect.Type, parent *FieldInfo) {
+	if w.visiting[t] {
+		return
+	}
+	w.visiting[t] = true
+	defer delete(w.visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || isMetaField(sf) || w.filler != nil && (w.filler.skipSource(sf) != "" || !w.filler.marks(sf)) {
+			continue
+		}
+
+		tag, hasTag := sf.Tag.Lookup(w.tagName)
+		if tag == "-" {
+			continue
+		}
+
+		tf := &FieldInfo{
+			Path:      parent.Path + sf.Name,
+			Field:     sf,
+			Type:      sf.Type,
+			Index:     appendIndex(parent.Index, i),
+			Tag:       tag,
+			HasTag:    hasTag,
+			Phase:     TagPhase(tag),
+			Features:  fieldFeatures(sf, tag),
+			Required:  isRequired(sf),
+			Secret:    isSecret(sf),
+			Names:     parent.Names,
+			Excluded:  parent.Excluded,
+			InElement: parent.InElement,
+		}
+		if isIntegerType(sf.Type) {
+			_, tf.Transform = splitIntTransform(tag)
+		}
+		name, inline, ok := externalName(sf, w.nameTags)
+		if !ok {
+			tf.Excluded = true
+		}
+
+		elem := sf.Type
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		switch {
+		case w.filler.isStructType(elem) && (sf.Type.Kind() == reflect.Struct || sf.Type.Kind() == reflect.Ptr):
+			if !inline {
+				tf.Names = appendName(tf.Names, name)
+			}
+			if hasTag && (w.structs && sf.Type.Kind() == reflect.Struct || w.pointers && sf.Type.Kind() == reflect.Ptr) {
+				w.visit(tf)
+			}
+			tf.Path += "."
+			w.walk(elem, tf)
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Struct:
+			elems := tf.element(name, "[]")
+			// Tagged ones are made by their default, see makePrefix.
+			if hasTag {
+				tf.Names = appendName(tf.Names, name)
+				w.visit(tf)
+			}
+			w.walk(sf.Type.Elem(), elems)
+		case sf.Type.Kind() == reflect.Map && w.filler.isStructType(sf.Type.Elem()):
+			values := tf.element(name, "{}")
+			tf.Names = appendName(tf.Names, name)
+			w.visit(tf)
+			w.walk(sf.Type.Elem(), values)
+		default:
+			tf.Names = appendName(tf.Names, name)
+			w.visit(tf)
+		}
+	}
+}
+
+// element returns the parent of the fields of the elements of the slice or
+// map tf, named after name followed by suffix.
+func (tf *FieldInfo) element(name, suffix string) *FieldInfo {
+	return &FieldInfo{
+		Path:      tf.Path + suffix + ".",
+		Names:     appendName(tf.Names, name+suffix),
+		Excluded:  tf.Excluded,
+		InElement: true,
+	}
+}
+
+// appendName appends to a copy of names, which is shared between siblings.
+func appendName(names []string, name string) []string {
+	return append(names[:len(names):len(names)], name)
+}
+
+// appendIndex is appendName for index sequences.
+func appendIndex(index []int, i int) []int {
+	return append(index[:len(index):len(index)], i)
+}
+
+// isStructType reports whether the filler descends into values of type t
+// rather than filling them from a tag.
+func isStructType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !opaqueTypes[t]
+}
+
+// structTypeOf returns the struct type behind v, which may be a struct value,
+// a pointer to a struct or a reflect.Type of either.
+func structTypeOf(v interface{}) (reflect.Type, error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, invalidTargetf("godefault: expected a struct or a pointer to a struct, got %v", t)
+	}
+
+	return t, nil
+}
+
+func tagNameOf(tagNames []string) string {
+	if len(tagNames) != 0 {
+		return tagNames[0]
+	}
+
+	return "default"
+}
+
+// CheckDefaults validates every default tag of the struct behind v without
+// filling anything, using the same parsers the default filler applies. It
+// returns one error per invalid tag, prefixed with the field path, the min
+// and max tags of durations included. Values resolved at fill time are
+// checked for their syntax: envs| mappings, date placeholders and the
+// fallback of env: references.
+//
+// Usage
+//
+//	func TestConfigDefaults(t *testing.T) {
+//	    for _, err := range CheckDefaults(&Config{}) {
+//	        t.Error(err)
+//	    }
+//	}
+func CheckDefaults(v interface{}, tagNames ...string) []error {
+	return checkDefaults(v, tagNameOf(tagNames), nil)
+}
+
+// CheckDefaults validates the default tags of the struct behind v like the
+// CheckDefaults function does, reading the tag of f. The tags using a feature
+// f doesn't allow are errors too, see Restrict, and so are, WithStrict, the
+// csv: defaults whose values don't match the fields of their struct. The
+// fields f skips aren't checked.
+func (f *Filler) CheckDefaults(v interface{}) []error {
+	return checkDefaults(v, f.Tag, f)
+}
+
+// checkDefaults implements CheckDefaults for the tag tagName, checking the
+// features of the tags against the restriction of f when it isn't nil.
+func checkDefaults(v interface{}, tagName string, f *Filler) []error {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	w := &typeWalker{tagName: tagName, nameTags: defaultNameTags, visiting: make(map[reflect.Type]bool), structs: true, filler: f}
+	w.visit = func(tf *FieldInfo) {
+		if !tf.HasTag || tf.Tag == "" {
+			return
+		}
+		tag := tf.Tag
+		if f != nil && f.compat != "" {
+			tag = compatTag(tf.Field.Type, tag, f.compat)
+		}
+		tag, err := sizeUnitValue(tf.Field, tag)
+		if err == nil && f != nil && f.bareDurationUnit != 0 {
+			tag, err = bareDurationTag(f, tf.Field, tag)
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Path: tf.Path, Tag: redact(tf.Field, tf.Tag), Err: err})
+			return
+		}
+		if tag != tf.Tag {
+			rewritten := *tf
+			rewritten.Tag = tag
+			tf = &rewritten
+		}
+		if f != nil {
+			if err := f.checkFeatures(tf.Field, tf.Tag); err != nil {
+				errs = append(errs, &FieldError{Path: tf.Path, Tag: redact(tf.Field, tf.Tag), Err: err})
+				return
+			}
+		}
+		if err, ok := checkDecoded(tf.Field.Type, tf.Tag); ok {
+			if err != nil {
+				errs = append(errs, &FieldError{Path: tf.Path, Tag: redact(tf.Field, tf.Tag), Err: err})
+			}
+			return
+		}
+		if err, ok := f.checkOpaque(tf.Field.Type, tf.Tag); ok {
+			if err != nil {
+				errs = append(errs, &FieldError{Path: tf.Path, Tag: redact(tf.Field, tf.Tag), Err: err})
+			}
+			return
+		}
+		if isStructModeType(tf.Field.Type) {
+			err := checkStructMode(tf.Tag)
+			if err == nil && isInheritedDefault(tf.Tag) {
+				err = checkInheritedDefaults(tf.Field.Type, tf.Tag)
+			}
+			if err == nil && f != nil && f.strict && isCSVDefault(tf.Tag) {
+				err = checkCSVCount(tf.Field.Type, tf.Tag)
+			}
+			if err != nil {
+				errs = append(errs, &FieldError{Path: tf.Path, Tag: redact(tf.Field, tf.Tag), Err: err})
+			}
+			return
+		}
+		sep, err := fieldSeparator(tf.Field)
+		if err == nil {
+			err = checkTagValueSep(tf.Field.Type, tf.Tag, sep)
+		}
+		if err == nil {
+			err = checkUnique(tf.Field)
+		}
+		if err == nil && isHostPort(tf.Field) && isStaticTag(tf.Tag) {
+			err = checkHostPort(tf.Tag)
+		}
+		if err == nil && isDurationType(tf.Field.Type) {
+			err = checkDurationBounds(tf.Field, tf.Tag)
+		}
+		if err == nil && isFloatType(tf.Field.Type) {
+			err = checkFloatBounds(tf.Field, tf.Tag)
+		}
+		if err != nil {
+			// The parse errors quote the value, which must not leak.
+			if isSecret(tf.Field) {
+				err = fmt.Errorf("invalid %s value %s", tf.Field.Type, secretValue)
+			}
+			errs = append(errs, &FieldError{Path: tf.Path, Tag: redact(tf.Field, tf.Tag), Err: err})
+		}
+	}
+	w.walk(t, &FieldInfo{})
+
+	return errs
+}
+
+// ListDefaultFields describes the fields of the struct behind v that carry a
+// default tag or are required, in declaration order, for tools such as
+// documentation generators. Nested structs and pointers to structs are
+// described without allocating anything, and the fields of struct slice
+// elements and struct map values once per type. The tag and name tags are
+// those set by opts, see WithTag and WithNameTags, and so are the fields left
+// out, see WithHonorJSONDash.
+//
+//	fields, _ := ListDefaultFields(&Config{})
+//	for _, field := range fields {
+//	    fmt.Println(field.Path, field.Type, field.Tag)
+//	}
+func ListDefaultFields(v interface{}, opts ...Option) ([]FieldInfo, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	filler := NewFiller(opts...)
+	var fields []FieldInfo
+	filler.walkType(t, func(tf *FieldInfo) {
+		if tf.HasTag || tf.Required {
+			fields = append(fields, *tf)
+		}
+	})
+
+	return fields, nil
+}
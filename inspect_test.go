@@ -0,0 +1,141 @@
+package godefault
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type InspectSuite struct{}
+
+var _ = Suite(&InspectSuite{})
+
+type ExampleInvalid struct {
+	Integer8 int8           `default:"300"`
+	Unsigned uint           `default:"-1"`
+	Float32  float32        `default:"1e40"`
+	Bool     bool           `default:"yes"`
+	Duration time.Duration  `default:"1 second"`
+	Time     time.Time      `default:"someday"`
+	Slice    []int          `default:"1,2"`
+	Elements []int          `default:"[1,a]"`
+	MapKey   map[int]bool   `default:"{a:true}"`
+	MapEntry map[string]int `default:"{a}"`
+	Nested   struct {
+		Integer int `default:"x"`
+	}
+	Children []struct {
+		Age int `default:"ten"`
+	}
+	Env     string `default:"envs|MODE|dev,1|prod,2"`
+	Envs    string `default:"envs|MODE"`
+	Base64  string `default:"envs|MODE|dev,,!!|prod,,YQ=="`
+	Entry   string `default:"envs|MODE|dev,1|prod,2,3"`
+	Date    string `default:"{{date:1,x,0}}"`
+	Unknown string `default:"on {{week:1,0,0}}"`
+	Literal string `default:"{{ .Name }} {{time:1,-5,10}}"`
+	Ignored int    `default:"-"`
+	Empty   int    `default:""`
+	private int    `default:"x"`
+}
+
+type ExampleFields struct {
+	Port    int    `yaml:"port" default:"env:PORT:8080|+1"`
+	Token   string `secret:"true" required:"true"`
+	Skipped string
+	Server  *struct {
+		Host string `yaml:"host" default:"localhost"`
+	} `yaml:"server"`
+	Peers []struct {
+		Weight int `default:"1"`
+	}
+	Zones map[string]struct {
+		Replicas int `default:"3"`
+	} `default:"{eu:,us:}"`
+}
+
+func (s *InspectSuite) TestListDefaultFields(c *C) {
+	fields, err := ListDefaultFields(&ExampleFields{})
+	c.Assert(err, IsNil)
+
+	var paths, keys []string
+	for _, field := range fields {
+		paths = append(paths, field.Path)
+		keys = append(keys, field.Key())
+	}
+	c.Assert(paths, DeepEquals, []string{"Port", "Token", "Server.Host", "Peers[].Weight", "Zones", "Zones{}.Replicas"})
+	c.Assert(keys, DeepEquals, []string{"port", "Token", "server.host", "Peers[].Weight", "Zones", "Zones{}.Replicas"})
+
+	c.Assert(fields[0].Type, Equals, reflect.TypeOf(0))
+	c.Assert(fields[0].Tag, Equals, "env:PORT:8080|+1")
+	c.Assert(fields[0].Transform, Equals, "|+1")
+	c.Assert(fields[1].HasTag, Equals, false)
+	c.Assert(fields[1].Required, Equals, true)
+	c.Assert(fields[1].Secret, Equals, true)
+	c.Assert(fields[2].Index, DeepEquals, []int{3, 0})
+	c.Assert(fields[3].Index, DeepEquals, []int{0})
+	c.Assert(fields[3].InElement, Equals, true)
+	c.Assert(fields[5].InElement, Equals, true)
+
+	value := reflect.ValueOf(ExampleFields{Port: 1})
+	c.Assert(value.FieldByIndex(fields[0].Index).Interface(), Equals, 1)
+}
+
+func (s *InspectSuite) TestListDefaultFieldsTag(c *C) {
+	fields, err := ListDefaultFields(ExampleFields{}, WithTag("yaml"))
+	c.Assert(err, IsNil)
+	c.Assert(fields, HasLen, 3)
+	c.Assert(fields[0].Tag, Equals, "port")
+
+	_, err = ListDefaultFields(42)
+	c.Assert(err, NotNil)
+}
+
+func (s *InspectSuite) TestCheckDefaultsValid(c *C) {
+	c.Assert(CheckDefaults(&ExampleBasic{}), HasLen, 0)
+	c.Assert(CheckDefaults(ExampleNested{}), HasLen, 0)
+}
+
+func (s *InspectSuite) TestCheckDefaultsInvalid(c *C) {
+	errs := CheckDefaults(&ExampleInvalid{})
+
+	var paths []string
+	for _, err := range errs {
+		paths = append(paths, strings.SplitN(err.Error(), ":", 2)[0])
+	}
+	c.Assert(paths, DeepEquals, []string{
+		"Integer8", "Unsigned", "Float32", "Bool", "Duration", "Time",
+		"Slice", "Elements", "MapKey", "MapEntry", "Nested.Integer", "Children[].Age",
+		"Envs", "Base64", "Entry", "Date", "Unknown",
+	})
+	c.Assert(errs[12], ErrorMatches, `Envs: invalid envs value "envs\|MODE", expected envs\|\[KEY\|\]name,value\|...`)
+	c.Assert(errs[13], ErrorMatches, `Base64: invalid envs entry "dev,,!!": illegal base64 data at input byte 0`)
+	c.Assert(errs[14], ErrorMatches, `Entry: invalid envs entry "prod,2,3", expected name,value or name,,base64`)
+	c.Assert(errs[15], ErrorMatches, `Date: invalid placeholder "{{date:1,x,0}}", expected {{date:Y,M,D\[@Location\]}} or {{time:h,m,s\[@Location\]}}`)
+}
+
+func (s *InspectSuite) TestCheckDefaultsEnvs(c *C) {
+	c.Assert(checkEnvsValue("envs|EnvType1|dev,MTE=|prod,12|stg,aBc|,||DeFgHiJkLmNoP"), IsNil)
+	c.Assert(checkEnvsValue("envs|dev,11|prod,12|stg,13"), IsNil)
+	c.Assert(checkEnvsValue("envs|KEY|dev,,MTE=|prod,x"), IsNil)
+}
+
+func (s *InspectSuite) TestCheckDefaultsTagName(c *C) {
+	errs := CheckDefaults(&struct {
+		Foo int `foo:"bar" default:"1"`
+	}{}, "foo")
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Foo: strconv.ParseInt: parsing "bar": invalid syntax`)
+}
+
+func (s *InspectSuite) TestCheckDefaultsSecret(c *C) {
+	errs := CheckDefaults(&ExampleSecret{})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Port: invalid int value \*\*\*\*`)
+}
+
+func (s *InspectSuite) TestCheckDefaultsNotStruct(c *C) {
+	c.Assert(CheckDefaults(42), HasLen, 1)
+}
@@ -0,0 +1,150 @@
+package godefault
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonPrefix introduces the default of a loosely typed field, a
+// map[string]interface{} or a []interface{}, which has no element type to
+// parse the usual syntax with. The rest of the tag is decoded with
+// encoding/json:
+//
+//	Extra map[string]interface{} `default:"json:{\"retries\":3,\"tags\":[\"a\"]}"`
+//
+// Numbers decode as float64, or as json.Number with WithJSONNumber.
+const jsonPrefix = "json:"
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// isLooseType reports whether t is a map with string keys or a slice, of
+// interface{} values, see jsonPrefix.
+func isLooseType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem() == interfaceType
+	case reflect.Slice:
+		return t.Elem() == interfaceType
+	}
+
+	return false
+}
+
+// parseJSONValue decodes the json: default value into a new value of type t.
+func parseJSONValue(value string, t reflect.Type, useNumber bool) (reflect.Value, error) {
+	if !strings.HasPrefix(value, jsonPrefix) {
+		return reflect.Value{}, fmt.Errorf("invalid %s value %q, expected %s followed by JSON", t, value, jsonPrefix)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(value[len(jsonPrefix):]))
+	if useNumber {
+		decoder.UseNumber()
+	}
+	result := reflect.New(t)
+	if err := decoder.Decode(result.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid %s value: %w", jsonPrefix, err)
+	}
+	if decoder.More() {
+		return reflect.Value{}, fmt.Errorf("invalid %s value: data after the JSON value", jsonPrefix)
+	}
+
+	return result.Elem(), nil
+}
+
+// fillJSON sets the loosely typed field from its json: default.
+func fillJSON(field *FieldData) {
+	if field.TagValue == "" {
+		return
+	}
+
+	value, err := parseJSONValue(field.TagValue, field.Value.Type(), field.owner().jsonNumber)
+	field.check(err)
+	if err == nil {
+		field.Value.Set(value)
+	}
+}
+
+// isJSONValue reports whether value is a json: default, which typed slices
+// accept too:
+//
+//	Names   []string        `default:"json:[\"a,b\",\"c|d\"]"`
+//	Delays  []time.Duration `default:"json:[\"1s\",\"2s\"]"`
+//	Servers []Server        `default:"json:[{\"addr\":\"a:80\"},{}]"`
+func isJSONValue(value string) bool {
+	return strings.HasPrefix(value, jsonPrefix)
+}
+
+// jsonElements decodes the json: default of a slice into its elements, raw
+// values to parse like the elements of the bracket syntax: strings unquoted,
+// null as an empty value and anything else as its JSON text.
+func jsonElements(value string) ([]string, error) {
+	raws, err := parseJSONValue(value, reflect.TypeOf([]json.RawMessage(nil)), false)
+	if err != nil {
+		return nil, err
+	}
+
+	elems := make([]string, raws.Len())
+	for i, raw := range raws.Interface().([]json.RawMessage) {
+		var s string
+		switch {
+		case json.Unmarshal(raw, &s) == nil:
+			elems[i] = s
+		case string(raw) == "null":
+		default:
+			elems[i] = string(raw)
+		}
+	}
+
+	return elems, nil
+}
+
+// fillJSONSlice sets the slice field from its json: default. Struct elements
+// are decoded by encoding/json, after their json tags, the fields left zero
+// being filled from their defaults by the slice filler; other elements are
+// filled from their raw value, see jsonElements.
+func fillJSONSlice(field *FieldData) {
+	t := field.Value.Type()
+	if t.Elem().Kind() == reflect.Struct {
+		value, err := parseJSONValue(field.TagValue, t, field.owner().jsonNumber)
+		field.check(err)
+		if err == nil {
+			field.Value.Set(value)
+		}
+		return
+	}
+
+	elems, err := jsonElements(field.TagValue)
+	field.check(err)
+	if err != nil {
+		return
+	}
+	result := reflect.MakeSlice(t, len(elems), len(elems))
+	for i, elem := range elems {
+		result.Index(i).Set(fillElement(field, t.Elem(), elem, strconv.Itoa(i)))
+	}
+	field.Value.Set(result)
+	dedupSlice(field)
+}
+
+// checkJSONSlice validates the json: default of a slice of type t.
+func checkJSONSlice(t reflect.Type, value string) error {
+	if t.Elem().Kind() == reflect.Struct {
+		_, err := parseJSONValue(value, t, false)
+		return err
+	}
+
+	elems, err := jsonElements(value)
+	if err != nil {
+		return err
+	}
+	for _, elem := range elems {
+		if err := checkTagValue(t.Elem(), elem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
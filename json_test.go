@@ -0,0 +1,129 @@
+package godefault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type JSONSuite struct{}
+
+var _ = Suite(&JSONSuite{})
+
+type ExampleJSON struct {
+	Extra    map[string]interface{} `default:"json:{\"retries\":3,\"tags\":[\"a\"]}"`
+	List     []interface{}          `default:"json:[1,\"two\",null]"`
+	Preset   map[string]interface{} `default:"json:{\"a\":1}"`
+	Untagged map[string]interface{}
+	Empty    []interface{} `default:""`
+}
+
+func (s *JSONSuite) TestJSON(c *C) {
+	foo := &ExampleJSON{Preset: map[string]interface{}{"b": 2}}
+	SetDefaults(foo)
+
+	c.Assert(foo.Extra, DeepEquals, map[string]interface{}{"retries": 3.0, "tags": []interface{}{"a"}})
+	c.Assert(foo.List, DeepEquals, []interface{}{1.0, "two", nil})
+	c.Assert(foo.Preset, DeepEquals, map[string]interface{}{"b": 2})
+	c.Assert(foo.Untagged, IsNil)
+	c.Assert(foo.Empty, IsNil)
+}
+
+func (s *JSONSuite) TestJSONNumber(c *C) {
+	foo := &ExampleJSON{}
+	NewFiller(WithJSONNumber()).Fill(foo)
+
+	c.Assert(foo.Extra["retries"], Equals, json.Number("3"))
+	c.Assert(foo.List[0], Equals, json.Number("1"))
+}
+
+func (s *JSONSuite) TestJSONErrors(c *C) {
+	foo := &struct {
+		Literal map[string]interface{} `default:"{a:1}"`
+		Invalid []interface{}          `default:"json:[1,"`
+		Trailer []interface{}          `default:"json:[1] [2]"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Literal: invalid map\[string\]interface {} value "{a:1}", expected json: followed by JSON\n`+
+		`Invalid: invalid json: value: unexpected EOF\nTrailer: invalid json: value: data after the JSON value`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(foo.Literal, IsNil)
+
+	c.Assert(CheckDefaults(foo), HasLen, 3)
+	c.Assert(CheckDefaults(&ExampleJSON{}), HasLen, 0)
+}
+
+type ExampleJSONServer struct {
+	Addr    string        `json:"addr" default:"localhost:80"`
+	Timeout time.Duration `json:"-" default:"5s"`
+	Weight  int           `json:"weight" default:"1"`
+}
+
+type ExampleJSONSlices struct {
+	Names   []string             `default:"json:[\"a,b\",\"c|d\",\"\"]"`
+	Ints    []int                `default:"json:[1,2,3]"`
+	Floats  []float64            `default:"json:[1.5,2]"`
+	Delays  []time.Duration      `default:"json:[\"1s\",\"2m\"]"`
+	Nested  [][]string           `default:"json:[[\"a,b\"],[]]"`
+	Servers []ExampleJSONServer  `default:"json:[{\"addr\":\"a:80\"},{\"weight\":3}]"`
+	Pointer *[]ExampleJSONServer `default:"json:[{}]"`
+	Set     []string             `default:"json:[\"a\",\"a\"]" unique:"true"`
+}
+
+func (s *JSONSuite) TestJSONSlices(c *C) {
+	foo := &ExampleJSONSlices{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Names, DeepEquals, []string{"a,b", "c|d", ""})
+	c.Assert(foo.Ints, DeepEquals, []int{1, 2, 3})
+	c.Assert(foo.Floats, DeepEquals, []float64{1.5, 2})
+	c.Assert(foo.Delays, DeepEquals, []time.Duration{time.Second, 2 * time.Minute})
+	c.Assert(foo.Nested, DeepEquals, [][]string{{"a,b"}, {}})
+	c.Assert(foo.Servers, DeepEquals, []ExampleJSONServer{
+		{Addr: "a:80", Timeout: 5 * time.Second, Weight: 1},
+		{Addr: "localhost:80", Timeout: 5 * time.Second, Weight: 3},
+	})
+	c.Assert(*foo.Pointer, DeepEquals, []ExampleJSONServer{{Addr: "localhost:80", Timeout: 5 * time.Second, Weight: 1}})
+	c.Assert(foo.Set, DeepEquals, []string{"a"})
+
+	c.Assert(CheckDefaults(foo), HasLen, 0)
+}
+
+func (s *JSONSuite) TestJSONSlicesPlan(c *C) {
+	expected := &ExampleJSONSlices{}
+	SetDefaults(expected)
+
+	plan, err := Compile(reflect.TypeOf(ExampleJSONSlices{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleJSONSlices{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo, DeepEquals, expected)
+
+	// Preset slices are kept.
+	bar := &ExampleJSONSlices{Servers: []ExampleJSONServer{{Weight: 7}}}
+	SetDefaults(bar)
+	c.Assert(bar.Servers, DeepEquals, []ExampleJSONServer{{Addr: "localhost:80", Timeout: 5 * time.Second, Weight: 7}})
+}
+
+func (s *JSONSuite) TestJSONSlicesErrors(c *C) {
+	foo := &struct {
+		Names   []string            `default:"json:[\"a\""`
+		Ints    []int               `default:"json:[\"x\"]"`
+		Servers []ExampleJSONServer `default:"json:[{\"weight\":\"x\"}]"`
+		Object  []string            `default:"json:{}"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Names: invalid json: value: unexpected EOF\n`+
+		`Ints\[0\]: strconv.ParseInt: parsing "x": invalid syntax\n`+
+		`Servers: invalid json: value: json: cannot unmarshal string .*\n`+
+		`Object: invalid json: value: json: cannot unmarshal object .*`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(foo.Names, IsNil)
+	c.Assert(foo.Servers, HasLen, 0)
+
+	c.Assert(CheckDefaults(foo), HasLen, 4)
+}
@@ -0,0 +1,88 @@
+package godefault
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// jwtClaimPrefix introduces a default read from a claim of the JSON Web Token
+// held by an environment variable, e.g. to default a user or tenant ID from
+// an ambient token:
+//
+//	Subject string `default:"jwtclaim:TOKEN:sub"`
+//	Tenant  string `default:"jwtclaim:TOKEN:https://example.com/tenant"`
+//
+// The payload is only base64-decoded: the signature is NOT verified, so the
+// claims must not be trusted for anything but a default. String claims are
+// used as they are, the other ones as their JSON text. An unset variable or a
+// missing claim resolves to nothing.
+const jwtClaimPrefix = "jwtclaim:"
+
+func isJWTClaimRef(value string) bool {
+	return strings.HasPrefix(value, jwtClaimPrefix)
+}
+
+// parseJWTClaimRef splits "jwtclaim:KEY:claim" into its parts, the claim
+// being everything after the variable name.
+func parseJWTClaimRef(value string) (key, claim string, err error) {
+	ref := strings.TrimPrefix(value, jwtClaimPrefix)
+	i := strings.IndexByte(ref, ':')
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", fmt.Errorf("invalid jwtclaim reference %q, expected %sKEY:claim", value, jwtClaimPrefix)
+	}
+
+	return ref[:i], ref[i+1:], nil
+}
+
+// resolveJWTClaimRef returns the claim named by the jwtclaim: reference
+// value, and whether the variable, read with lookup, was set. A variable set
+// to an empty value, when lookup counts it as set, has no claims.
+func resolveJWTClaimRef(value string, lookup func(key string) (string, bool)) (string, bool, error) {
+	key, claim, err := parseJWTClaimRef(value)
+	if err != nil {
+		return "", false, err
+	}
+	token, ok := lookup(key)
+	if !ok || token == "" {
+		return "", ok, nil
+	}
+
+	claims, err := decodeJWTClaims(token)
+	if err != nil {
+		return "", true, &ResolverError{Prefix: jwtClaimPrefix, Err: fmt.Errorf("%s: %w", key, err)}
+	}
+	raw, ok := claims[claim]
+	if !ok {
+		return "", true, nil
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s, true, nil
+	}
+
+	return string(raw), true, nil
+}
+
+// decodeJWTClaims decodes the payload of token, header.payload.signature,
+// without verifying anything.
+func decodeJWTClaims(token string) (map[string]json.RawMessage, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid JWT, expected header.payload.signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+
+	return claims, nil
+}
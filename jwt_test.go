@@ -0,0 +1,63 @@
+package godefault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+
+	. "gopkg.in/check.v1"
+)
+
+type JWTSuite struct{}
+
+var _ = Suite(&JWTSuite{})
+
+type ExampleJWT struct {
+	Subject string `default:"jwtclaim:GODEFAULT_TEST_TOKEN:sub"`
+	Tenant  string `default:"jwtclaim:GODEFAULT_TEST_TOKEN:https://example.com/tenant"`
+	Level   int    `default:"jwtclaim:GODEFAULT_TEST_TOKEN:level"`
+	Missing string `default:"jwtclaim:GODEFAULT_TEST_TOKEN:email"`
+	Unset   string `default:"jwtclaim:GODEFAULT_TEST_NO_TOKEN:sub"`
+}
+
+func testToken(payload string) string {
+	// The signature is never checked.
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2ln"
+}
+
+func (s *JWTSuite) TestJWTClaim(c *C) {
+	os.Setenv("GODEFAULT_TEST_TOKEN", testToken(`{"sub":"alice","https://example.com/tenant":"acme","level":3}`))
+	defer os.Unsetenv("GODEFAULT_TEST_TOKEN")
+
+	foo := &ExampleJWT{}
+	report := &FillReport{}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Subject, Equals, "alice")
+	c.Assert(foo.Tenant, Equals, "acme")
+	c.Assert(foo.Level, Equals, 3)
+	c.Assert(foo.Missing, Equals, "")
+	c.Assert(foo.Unset, Equals, "")
+	c.Assert(report.Fields[0].Source, Equals, SourceEnv)
+	c.Assert(report.Fields[4].Source, Equals, SourceNone)
+}
+
+func (s *JWTSuite) TestJWTClaimErrors(c *C) {
+	os.Setenv("GODEFAULT_TEST_TOKEN", "not-a-token")
+	defer os.Unsetenv("GODEFAULT_TEST_TOKEN")
+
+	foo := &struct {
+		Subject string `default:"jwtclaim:GODEFAULT_TEST_TOKEN:sub"`
+		Claim   string `default:"jwtclaim:GODEFAULT_TEST_TOKEN"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Subject: GODEFAULT_TEST_TOKEN: invalid JWT, expected header.payload.signature\n`+
+		`Claim: invalid jwtclaim reference "jwtclaim:GODEFAULT_TEST_TOKEN", expected jwtclaim:KEY:claim`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+
+	os.Setenv("GODEFAULT_TEST_TOKEN", testToken(`[1]`))
+	c.Assert(SetDefaultsContext(context.Background(), &ExampleJWT{}), ErrorMatches, `(?s)Subject: GODEFAULT_TEST_TOKEN: invalid JWT payload: .*`)
+
+	c.Assert(CheckDefaults(foo), HasLen, 1)
+	c.Assert(CheckDefaults(&ExampleJWT{}), HasLen, 0)
+}
@@ -0,0 +1,98 @@
+package godefault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// kvPrefix introduces a default read from a key-value store, such as Consul
+// or etcd, through the KeyValueSource registered for its scheme, with an
+// optional fallback used when the key is missing:
+//
+//	Port int    `default:"kv:config/app/port:8080"`
+//	Zone string `default:"kv:etcd://config/app/zone"`
+//
+// Keys without a scheme go to the source registered for the empty scheme.
+// Integer fields keep their transform, like with env: references.
+const kvPrefix = "kv:"
+
+// KeyValueSource is a backend of kv: defaults, see WithKeyValueSource.
+type KeyValueSource interface {
+	// Get returns the value at key, and whether there is one. Errors fail
+	// the field; they should only be returned when the store can't be
+	// read. ctx is the one of the fill.
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+}
+
+// MapSource is a KeyValueSource holding its values in memory, e.g. for
+// tests.
+type MapSource map[string]string
+
+func (s MapSource) Get(_ context.Context, key string) (string, bool, error) {
+	value, ok := s[key]
+	return value, ok, nil
+}
+
+// WithKeyValueSource registers source for the kv: defaults of scheme, e.g.
+// "consul" for kv:consul://config/app/port, or "" for those without one.
+func WithKeyValueSource(scheme string, source KeyValueSource) Option {
+	return func(f *Filler) {
+		if f.kvSources == nil {
+			f.kvSources = make(map[string]KeyValueSource)
+		}
+		f.kvSources[scheme] = source
+	}
+}
+
+func isKVRef(value string) bool {
+	return strings.HasPrefix(value, kvPrefix)
+}
+
+// parseKVRef splits a kv: reference into its scheme, key and fallback.
+func parseKVRef(value string) (scheme, key, fallback string, err error) {
+	key = strings.TrimPrefix(value, kvPrefix)
+	if i := strings.Index(key, "://"); i >= 0 {
+		scheme, key = key[:i], key[i+len("://"):]
+	}
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		key, fallback = key[:i], key[i+1:]
+	}
+	if key == "" {
+		return "", "", "", fmt.Errorf("invalid kv reference %q, expected %s[scheme://]key[:fallback]", value, kvPrefix)
+	}
+
+	return scheme, key, fallback, nil
+}
+
+// resolveKVRef returns the value the kv: reference value of field resolves
+// to, the transform of integer fields kept, the key read, after its scheme,
+// and whether it was found.
+func resolveKVRef(field *FieldData, value string) (string, string, bool, error) {
+	suffix := ""
+	if isIntegerType(field.Field.Type) {
+		value, suffix = splitIntTransform(value)
+	}
+	scheme, key, fallback, err := parseKVRef(value)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	source := field.owner().kvSources[scheme]
+	if source == nil {
+		return "", "", false, &ResolverError{Prefix: kvPrefix, Err: fmt.Errorf("%s: no key-value source for scheme %q", value, scheme)}
+	}
+	resolved, ok, err := source.Get(field.Context(), key)
+	if err != nil {
+		return "", "", false, &ResolverError{Prefix: kvPrefix, Err: fmt.Errorf("%s: %w", value, err)}
+	}
+	name := key
+	if scheme != "" {
+		name = scheme + "://" + key
+	}
+	if !ok {
+		return fallback + suffix, name, false, nil
+	}
+
+	return resolved + suffix, name, true, nil
+}
@@ -0,0 +1,79 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+type KVSuite struct{}
+
+var _ = Suite(&KVSuite{})
+
+type ExampleKV struct {
+	Port    int      `default:"kv:config/app/port:8080"`
+	Admin   int      `default:"kv:config/app/port:8080|+1"`
+	Zone    string   `default:"kv:etcd://config/app/zone"`
+	Ratio   float64  `default:"kv:config/app/ratio:0.5"`
+	Missing string   `default:"kv:config/app/missing"`
+	Chained string   `default:"env:GODEFAULT_TEST_ZONE:kv:etcd://config/app/zone"`
+	Tags    []string `default:"kv:config/app/tags"`
+}
+
+type failingSource struct{}
+
+func (failingSource) Get(context.Context, string) (string, bool, error) {
+	return "", false, errors.New("connection refused")
+}
+
+func (s *KVSuite) TestKV(c *C) {
+	filler := NewFiller(
+		WithKeyValueSource("", MapSource{"config/app/port": "9000", "config/app/tags": "[a,b]"}),
+		WithKeyValueSource("etcd", MapSource{"config/app/zone": "eu-west-1"}),
+	)
+	foo := &ExampleKV{}
+	c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Port, Equals, 9000)
+	c.Assert(foo.Admin, Equals, 9001)
+	c.Assert(foo.Zone, Equals, "eu-west-1")
+	c.Assert(foo.Ratio, Equals, 0.5)
+	c.Assert(foo.Missing, Equals, "")
+	c.Assert(foo.Chained, Equals, "eu-west-1")
+	c.Assert(foo.Tags, DeepEquals, []string{"a", "b"})
+
+	report := &FillReport{}
+	WithReport(report)(filler)
+	c.Assert(filler.FillContext(context.Background(), &ExampleKV{}), IsNil)
+	c.Assert(report.Fields[0].Source, Equals, SourceKV)
+	c.Assert(report.Fields[3].Source, Equals, SourceTag)
+	c.Assert(report.Fields[4].Source, Equals, SourceNone)
+}
+
+func (s *KVSuite) TestKVErrors(c *C) {
+	foo := &struct {
+		Scheme string `default:"kv:consul://config/app/zone"`
+		Failed string `default:"kv:config/app/zone:eu"`
+		Empty  string `default:"kv:"`
+		Parse  int    `default:"kv:config/app/zone"`
+	}{}
+	filler := NewFiller(WithKeyValueSource("", failingSource{}))
+	err := filler.FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Scheme: kv:consul://config/app/zone: no key-value source for scheme "consul"\n`+
+		`Failed: kv:config/app/zone:eu: connection refused\n`+
+		`Empty: invalid kv reference "kv:", expected kv:\[scheme://\]key\[:fallback\]\n`+
+		`Parse: kv:config/app/zone: connection refused`)
+	c.Assert(foo.Failed, Equals, "")
+	c.Assert(errors.Is(err, ErrParse), Equals, false)
+
+	bar := &struct {
+		Port  int `default:"kv:config/app/port:x"`
+		Admin int `default:"kv:config/app/port:80|+1"`
+		Empty int `default:"kv:"`
+	}{}
+	errs := CheckDefaults(bar)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `Port: strconv.ParseInt: parsing "x": invalid syntax`)
+	c.Assert(errs[1], ErrorMatches, `Empty: invalid kv reference .*`)
+	c.Assert(CheckDefaults(&ExampleKV{}), HasLen, 0)
+}
@@ -0,0 +1,329 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Layer is a source of values for Apply.
+type Layer interface {
+	// Fill sets the leaf field at path, see FieldData.Path, and reports
+	// whether the layer provided a value. FieldData.Assign parses raw values
+	// like default tags.
+	Fill(path string, field *FieldData) bool
+}
+
+// Apply fills the struct behind v from layers, by decreasing precedence:
+// every leaf field is given to the layers in order until one provides a
+// value, so lower layers only see the fields no higher layer set. Fields no
+// layer sets are left as they are; ValuesLayer decides where the values set
+// before the call rank. A value a layer provides but that fails to parse is
+// an error of Apply, not a miss: the field is left zero rather than given to
+// the lower layers, so that a typo in, say, an env variable isn't hidden by
+// the default tag. With WithReport, the report attributes every field to
+// the layer that set it, by its String method when it has one.
+//
+// Nil pointers to structs are allocated when a layer other than TagLayer sets
+// one of their fields, like Fill never allocates them for tags alone.
+//
+// Usage
+//
+//	err := Apply(&config,
+//	    ValuesLayer(),
+//	    EnvLayer("myapp"),
+//	    MapLayer(properties),
+//	    TagLayer(),
+//	)
+func Apply(v interface{}, layers ...Layer) error {
+	return getDefaultFiller().Apply(v, layers...)
+}
+
+// Apply is Apply using f for parsing the values and reporting.
+func (f *Filler) Apply(v interface{}, layers ...Layer) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return invalidTargetf("godefault: expected a non-nil pointer to a struct, got %T", v)
+	}
+
+	a := &applier{filler: f, layers: layers, state: &fillState{recover: true, root: value.Elem()}, visiting: make(map[reflect.Type]bool)}
+	a.applyStruct(value.Elem(), nil, nil, true)
+	f.resolveLookups(a.state)
+	for _, visit := range a.visits {
+		if visit.source == SourceJSONDash || visit.source == SourceSkippedKind {
+			f.skipped(visit.field, visit.source)
+			continue
+		}
+		f.visited(visit.field, visit.source)
+	}
+
+	if a.state.err != nil {
+		return a.state.err
+	}
+
+	return joinErrors(a.state.errs)
+}
+
+type applier struct {
+	filler   *Filler
+	layers   []Layer
+	state    *fillState
+	visiting map[reflect.Type]bool
+	// visits are the leaves set, checked once the fields discarded with
+	// the structs allocated for nil pointers are known.
+	visits []appliedField
+}
+
+type appliedField struct {
+	field  *FieldData
+	source string
+}
+
+// applyStruct applies the layers to the fields of value and reports whether
+// a layer other than TagLayer set one of them. keyed is false below fields
+// that layers can't address by key: excluded ones and struct slices.
+func (a *applier) applyStruct(value reflect.Value, parent *FieldData, names []string, keyed bool) bool {
+	t := value.Type()
+	a.visiting[t] = true
+	defer delete(a.visiting, t)
+
+	var fields []*FieldData
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldValue, ok := a.filler.settable(value.Field(i))
+		tag := sf.Tag.Get(a.filler.Tag)
+		if !ok || tag == "-" || isMetaField(sf) {
+			continue
+		}
+		if skip := a.filler.skipSource(sf); skip != "" {
+			a.visits = append(a.visits, appliedField{field: &FieldData{Value: fieldValue, Field: sf, Parent: parent, filler: a.filler, state: a.state}, source: skip})
+			continue
+		}
+
+		field := &FieldData{
+			Value:    fieldValue,
+			Field:    sf,
+			TagValue: tag,
+			Parent:   parent,
+			filler:   a.filler,
+			state:    a.state,
+			siblings: value,
+		}
+		if a.filler.keeps(field) {
+			fields = append(fields, field)
+		}
+	}
+
+	if !inheritDefaults(parent, fields) {
+		return false
+	}
+	set := false
+	for _, field := range derivedLast(fields) {
+		sf, fieldValue := field.Field, field.Value
+		name, inline, ok := externalName(sf, a.filler.nameTags)
+		fieldNames, fieldKeyed := names, keyed && ok
+		if !inline {
+			fieldNames = appendName(names, name)
+		}
+
+		switch {
+		case isDeferredTag(field.TagValue) || isSiblingRef(field.TagValue):
+			// Found values are copied as a whole.
+			if fieldKeyed {
+				field.names = fieldNames
+			}
+			set = a.applyLeaf(field) || set
+		case a.filler.isStructType(sf.Type):
+			if !skipsStruct(field) {
+				set = a.applyStruct(fieldValue, field, fieldNames, fieldKeyed) || set
+			}
+		case sf.Type.Kind() == reflect.Ptr && a.filler.isStructType(sf.Type.Elem()):
+			elemType := sf.Type.Elem()
+			if !fieldValue.IsNil() {
+				if !field.visit(fieldValue.Pointer()) {
+					set = a.applyStruct(fieldValue.Elem(), field, fieldNames, fieldKeyed) || set
+				}
+			} else if !a.visiting[elemType] {
+				visits, errs := len(a.visits), len(a.state.errs)
+				elem := reflect.New(elemType)
+				if a.applyStruct(elem.Elem(), field, fieldNames, fieldKeyed) {
+					fieldValue.Set(elem)
+					set = true
+				} else {
+					a.visits, a.state.errs = a.visits[:visits], a.state.errs[:errs]
+				}
+			}
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Struct:
+			makeSlice(field)
+			for j := 0; j < a.filler.sliceFillCount(fieldValue.Len()); j++ {
+				elem := fieldValue.Index(j)
+				a.applyStruct(elem, field.element(elem, strconv.Itoa(j)), nil, false)
+			}
+		default:
+			if fieldKeyed {
+				field.names = fieldNames
+			}
+			set = a.applyLeaf(field) || set
+		}
+	}
+
+	return set
+}
+
+func (a *applier) applyLeaf(field *FieldData) bool {
+	path := field.Path()
+	for _, layer := range a.layers {
+		errs := len(a.state.errs)
+		if fillLayer(layer, path, field) {
+			source := layerName(layer)
+			_, isTag := layer.(tagLayer)
+			if failed := len(a.state.errs) != errs; isTag || failed {
+				source = tagSource(field, failed)
+			}
+			a.visits = append(a.visits, appliedField{field: field, source: source})
+			return !isTag
+		}
+	}
+	a.visits = append(a.visits, appliedField{field: field, source: SourceNone})
+
+	return false
+}
+
+// fillLayer calls layer.Fill, a panic counting as a failed fill of field.
+func fillLayer(layer Layer, path string, field *FieldData) bool {
+	if field.recovers() {
+		defer field.recoverPanic()
+	}
+
+	return layer.Fill(path, field)
+}
+
+func layerName(layer Layer) string {
+	if s, ok := layer.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return fmt.Sprintf("%T", layer)
+}
+
+// Assign parses value with the fillers of the kind or type of the field and
+// sets the field with it, once coerced to the kind of the field when the
+// Filler has weak typing, see WithWeakTyping. The value is a literal, coming
+// from outside the program: references such as env:, ref:, printf:, kv: or
+// gen:, date placeholders, envs| mappings and file: contents are not
+// resolved, a string field getting "ref:Password" as it is. The zero
+// sentinels still clear the field. Parse errors are reported by the
+// error-returning fills.
+func (field *FieldData) Assign(value string) {
+	assigned := *field
+	assigned.TagValue = assigned.coerce(value)
+	assigned.resolved = true
+	assigned.notTag = true
+	assigned.literal = true
+	field.owner().SetDefaultValue(&assigned)
+}
+
+// ValuesLayer keeps the values set before Apply, which makes them win over
+// the layers below it.
+func ValuesLayer() Layer {
+	return valuesLayer{}
+}
+
+type valuesLayer struct{}
+
+func (valuesLayer) Fill(path string, field *FieldData) bool {
+	return !isZeroValue(field.Value)
+}
+
+func (valuesLayer) String() string {
+	return SourcePreset
+}
+
+// EnvLayer sets fields from the environment variables named after their keys,
+// see ExtractEnv, e.g. MYAPP_SERVER_BIND_ADDR for server.bind_addr with the
+// prefix "myapp".
+func EnvLayer(prefix string) Layer {
+	return envLayer{prefix: prefix}
+}
+
+type envLayer struct {
+	prefix string
+}
+
+func (l envLayer) Fill(path string, field *FieldData) bool {
+	if field.names == nil {
+		return false
+	}
+	name := envName(l.prefix, field.names)
+	value, ok := field.lookupEnv(name)
+	if ok {
+		// Assigned values separate their elements with commas.
+		field.Assign(envList(field, value, defaultSeparator))
+		field.sourceName = name
+	}
+
+	return ok
+}
+
+func (envLayer) String() string {
+	return "env"
+}
+
+// MapLayer sets fields from values keyed by their dotted keys, see
+// ExtractDefaults, e.g. as parsed from a properties file. Keys are matched
+// regardless of case; of the keys only differing in case, the last in sorted
+// order wins.
+func MapLayer(values map[string]string) Layer {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	l := mapLayer(make(map[string]string, len(values)))
+	for _, key := range keys {
+		l[strings.ToLower(key)] = values[key]
+	}
+
+	return l
+}
+
+type mapLayer map[string]string
+
+func (l mapLayer) Fill(path string, field *FieldData) bool {
+	if field.names == nil {
+		return false
+	}
+	value, ok := l[strings.ToLower(strings.Join(field.names, "."))]
+	if ok {
+		field.Assign(value)
+	}
+
+	return ok
+}
+
+func (mapLayer) String() string {
+	return "map"
+}
+
+// TagLayer sets fields from their default tags, like Fill.
+func TagLayer() Layer {
+	return tagLayer{}
+}
+
+type tagLayer struct{}
+
+func (tagLayer) Fill(path string, field *FieldData) bool {
+	if field.TagValue == "" {
+		return false
+	}
+	field.owner().SetDefaultValue(field)
+
+	return true
+}
+
+func (tagLayer) String() string {
+	return SourceTag
+}
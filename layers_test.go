@@ -0,0 +1,145 @@
+package godefault
+
+import (
+	"os"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type LayersSuite struct{}
+
+var _ = Suite(&LayersSuite{})
+
+type ExampleLayers struct {
+	Name    string `default:"app"`
+	Mode    string `default:"dev"`
+	Workers int    `default:"4"`
+	Server  struct {
+		BindAddr string        `yaml:"bind_addr" default:"0.0.0.0"`
+		Timeout  time.Duration `default:"10s"`
+	} `yaml:"server"`
+	TLS     *ExampleTLS
+	Cache   *ExampleTLS
+	Peers   []Child
+	Secret  string `yaml:"-" default:"s3cr3t"`
+	Untouch int
+}
+
+type ExampleTLS struct {
+	Cert string `default:"cert.pem"`
+	Key  string
+}
+
+func (s *LayersSuite) TestApply(c *C) {
+	os.Setenv("GODEFAULT_TEST_SERVER_BIND_ADDR", "127.0.0.1")
+	os.Setenv("GODEFAULT_TEST_NAME", "from-env")
+	os.Setenv("GODEFAULT_TEST_SECRET", "leaked")
+	defer os.Unsetenv("GODEFAULT_TEST_SERVER_BIND_ADDR")
+	defer os.Unsetenv("GODEFAULT_TEST_NAME")
+	defer os.Unsetenv("GODEFAULT_TEST_SECRET")
+
+	var report FillReport
+	filler := NewFiller(WithReport(&report))
+	foo := &ExampleLayers{Name: "preset", Peers: []Child{{Name: "alice"}}}
+	err := filler.Apply(foo,
+		ValuesLayer(),
+		EnvLayer("godefault_test"),
+		MapLayer(map[string]string{"Mode": "prod", "server.timeout": "30s", "tls.key": "key.pem"}),
+		TagLayer(),
+	)
+	c.Assert(err, IsNil)
+
+	c.Assert(foo.Name, Equals, "preset")
+	c.Assert(foo.Mode, Equals, "prod")
+	c.Assert(foo.Workers, Equals, 4)
+	c.Assert(foo.Server.BindAddr, Equals, "127.0.0.1")
+	c.Assert(foo.Server.Timeout, Equals, 30*time.Second)
+	c.Assert(*foo.TLS, Equals, ExampleTLS{Cert: "cert.pem", Key: "key.pem"})
+	c.Assert(foo.Cache, IsNil)
+	c.Assert(foo.Peers[0].Age, Equals, 10)
+	c.Assert(foo.Secret, Equals, "s3cr3t")
+
+	sources := make(map[string]string)
+	for _, field := range report.Fields {
+		sources[field.Path] = field.Source
+	}
+	c.Assert(sources, DeepEquals, map[string]string{
+		"Name":            "preset",
+		"Mode":            "map",
+		"Workers":         "tag",
+		"Server.BindAddr": "env",
+		"Server.Timeout":  "map",
+		"TLS.Cert":        "tag",
+		"TLS.Key":         "map",
+		"Peers[0].Name":   "preset",
+		"Peers[0].Age":    "tag",
+		"Secret":          "tag",
+		"Untouch":         "none",
+	})
+}
+
+func (s *LayersSuite) TestApplyPrecedence(c *C) {
+	// Without ValuesLayer first, lower layers override the values set.
+	foo := &ExampleLayers{Name: "preset", Mode: "preset"}
+	c.Assert(Apply(foo, MapLayer(map[string]string{"name": "map"}), ValuesLayer(), TagLayer()), IsNil)
+	c.Assert(foo.Name, Equals, "map")
+	c.Assert(foo.Mode, Equals, "preset")
+
+	bar := &ExampleLayers{Name: "preset"}
+	c.Assert(Apply(bar, TagLayer()), IsNil)
+	c.Assert(bar.Name, Equals, "app")
+}
+
+func (s *LayersSuite) TestApplyErrors(c *C) {
+	foo := &ExampleLayers{}
+	err := Apply(foo, MapLayer(map[string]string{"workers": "many", "cache.key": "x", "peers.age": "1"}))
+	c.Assert(err, ErrorMatches, `Workers: strconv.ParseInt: parsing "many": invalid syntax`)
+	c.Assert(foo.Cache.Key, Equals, "x")
+	c.Assert(foo.Cache.Cert, Equals, "")
+
+	foo = &ExampleLayers{}
+	err = Apply(foo, MapLayer(map[string]string{"workers": "many", "mode": "prod"}), TagLayer())
+	c.Assert(err, ErrorMatches, `Workers: strconv.ParseInt: parsing "many": invalid syntax`)
+	c.Assert(foo.Workers, Equals, 0)
+	c.Assert(foo.Mode, Equals, "prod")
+	c.Assert(foo.Name, Equals, "app")
+
+	c.Assert(Apply(ExampleLayers{}), ErrorMatches, "godefault: expected a non-nil pointer to a struct, got godefault.ExampleLayers")
+}
+
+func (s *LayersSuite) TestApplyLiteral(c *C) {
+	foo := &ExampleLayers{}
+	err := Apply(foo, MapLayer(map[string]string{"name": "ref:Secret", "mode": "printf:%s|HOME", "server.bind_addr": "env:HOME"}), TagLayer())
+	c.Assert(err, IsNil)
+	c.Assert(foo.Name, Equals, "ref:Secret")
+	c.Assert(foo.Mode, Equals, "printf:%s|HOME")
+	c.Assert(foo.Server.BindAddr, Equals, "env:HOME")
+
+	os.Setenv("GODEFAULT_TEST_LITERAL_NAME", "{{date:0,0,0}}")
+	os.Setenv("GODEFAULT_TEST_LITERAL_WORKERS", "gen:uuid")
+	defer os.Unsetenv("GODEFAULT_TEST_LITERAL_NAME")
+	defer os.Unsetenv("GODEFAULT_TEST_LITERAL_WORKERS")
+	bar := &ExampleLayers{}
+	err = Apply(bar, EnvLayer("godefault_test_literal"), TagLayer())
+	c.Assert(err, ErrorMatches, `Workers: strconv.ParseInt: parsing "gen:uuid": invalid syntax`)
+	c.Assert(bar.Name, Equals, "{{date:0,0,0}}")
+}
+
+type exampleLayer struct{}
+
+func (exampleLayer) Fill(path string, field *FieldData) bool {
+	if path != "Workers" {
+		return false
+	}
+	field.Assign("8")
+	return true
+}
+
+func (s *LayersSuite) TestApplyCustomLayer(c *C) {
+	var report FillReport
+	foo := &ExampleLayers{}
+	c.Assert(NewFiller(WithReport(&report)).Apply(foo, exampleLayer{}), IsNil)
+	c.Assert(foo.Workers, Equals, 8)
+	c.Assert(report.Fields[2], DeepEquals, FieldReport{Path: "Workers", Source: "godefault.exampleLayer", Value: "8", Tag: "4"})
+}
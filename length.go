@@ -0,0 +1,54 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// lenRefPrefix introduces a default counting the elements of a sibling
+// slice, array or map, once the sibling was filled:
+//
+//	Items []string `default:"[a,b,c]"`
+//	Count int      `default:"len:Items"`
+//
+// Integer fields can apply a transform to the length, see splitIntTransform.
+const lenRefPrefix = "len:"
+
+// isLenRef reports whether value is a len: reference.
+func isLenRef(value string) bool {
+	return strings.HasPrefix(value, lenRefPrefix)
+}
+
+// resolveLenRef replaces the len: reference of field by the length of the
+// sibling it names, keeping the transform of integer fields. References to
+// missing fields or to fields without a length resolve to nothing, which
+// strict fills report.
+func resolveLenRef(field *FieldData) string {
+	value, suffix := field.TagValue, ""
+	if isIntegerType(field.Field.Type) {
+		value, suffix = splitIntTransform(value)
+	}
+
+	name := strings.TrimPrefix(value, lenRefPrefix)
+	var sibling reflect.Value
+	if field.siblings.IsValid() {
+		sibling = field.siblings.FieldByName(name)
+	}
+
+	switch sibling.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return strconv.Itoa(sibling.Len()) + suffix
+	case reflect.Invalid:
+		if field.owner().strict {
+			field.fail(unknownPathf("%s%s: no such sibling field", lenRefPrefix, name))
+		}
+	default:
+		if field.owner().strict {
+			field.fail(fmt.Errorf("%s%s: %s has no length", lenRefPrefix, name, sibling.Type()))
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,72 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type LengthSuite struct{}
+
+var _ = Suite(&LengthSuite{})
+
+type ExampleLength struct {
+	Count   int               `default:"len:Items"`
+	Next    uint8             `default:"len:Items|+1"`
+	Labels  int               `default:"len:Tags"`
+	Items   []string          `default:"[a,b,c]"`
+	Tags    map[string]string `default:"{a:1}"`
+	Preset  int               `default:"len:Items"`
+	Missing int               `default:"len:Unknown"`
+	Name    string            `default:"foo"`
+	Invalid int               `default:"len:Name"`
+}
+
+func (s *LengthSuite) TestLenRef(c *C) {
+	foo := &ExampleLength{Preset: 7}
+	SetDefaults(foo)
+
+	c.Assert(foo.Count, Equals, 3)
+	c.Assert(foo.Next, Equals, uint8(4))
+	c.Assert(foo.Labels, Equals, 1)
+	c.Assert(foo.Preset, Equals, 7)
+	c.Assert(foo.Missing, Equals, 0)
+	c.Assert(foo.Invalid, Equals, 0)
+}
+
+func (s *LengthSuite) TestLenRefFilledItems(c *C) {
+	foo := &ExampleLength{Items: []string{"x"}}
+	SetDefaults(foo)
+
+	c.Assert(foo.Count, Equals, 1)
+}
+
+func (s *LengthSuite) TestLenRefStrict(c *C) {
+	err := SetDefaultsContext(context.Background(), &ExampleLength{}, WithStrict())
+	c.Assert(err, ErrorMatches, "Missing: len:Unknown: no such sibling field\nInvalid: len:Name: string has no length")
+
+	c.Assert(SetDefaultsContext(context.Background(), &ExampleLength{}), IsNil)
+}
+
+func (s *LengthSuite) TestLenRefPlanAndLayers(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExampleLength{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleLength{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Count, Equals, 3)
+
+	bar := &ExampleLength{}
+	c.Assert(Apply(bar, MapLayer(map[string]string{"items": "[x,y]"}), TagLayer()), IsNil)
+	c.Assert(bar.Count, Equals, 2)
+}
+
+func (s *LengthSuite) TestCheckDefaultsLenRef(c *C) {
+	c.Assert(CheckDefaults(&ExampleLength{}), HasLen, 0)
+
+	errs := CheckDefaults(&struct {
+		Count int8 `default:"len:Items|+1000"`
+	}{})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Count: 0\|\+1000 overflows int8`)
+}
@@ -0,0 +1,45 @@
+//go:build go1.21
+// +build go1.21
+
+package godefault_test
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/sonnt85/godefault"
+)
+
+// slogLogger adapts a slog.Logger to WithLogger. EventValue is a Stringer,
+// so the values of events below the logger level are never formatted.
+func slogLogger(logger *slog.Logger) func(e godefault.FillEvent) {
+	return func(e godefault.FillEvent) {
+		logger.Debug("default applied",
+			slog.String("path", e.Path),
+			slog.Any("value", e.Value),
+			slog.String("source", e.Source),
+		)
+	}
+}
+
+func ExampleWithLogger() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	var config struct {
+		Timeout  string `default:"30s"`
+		Password string `default:"hunter2" secret:"true"`
+	}
+	godefault.NewFiller(godefault.WithLogger(slogLogger(logger))).Fill(&config)
+
+	// Output:
+	// level=DEBUG msg="default applied" path=Timeout value=30s source=tag
+	// level=DEBUG msg="default applied" path=Password value=**** source=tag
+}
@@ -0,0 +1,266 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lookupPrefix introduces a default copied from a value found elsewhere in
+// the struct being filled, once the rest of it is filled:
+//
+//	Profiles map[string]Profile `default:"{standard:,large:}"`
+//	Active   Profile            `default:"lookup:Profiles[standard]"`
+//
+// The path is dotted from the root of the fill, through pointers, with at
+// most one selector: a map key or a slice index in brackets. The value is
+// deep copied into the field, converted when its type only converts to the
+// one of the field. A path leading nowhere fails the field.
+const lookupPrefix = "lookup:"
+
+var lookupSegmentPattern = regexp.MustCompile(`^(\w+)(?:\[([^\[\]]*)\])?$`)
+
+type lookupSegment struct {
+	name string
+	// selector is the map key or slice index following the name, if any.
+	selector    string
+	hasSelector bool
+}
+
+func isLookupRef(value string) bool {
+	return strings.HasPrefix(value, lookupPrefix)
+}
+
+// parseLookupRef splits the path of a lookup: reference.
+func parseLookupRef(value string) ([]lookupSegment, error) {
+	path := strings.TrimPrefix(value, lookupPrefix)
+	var segments []lookupSegment
+	selectors := 0
+	for _, part := range strings.Split(path, ".") {
+		match := lookupSegmentPattern.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("invalid lookup reference %q, expected %sPath.To.Field[key]", value, lookupPrefix)
+		}
+		segment := lookupSegment{name: match[1], selector: match[2], hasSelector: strings.HasSuffix(part, "]")}
+		if segment.hasSelector {
+			selectors++
+		}
+		segments = append(segments, segment)
+	}
+	if selectors > 1 {
+		return nil, fmt.Errorf("invalid lookup reference %q, expected a single selector", value)
+	}
+
+	return segments, nil
+}
+
+// deferLookup records the field with a lookup: or ratio: reference to be resolved at
+// the end of the fill, and reports whether it did. Fields filled outside of
+// a fill are resolved at once.
+func deferLookup(field *FieldData) bool {
+	if !isDeferredTag(field.TagValue) || field.state == nil || !field.state.root.IsValid() {
+		return false
+	}
+	field.state.lookups = append(field.state.lookups, field)
+
+	return true
+}
+
+// resolveLookups resolves the lookup: and ratio: references deferred by the
+// fill that used state, in the order they were met, but for the ratio:
+// fields read by a ratio: reference, which are resolved before it.
+func (f *Filler) resolveLookups(state *fillState) {
+	if state == nil {
+		return
+	}
+
+	lookups := state.lookups
+	state.lookups = nil
+	r := &lookupResolver{
+		root:      state.root,
+		pending:   make(map[uintptr]*FieldData),
+		resolving: make(map[*FieldData]bool),
+		cyclic:    make(map[*FieldData]bool),
+	}
+	for _, field := range lookups {
+		if isRatioRef(field.TagValue) && field.Value.CanAddr() {
+			r.pending[field.Value.UnsafeAddr()] = field
+		}
+	}
+	for _, field := range lookups {
+		if state.aborted(field) {
+			return
+		}
+		r.resolve(field)
+	}
+}
+
+// lookupResolver resolves the references deferred by a fill.
+type lookupResolver struct {
+	root reflect.Value
+	// pending are the ratio: fields not resolved yet, by address.
+	pending map[uintptr]*FieldData
+	// resolving are the ratio: fields being resolved, to detect cycles, and
+	// cyclic the ones that failed on one.
+	resolving map[*FieldData]bool
+	cyclic    map[*FieldData]bool
+}
+
+func (r *lookupResolver) resolve(field *FieldData) {
+	if !isRatioRef(field.TagValue) {
+		if isZeroValue(field.Value) {
+			resolveLookup(field, r.root)
+		}
+		return
+	}
+
+	if field.Value.CanAddr() {
+		delete(r.pending, field.Value.UnsafeAddr())
+	}
+	if isZeroValue(field.Value) {
+		r.resolving[field] = true
+		r.resolveRatio(field)
+		delete(r.resolving, field)
+	}
+}
+
+// resolveTarget resolves the pending ratio: field at value, read by field,
+// first. It fails field and reports false when the fields read each other.
+func (r *lookupResolver) resolveTarget(field *FieldData, value reflect.Value) bool {
+	if !value.CanAddr() {
+		return true
+	}
+	if target, ok := r.pending[value.UnsafeAddr()]; ok {
+		r.resolve(target)
+		if !r.cyclic[target] {
+			return true
+		}
+		r.cyclic[field] = true
+		field.fail(fmt.Errorf("%s: cycle through %s", field.TagValue, target.Path()))
+		return false
+	}
+	for other := range r.resolving {
+		if other.Value.CanAddr() && other.Value.UnsafeAddr() == value.UnsafeAddr() {
+			r.cyclic[field] = true
+			field.fail(fmt.Errorf("%s: cycle through %s", field.TagValue, other.Path()))
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolveLookup sets field to the value its lookup: reference leads to from
+// root.
+func resolveLookup(field *FieldData, root reflect.Value) {
+	segments, err := parseLookupRef(field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return
+	}
+
+	value := root
+	for _, segment := range segments {
+		if value, err = lookupSegmentValue(value, segment); err != nil {
+			field.fail(fmt.Errorf("%s: %w", field.TagValue, err))
+			return
+		}
+	}
+
+	setCopy(field, value)
+}
+
+// setCopy sets field to a deep copy of value, converted when its type only
+// converts to the one of the field, allocating the field when it is a
+// pointer and value isn't.
+func setCopy(field *FieldData, value reflect.Value) {
+	target := field.Value
+	if target.Kind() == reflect.Ptr && value.Kind() != reflect.Ptr {
+		target = reflect.New(target.Type().Elem()).Elem()
+	}
+	c := &cloner{copies: make(map[uintptr]reflect.Value)}
+	switch t := target.Type(); {
+	case value.Type().AssignableTo(t):
+		target.Set(c.clone(value))
+	// Integers convert to strings as runes, which no one wants here.
+	case value.Type().ConvertibleTo(t) && (t.Kind() != reflect.String || value.Kind() == reflect.String):
+		target.Set(c.clone(value).Convert(t))
+	default:
+		field.fail(fmt.Errorf("%s: %s can't be assigned to %s", field.TagValue, value.Type(), t))
+		return
+	}
+	if target != field.Value {
+		field.Value.Set(target.Addr())
+	}
+}
+
+// lookupSegmentValue returns the field of value, a struct or pointers to
+// one, named by segment, then its element at the selector.
+func lookupSegmentValue(value reflect.Value, segment lookupSegment) (reflect.Value, error) {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, fmt.Errorf("nil pointer before %s", segment.name)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, unknownPathf("no field %s in %s", segment.name, value.Type())
+	}
+	sf, ok := value.Type().FieldByName(segment.name)
+	if !ok || sf.PkgPath != "" {
+		return reflect.Value{}, unknownPathf("no field %s in %s", segment.name, value.Type())
+	}
+	value = value.FieldByIndex(sf.Index)
+	if !segment.hasSelector {
+		return value, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		key, err := lookupKey(value.Type().Key(), segment.selector)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		elem := value.MapIndex(key)
+		if !elem.IsValid() {
+			return reflect.Value{}, fmt.Errorf("no key %s in %s", segment.selector, segment.name)
+		}
+		return elem, nil
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(segment.selector)
+		if err != nil || i < 0 || i >= value.Len() {
+			return reflect.Value{}, fmt.Errorf("no index %s in %s of length %d", segment.selector, segment.name, value.Len())
+		}
+		return value.Index(i), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s is a %s, not a map or a slice", segment.name, value.Type())
+}
+
+// lookupKey converts the selector to a map key of type t, a string or an
+// integer type.
+func lookupKey(t reflect.Type, selector string) (reflect.Value, error) {
+	key := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		key.SetString(selector)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := parseIntLiteral(selector, t)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		key.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseUintLiteral(selector, t)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		key.SetUint(n)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported map key type %s", t)
+	}
+
+	return key, nil
+}
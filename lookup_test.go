@@ -0,0 +1,93 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type LookupSuite struct{}
+
+var _ = Suite(&LookupSuite{})
+
+type Profile struct {
+	Workers int      `default:"4"`
+	Tags    []string `default:"[a]"`
+}
+
+type ExampleLookup struct {
+	Active   Profile            `default:"lookup:Profiles[standard]"`
+	Pointer  *Profile           `default:"lookup:Profiles[large]"`
+	Workers  int64              `default:"lookup:Profiles[standard].Workers"`
+	First    string             `default:"lookup:Servers[0]"`
+	Nested   string             `default:"lookup:Settings.Name"`
+	Profiles map[string]Profile `default:"{standard:,large:}"`
+	Servers  []string           `default:"[a.example.com,b.example.com]"`
+	Settings struct {
+		Name string `default:"app"`
+	}
+}
+
+func (s *LookupSuite) TestLookup(c *C) {
+	foo := &ExampleLookup{}
+	SetDefaults(foo)
+
+	c.Assert(foo.Active, DeepEquals, Profile{4, []string{"a"}})
+	c.Assert(*foo.Pointer, DeepEquals, Profile{4, []string{"a"}})
+	c.Assert(foo.Workers, Equals, int64(4))
+	c.Assert(foo.First, Equals, "a.example.com")
+	c.Assert(foo.Nested, Equals, "app")
+
+	// The copies are deep.
+	foo.Active.Tags[0] = "b"
+	c.Assert(foo.Profiles["standard"].Tags, DeepEquals, []string{"a"})
+}
+
+func (s *LookupSuite) TestLookupPreset(c *C) {
+	foo := &ExampleLookup{Active: Profile{Workers: 1}, Profiles: map[string]Profile{"standard": {Workers: 8}, "large": {}}}
+	SetDefaults(foo)
+
+	c.Assert(foo.Active, DeepEquals, Profile{Workers: 1})
+	c.Assert(foo.Workers, Equals, int64(8))
+}
+
+func (s *LookupSuite) TestLookupPlanAndLayers(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExampleLookup{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleLookup{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Active.Workers, Equals, 4)
+	c.Assert(foo.First, Equals, "a.example.com")
+
+	bar := &ExampleLookup{}
+	c.Assert(Apply(bar, MapLayer(map[string]string{"servers": "[x]"}), TagLayer()), IsNil)
+	c.Assert(bar.First, Equals, "x")
+	c.Assert(bar.Pointer.Workers, Equals, 4)
+}
+
+func (s *LookupSuite) TestLookupErrors(c *C) {
+	foo := &struct {
+		Missing string            `default:"lookup:Names[c]"`
+		Index   string            `default:"lookup:List[5]"`
+		Field   string            `default:"lookup:Unknown"`
+		Type    int               `default:"lookup:Names[a]"`
+		Rune    string            `default:"lookup:Count"`
+		Syntax  string            `default:"lookup:Names[a].X[0]"`
+		Names   map[string]string `default:"{a:1}"`
+		List    []string          `default:"[x]"`
+		Count   int               `default:"65"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Missing: lookup:Names\[c\]: no key c in Names\n`+
+		`Index: lookup:List\[5\]: no index 5 in List of length 1\n`+
+		`Field: lookup:Unknown: no field Unknown in struct .*\n`+
+		`Type: lookup:Names\[a\]: string can't be assigned to int\n`+
+		`Rune: lookup:Count: int can't be assigned to string\n`+
+		`Syntax: invalid lookup reference "lookup:Names\[a\].X\[0\]", expected a single selector`)
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Syntax: .*single selector`)
+	c.Assert(CheckDefaults(&ExampleLookup{}), HasLen, 0)
+}
@@ -0,0 +1,54 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// makePrefix introduces a default giving the length of an empty slice. The
+// elements of struct slices are then defaulted from their own tags, the
+// others are left zero:
+//
+//	Rules  []Rule   `default:"make:3"`
+//	Shards *[]Shard `default:"make:2"`
+//
+// []byte defaults are taken as they are, see dataURIPrefix.
+const makePrefix = "make:"
+
+// maxMakeLen bounds the lengths of make: defaults, so that a tag can't
+// exhaust the memory.
+const maxMakeLen = 1 << 16
+
+// isMakeLen reports whether value is a make: default.
+func isMakeLen(value string) bool {
+	return strings.HasPrefix(value, makePrefix)
+}
+
+// parseMakeLen parses the length of a make: default.
+func parseMakeLen(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(value, makePrefix))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid make length %q, expected %sN with N >= 0", value, makePrefix)
+	}
+	if n > maxMakeLen {
+		return 0, overflowf("make length %d exceeds %d", n, maxMakeLen)
+	}
+
+	return n, nil
+}
+
+// makeSlice sets the empty slice field to the length its make: default
+// gives, if it has one.
+func makeSlice(field *FieldData) {
+	if !isMakeLen(field.TagValue) || field.Value.Len() != 0 {
+		return
+	}
+
+	n, err := parseMakeLen(field.TagValue)
+	field.check(err)
+	if err == nil {
+		field.Value.Set(reflect.MakeSlice(field.Value.Type(), n, n))
+	}
+}
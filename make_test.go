@@ -0,0 +1,93 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type MakeSuite struct{}
+
+var _ = Suite(&MakeSuite{})
+
+type Rule struct {
+	Name   string `default:"allow"`
+	Weight int    `default:"1"`
+}
+
+type ExampleMake struct {
+	Rules    []Rule  `default:"make:2"`
+	Pointer  *[]Rule `default:"make:3"`
+	Untagged *[]Rule
+	Listed   *[]Rule `default:"[a,b]"`
+	Empty    *[]Rule `default:"make:0"`
+	Ints     []int   `default:"make:2"`
+}
+
+func (s *MakeSuite) TestMake(c *C) {
+	foo := &ExampleMake{}
+	SetDefaults(foo)
+
+	c.Assert(foo.Rules, DeepEquals, []Rule{{"allow", 1}, {"allow", 1}})
+	c.Assert(foo.Pointer, NotNil)
+	c.Assert(*foo.Pointer, DeepEquals, []Rule{{"allow", 1}, {"allow", 1}, {"allow", 1}})
+	c.Assert(foo.Untagged, IsNil)
+	c.Assert(foo.Listed, IsNil)
+	c.Assert(*foo.Empty, HasLen, 0)
+	c.Assert(foo.Ints, DeepEquals, []int{0, 0})
+}
+
+func (s *MakeSuite) TestMakePreset(c *C) {
+	rules := []Rule{{Name: "deny"}}
+	foo := &ExampleMake{Rules: []Rule{{Weight: 5}}, Pointer: &rules, Untagged: &[]Rule{{}}}
+	SetDefaults(foo)
+
+	c.Assert(foo.Rules, DeepEquals, []Rule{{"allow", 5}})
+	c.Assert(rules, DeepEquals, []Rule{{"deny", 1}})
+	c.Assert(*foo.Untagged, DeepEquals, []Rule{{"allow", 1}})
+}
+
+func (s *MakeSuite) TestMakeErrors(c *C) {
+	err := SetDefaultsContext(context.Background(), &struct {
+		Negative []Rule `default:"make:-1"`
+		Huge     *[]int `default:"make:100000"`
+	}{})
+	c.Assert(err, ErrorMatches, `Negative: invalid make length "make:-1", expected make:N with N >= 0\nHuge: make length 100000 exceeds 65536`)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+}
+
+func (s *MakeSuite) TestMakePaths(c *C) {
+	err := SetDefaultsContext(context.Background(), &struct {
+		Rules *[]struct {
+			Port int `default:"x"`
+		} `default:"make:2"`
+	}{})
+	c.Assert(err, ErrorMatches, `Rules\[0\].Port: .*\nRules\[1\].Port: .*`)
+}
+
+func (s *MakeSuite) TestMakePlanAndLayers(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExampleMake{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleMake{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Rules, HasLen, 2)
+	c.Assert(*foo.Pointer, HasLen, 3)
+	c.Assert(foo.Ints, DeepEquals, []int{0, 0})
+
+	bar := &ExampleMake{}
+	c.Assert(Apply(bar, TagLayer()), IsNil)
+	c.Assert(bar.Rules, DeepEquals, []Rule{{"allow", 1}, {"allow", 1}})
+	c.Assert(*bar.Pointer, HasLen, 3)
+}
+
+func (s *MakeSuite) TestCheckDefaultsMake(c *C) {
+	errs := CheckDefaults(&struct {
+		Valid   []Rule `default:"make:3"`
+		Invalid []Rule `default:"make:x"`
+		Bytes   []byte `default:"make:x"`
+	}{})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Invalid: invalid make length "make:x".*`)
+}
@@ -0,0 +1,81 @@
+package godefault
+
+import "reflect"
+
+// metaTag marks the field of the root struct of a fill that receives the
+// source of every leaf field the fill visited, by path, like a FillReport
+// that is serialized along with the values:
+//
+//	type Config struct {
+//	    Port         int               `default:"env:PORT:8080"`
+//	    DefaultsMeta map[string]string `godefault:"meta" json:"-"`
+//	}
+//
+// The sources are those of FieldReport, followed by the name of the variable
+// or key read for the env, var and kv ones, e.g. "env:PORT". The field must
+// be a map[string]string; it is never filled from a default, nor described
+// by the inspection functions. Meta fields of nested structs are left alone.
+const metaTag = "godefault"
+
+var metaType = reflect.TypeOf(map[string]string(nil))
+
+// isMetaField reports whether sf is the meta field of its struct.
+func isMetaField(sf reflect.StructField) bool {
+	return sf.Tag.Get(metaTag) == "meta" && sf.Type == metaType
+}
+
+// hasMetaField reports whether the struct type t has a meta field.
+func hasMetaField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if isMetaField(t.Field(i)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// metaMap returns the meta map of the root of the fill, allocated on first
+// use, or an invalid value when the root has no meta field.
+func (s *fillState) metaMap() reflect.Value {
+	if s.metaFound {
+		return s.meta
+	}
+	s.metaFound = true
+
+	root := s.root
+	if !root.IsValid() || root.Kind() != reflect.Struct {
+		return s.meta
+	}
+	for i := 0; i < root.NumField(); i++ {
+		if isMetaField(root.Type().Field(i)) && root.Field(i).CanSet() {
+			s.meta = root.Field(i)
+			if s.meta.IsNil() {
+				s.meta.Set(reflect.MakeMap(metaType))
+			}
+			break
+		}
+	}
+
+	return s.meta
+}
+
+// recordMeta records the source of the value of field in the meta map of the
+// fill, if any.
+func recordMeta(field *FieldData, source string) {
+	if field.state == nil {
+		return
+	}
+	meta := field.state.metaMap()
+	if !meta.IsValid() {
+		return
+	}
+
+	switch source {
+	case SourceEnv, SourceVar, SourceKV:
+		if field.sourceName != "" {
+			source += ":" + field.sourceName
+		}
+	}
+	meta.SetMapIndex(reflect.ValueOf(field.Path()), reflect.ValueOf(source))
+}
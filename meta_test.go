@@ -0,0 +1,80 @@
+package godefault
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type MetaSuite struct{}
+
+var _ = Suite(&MetaSuite{})
+
+type ExampleMeta struct {
+	Port   int    `default:"env:GODEFAULT_TEST_PORT:8080"`
+	Host   string `default:"localhost"`
+	User   string `default:"guest"`
+	Unset  string
+	Server struct {
+		Zone string            `default:"kv:config/zone"`
+		Meta map[string]string `godefault:"meta"`
+	}
+	DefaultsMeta map[string]string `godefault:"meta" default:"{a:b}"`
+}
+
+func (s *MetaSuite) TestMeta(c *C) {
+	os.Setenv("GODEFAULT_TEST_PORT", "9000")
+	defer os.Unsetenv("GODEFAULT_TEST_PORT")
+
+	foo := &ExampleMeta{User: "admin"}
+	filler := NewFiller(WithKeyValueSource("", MapSource{"config/zone": "eu"}))
+	c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.DefaultsMeta, DeepEquals, map[string]string{
+		"Port":        "env:GODEFAULT_TEST_PORT",
+		"Host":        SourceTag,
+		"User":        SourcePreset,
+		"Unset":       SourceNone,
+		"Server.Zone": "kv:config/zone",
+	})
+	c.Assert(foo.Server.Meta, IsNil)
+
+	data, err := json.Marshal(foo)
+	c.Assert(err, IsNil)
+	bar := &ExampleMeta{}
+	c.Assert(json.Unmarshal(data, bar), IsNil)
+	c.Assert(bar.DefaultsMeta["Port"], Equals, "env:GODEFAULT_TEST_PORT")
+
+	plan, err := filler.Compile(reflect.TypeOf(ExampleMeta{}))
+	c.Assert(err, IsNil)
+	foo = &ExampleMeta{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.DefaultsMeta["Host"], Equals, SourceTag)
+}
+
+func (s *MetaSuite) TestMetaApply(c *C) {
+	os.Setenv("GODEFAULT_TEST_HOST", "example.com")
+	defer os.Unsetenv("GODEFAULT_TEST_HOST")
+
+	foo := &ExampleMeta{}
+	filler := NewFiller(WithKeyValueSource("", MapSource{}))
+	c.Assert(filler.Apply(foo, EnvLayer("godefault_test"), TagLayer()), IsNil)
+	c.Assert(foo.Host, Equals, "example.com")
+	c.Assert(foo.DefaultsMeta["Host"], Equals, "env:GODEFAULT_TEST_HOST")
+	c.Assert(foo.DefaultsMeta["Port"], Equals, "tag")
+}
+
+func (s *MetaSuite) TestMetaExcluded(c *C) {
+	fields, err := ListDefaultFields(&ExampleMeta{})
+	c.Assert(err, IsNil)
+	for _, field := range fields {
+		c.Assert(field.Field.Name, Not(Equals), "DefaultsMeta")
+	}
+	c.Assert(CheckDefaults(&ExampleMeta{}), HasLen, 0)
+
+	diffs, err := DiffFromDefaults(&ExampleMeta{Port: 8080, Host: "localhost", User: "guest"})
+	c.Assert(err, IsNil)
+	c.Assert(diffs, HasLen, 0)
+}
@@ -0,0 +1,110 @@
+package godefault
+
+import "reflect"
+
+// SourceMethod is a value set by the SetDefault or Default method of the
+// type of an untagged field, see defaultOf.
+const SourceMethod = "method"
+
+// defaultSetter is implemented by the types setting their own default, when
+// called on a pointer to their zero value.
+type defaultSetter interface {
+	SetDefault()
+}
+
+// defaultMethod is the name of the method returning the default of a type,
+// see defaultOf.
+const defaultMethod = "Default"
+
+// defaultOf returns the default the type t gives itself, which the fills use
+// for the zero fields of type t that have no default tag at all:
+//
+//	SetDefault()  on *T, called on a pointer to the zero value
+//	Default() T   on T or *T, the result set as it is
+//
+// For a field of type *T the methods of T count too, on a T allocated for
+// the field. An explicit tag, even `default:""`, wins over the methods.
+func defaultOf(value reflect.Value) (reflect.Value, bool) {
+	t := value.Type()
+	if v, ok := methodDefault(value, t); ok {
+		return v, true
+	}
+	if t.Kind() != reflect.Ptr {
+		return reflect.Value{}, false
+	}
+
+	elem := reflect.New(t.Elem())
+	v, ok := methodDefault(elem.Elem(), t.Elem())
+	if !ok {
+		return reflect.Value{}, false
+	}
+	elem.Elem().Set(v)
+
+	return elem, true
+}
+
+// methodDefault returns the default of value, of type t, set by its
+// SetDefault method, or returned by its Default one.
+func methodDefault(value reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	p := reflect.New(t)
+	if value.CanAddr() {
+		p = value.Addr()
+	}
+	if setter, ok := p.Interface().(defaultSetter); ok {
+		setter.SetDefault()
+		return p.Elem(), true
+	}
+
+	m := p.MethodByName(defaultMethod)
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 || m.Type().Out(0) != t {
+		return reflect.Value{}, false
+	}
+
+	return m.Call(nil)[0], true
+}
+
+// hasDefaultMethod reports whether the type t, or the type it points to,
+// gives itself a default, see defaultOf.
+func hasDefaultMethod(t reflect.Type) bool {
+	for _, t := range []reflect.Type{t, derefType(t)} {
+		p := reflect.PtrTo(t)
+		if p.Implements(reflect.TypeOf((*defaultSetter)(nil)).Elem()) {
+			return true
+		}
+		if m, ok := p.MethodByName(defaultMethod); ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 && m.Type.Out(0) == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasMethodDefaultField reports whether one of the fields of the struct type
+// t has a type giving itself a default.
+func hasMethodDefaultField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if hasDefaultMethod(t.Field(i).Type) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setMethodDefault sets field, zero and untagged, to the default of its
+// type, reporting whether its type has one, see defaultOf.
+func (f *Filler) setMethodDefault(field *FieldData) bool {
+	if _, tagged := field.Field.Tag.Lookup(f.Tag); tagged || field.notTag || !field.Value.IsZero() || !hasDefaultMethod(field.Value.Type()) {
+		return false
+	}
+	if field.recovers() {
+		defer field.recoverPanic()
+	}
+
+	v, ok := defaultOf(field.Value)
+	if ok {
+		field.Value.Set(v)
+	}
+
+	return ok
+}
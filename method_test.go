@@ -0,0 +1,87 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type MethodSuite struct{}
+
+var _ = Suite(&MethodSuite{})
+
+type exampleRetry struct {
+	Attempts int
+	Backoff  string `default:"exponential"`
+}
+
+func (r *exampleRetry) SetDefault() {
+	r.Attempts = 3
+}
+
+type examplePort int
+
+func (examplePort) Default() examplePort {
+	return 8080
+}
+
+type exampleLabels map[string]string
+
+func (*exampleLabels) Default() exampleLabels {
+	return exampleLabels{"team": "core"}
+}
+
+type examplePanicky string
+
+func (examplePanicky) Default() examplePanicky {
+	panic("no default")
+}
+
+type ExampleMethod struct {
+	Retry    exampleRetry
+	Port     examplePort
+	Tagged   examplePort `default:"9090"`
+	Empty    examplePort `default:""`
+	Pointer  *examplePort
+	Labels   exampleLabels
+	Preset   examplePort
+	Untagged int
+}
+
+func (s *MethodSuite) TestDefaultMethods(c *C) {
+	report := &FillReport{}
+	foo := &ExampleMethod{Preset: 1}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Retry, Equals, exampleRetry{Attempts: 3, Backoff: "exponential"})
+	c.Assert(foo.Port, Equals, examplePort(8080))
+	c.Assert(foo.Tagged, Equals, examplePort(9090))
+	c.Assert(foo.Empty, Equals, examplePort(0))
+	c.Assert(*foo.Pointer, Equals, examplePort(8080))
+	c.Assert(foo.Labels, DeepEquals, exampleLabels{"team": "core"})
+	c.Assert(foo.Preset, Equals, examplePort(1))
+	c.Assert(foo.Untagged, Equals, 0)
+
+	sources := map[string]string{}
+	for _, field := range report.Fields {
+		sources[field.Path] = field.Source
+	}
+	c.Assert(sources["Port"], Equals, SourceMethod)
+	c.Assert(sources["Pointer"], Equals, SourceMethod)
+	c.Assert(sources["Tagged"], Equals, SourceTag)
+	c.Assert(sources["Preset"], Equals, SourcePreset)
+
+	plan, err := Compile(reflect.TypeOf(ExampleMethod{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleMethod{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Port, Equals, examplePort(8080))
+	c.Assert(bar.Retry.Attempts, Equals, 3)
+}
+
+func (s *MethodSuite) TestDefaultMethodPanic(c *C) {
+	foo := &struct {
+		Name examplePanicky
+	}{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), ErrorMatches, `Name: panic: no default`)
+}
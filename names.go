@@ -0,0 +1,58 @@
+package godefault
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// defaultNameTags is the priority of the tags naming a field in config files
+// and environment variables, before falling back to the field name.
+var defaultNameTags = []string{"yaml", "toml", "json"}
+
+// externalName returns the key naming sf in a config file, taken from the
+// first of nameTags present on sf and stripped of its options, e.g.
+// `yaml:"bind_addr,omitempty"` is "bind_addr". It reports false when the
+// field is excluded with "-", and whether the field is inlined: embedded
+// structs without an explicit name, or tagged ",inline".
+func externalName(sf reflect.StructField, nameTags []string) (name string, inline bool, ok bool) {
+	for _, tag := range nameTags {
+		value, found := sf.Tag.Lookup(tag)
+		if !found {
+			continue
+		}
+		if value == "-" {
+			return "", false, false
+		}
+
+		parts := strings.Split(value, ",")
+		for _, option := range parts[1:] {
+			if option == "inline" {
+				inline = true
+			}
+		}
+		if parts[0] != "" {
+			return parts[0], inline, true
+		}
+		break
+	}
+
+	return sf.Name, inline || sf.Anonymous, true
+}
+
+// envName derives the envconfig-style variable name of the field at names,
+// e.g. MYAPP_SERVER_BIND_ADDR for the prefix "myapp" and the key
+// server.bind_addr.
+func envName(prefix string, names []string) string {
+	parts := names
+	if prefix != "" {
+		parts = append([]string{prefix}, names...)
+	}
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, strings.Join(parts, "_"))
+}
@@ -0,0 +1,43 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// noopDefault is the only default of func fields, which installs a function
+// doing nothing but returning the zero values of its results, so that
+// optional callbacks can be called without a nil check:
+//
+//	OnReload func(cfg *Config) error `default:"noop"`
+const noopDefault = "noop"
+
+// makeNoop returns a function of type t returning zero values.
+func makeNoop(t reflect.Type) reflect.Value {
+	results := make([]reflect.Value, t.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(t.Out(i))
+	}
+
+	return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		return results
+	})
+}
+
+// checkFuncValue validates the default of a func field.
+func checkFuncValue(value string) error {
+	if value != noopDefault {
+		return fmt.Errorf("invalid func value %q, expected %q", value, noopDefault)
+	}
+
+	return nil
+}
+
+// fillFunc sets the func field from its default, see noopDefault.
+func fillFunc(field *FieldData) {
+	err := checkFuncValue(field.TagValue)
+	field.check(err)
+	if err == nil && field.TagValue != "" {
+		field.Value.Set(makeNoop(field.Value.Type()))
+	}
+}
@@ -0,0 +1,45 @@
+package godefault
+
+import (
+	"context"
+
+	. "gopkg.in/check.v1"
+)
+
+type NoopSuite struct{}
+
+var _ = Suite(&NoopSuite{})
+
+type ExampleCallbacks struct {
+	OnStart  func()                                  `default:"noop"`
+	OnReload func(path string) (int, error)          `default:"noop"`
+	Format   func(format string, args ...int) string `default:"noop"`
+	OnStop   func()
+	Set      func() int `default:"noop"`
+}
+
+func (s *NoopSuite) TestNoop(c *C) {
+	foo := &ExampleCallbacks{Set: func() int { return 1 }}
+	c.Assert(NewFiller().FillContext(context.Background(), foo), IsNil)
+
+	foo.OnStart()
+	n, err := foo.OnReload("config.yaml")
+	c.Assert(n, Equals, 0)
+	c.Assert(err, IsNil)
+	c.Assert(foo.Format("%d", 1, 2), Equals, "")
+	c.Assert(foo.OnStop, IsNil)
+	c.Assert(foo.Set(), Equals, 1)
+}
+
+func (s *NoopSuite) TestNoopInvalid(c *C) {
+	foo := &struct {
+		OnStart func() `default:"nil"`
+	}{}
+	c.Assert(NewFiller().FillContext(context.Background(), foo), ErrorMatches, `OnStart: invalid func value "nil", expected "noop"`)
+	c.Assert(foo.OnStart, IsNil)
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `OnStart: invalid func value "nil", expected "noop"`)
+	c.Assert(CheckDefaults(&ExampleCallbacks{}), HasLen, 0)
+}
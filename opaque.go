@@ -0,0 +1,101 @@
+package godefault
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// opaqueTypes are the struct types the built-in fillers parse from a tag as a
+// whole rather than descending into their fields, see RegisterOpaque.
+var opaqueTypes = builtinOpaqueTypes()
+
+func builtinOpaqueTypes() map[reflect.Type]bool {
+	types := map[reflect.Type]bool{timeType: true, regexpType: true, syncMapType: true, hostPortType: true}
+	for _, nullType := range nullTypes {
+		types[nullType] = true
+	}
+
+	return types
+}
+
+// RegisterOpaque makes the fills of f fill the values of type t, usually a
+// struct such as a date or an amount of money, from their tag as a whole
+// rather than descending into their fields. The tag is handed to the filler
+// registered for t in FuncByType, or else to the UnmarshalText method of *t:
+//
+//	filler := godefault.NewFiller()
+//	filler.RegisterOpaque(reflect.TypeOf(civil.Date{}))
+//
+//	Since civil.Date `default:"2024-01-31"`
+//
+// A tagged value of t that has neither fails. The values of t are reported
+// like other leaf fields, and Restrict-like, RegisterOpaque is meant to be
+// called before f is used. It panics on the frozen default filler, see
+// FreezeDefaultFiller. time.Time, regexp.Regexp, sync.Map, HostPort and the
+// database/sql Null types are opaque to every Filler.
+func (f *Filler) RegisterOpaque(t reflect.Type) {
+	if f.frozen != nil {
+		panic("godefault: RegisterOpaque on the frozen default filler, use a Filler of your own")
+	}
+	if f.opaque == nil {
+		f.opaque = make(map[reflect.Type]bool)
+	}
+	f.opaque[t] = true
+}
+
+// isStructType is the package level isStructType, also false for the types
+// registered opaque with f. f may be nil.
+func (f *Filler) isStructType(t reflect.Type) bool {
+	return isStructType(t) && (f == nil || !f.opaque[t])
+}
+
+func (f *Filler) getFunctionByOpaque(field *FieldData) FillerFunc {
+	if f.opaque[field.Field.Type] {
+		return fillOpaque
+	}
+
+	return nil
+}
+
+// fillOpaque fills field, of a type registered opaque, with the UnmarshalText
+// method of its type.
+func fillOpaque(field *FieldData) {
+	if field.TagValue == "" {
+		return
+	}
+
+	value := reflect.New(field.Value.Type())
+	if err := unmarshalOpaque(value, field.TagValue); err != nil {
+		field.fail(parseErrorOf(err))
+		return
+	}
+	field.Value.Set(value.Elem())
+}
+
+// unmarshalOpaque parses value into p, a pointer to a value of an opaque
+// type.
+func unmarshalOpaque(p reflect.Value, value string) error {
+	if !p.Type().Implements(textUnmarshalerType) {
+		return fmt.Errorf("%s is opaque but has neither a filler nor an UnmarshalText method", p.Type().Elem())
+	}
+
+	return p.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+}
+
+// checkOpaque validates the default of a field of type t when f registered
+// it opaque, reporting false when it didn't. Types with a filler of their own
+// can't be checked.
+func (f *Filler) checkOpaque(t reflect.Type, value string) (error, bool) {
+	t = derefType(t)
+	if f == nil || !f.opaque[t] {
+		return nil, false
+	}
+	if _, ok := f.FuncByType[GetTypeHash(t)]; ok {
+		return nil, true
+	}
+
+	return unmarshalOpaque(reflect.New(t), value), true
+}
@@ -0,0 +1,100 @@
+package godefault
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type OpaqueSuite struct{}
+
+var _ = Suite(&OpaqueSuite{})
+
+type exampleDate struct {
+	year, month, day int
+}
+
+func (d *exampleDate) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d-%d-%d", &d.year, &d.month, &d.day)
+	return err
+}
+
+type ExampleMoney struct {
+	Units    int64  `default:"1"`
+	Currency string `default:"USD"`
+}
+
+type ExampleOpaque struct {
+	Since   exampleDate  `default:"2024-01-31"`
+	Until   *exampleDate `default:"2025-06-01"`
+	Price   ExampleMoney `default:"12.50 EUR"`
+	Budget  ExampleMoney
+	Default ExampleMoney `default:"-"`
+}
+
+func newOpaqueFiller(opts ...Option) *Filler {
+	filler := NewFiller(opts...)
+	filler.RegisterOpaque(reflect.TypeOf(exampleDate{}))
+	filler.RegisterOpaque(reflect.TypeOf(ExampleMoney{}))
+	filler.FuncByType[GetTypeHash(reflect.TypeOf(ExampleMoney{}))] = func(field *FieldData) {
+		var units, cents int64
+		var currency string
+		_, err := fmt.Sscanf(field.TagValue, "%d.%d %s", &units, &cents, &currency)
+		field.check(err)
+		field.Value.Set(reflect.ValueOf(ExampleMoney{Units: units*100 + cents, Currency: currency}))
+	}
+
+	return filler
+}
+
+func (s *OpaqueSuite) TestRegisterOpaque(c *C) {
+	report := &FillReport{}
+	foo := &ExampleOpaque{}
+	c.Assert(newOpaqueFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Since, Equals, exampleDate{2024, 1, 31})
+	c.Assert(*foo.Until, Equals, exampleDate{2025, 6, 1})
+	c.Assert(foo.Price, Equals, ExampleMoney{Units: 1250, Currency: "EUR"})
+	// Opaque values are not descended into, tagged or not.
+	c.Assert(foo.Budget, Equals, ExampleMoney{})
+	c.Assert(report.Fields, HasLen, 4)
+	c.Assert(report.Fields[2].Path, Equals, "Price")
+	c.Assert(report.Fields[3], DeepEquals, FieldReport{Path: "Budget", Source: SourceNone, Value: "{0 }"})
+
+	// Set pointers are left alone.
+	until := &exampleDate{year: 2000}
+	foo = &ExampleOpaque{Until: until}
+	c.Assert(newOpaqueFiller().FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Until, Equals, until)
+	c.Assert(*until, Equals, exampleDate{year: 2000})
+
+	bar := &ExampleOpaque{}
+	c.Assert(newOpaqueFiller().Apply(bar, MapLayer(map[string]string{"since": "1999-12-31"}), TagLayer()), IsNil)
+	c.Assert(bar.Since, Equals, exampleDate{1999, 12, 31})
+	c.Assert(bar.Price.Units, Equals, int64(1250))
+
+	fields, err := ListDefaultFields(&ExampleOpaque{})
+	c.Assert(err, IsNil)
+	c.Assert(len(fields) > 3, Equals, true)
+	c.Assert(newOpaqueFiller().CheckDefaults(&ExampleOpaque{}), HasLen, 0)
+
+	// Without the registration, the fields of the struct are filled.
+	baz := &ExampleOpaque{}
+	SetDefaults(baz)
+	c.Assert(baz.Budget, Equals, ExampleMoney{Units: 1, Currency: "USD"})
+}
+
+func (s *OpaqueSuite) TestRegisterOpaqueErrors(c *C) {
+	foo := &struct {
+		Since exampleDate `default:"yesterday"`
+		Plain ExampleMoney
+		Other ExampleOpaque `default:"x"`
+	}{}
+	filler := newOpaqueFiller()
+	filler.RegisterOpaque(reflect.TypeOf(ExampleOpaque{}))
+	err := filler.FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Since: expected integer\n`+
+		`Other: godefault.ExampleOpaque is opaque but has neither a filler nor an UnmarshalText method`)
+	c.Assert(filler.CheckDefaults(foo), HasLen, 2)
+}
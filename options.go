@@ -0,0 +1,268 @@
+package godefault
+
+import "reflect"
+
+// Option configures a Filler created with NewFiller.
+type Option func(*Filler)
+
+// NewFiller returns a Filler with the same fillers SetDefaults uses,
+// configured by opts. Unlike the filler shared by SetDefaults, it can be
+// customized without affecting other callers.
+func NewFiller(opts ...Option) *Filler {
+	f := newDefaultFiller()
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// WithTag sets the struct tag the default values are read from.
+func WithTag(name string) Option {
+	return func(f *Filler) {
+		f.Tag = name
+	}
+}
+
+// WithProtoCompat forces the protobuf compatibility mode for every struct,
+// see isProtoMessage for when it is enabled automatically.
+func WithProtoCompat() Option {
+	return func(f *Filler) {
+		f.protoCompat = true
+	}
+}
+
+// WithStrict makes the error-returning fills, such as FillContext, report
+// the fields tagged `required:"true"` that the fill left unset. A field is
+// set when it had a value already or got one from its default; an explicit
+// empty `default:""` also counts, it means the zero value is intended.
+func WithStrict() Option {
+	return func(f *Filler) {
+		f.strict = true
+	}
+}
+
+// WithNameTags sets the tags naming the fields in the exported defaults, by
+// priority, see ExtractDefaults. The default is yaml, toml then json.
+func WithNameTags(tags ...string) Option {
+	return func(f *Filler) {
+		f.nameTags = tags
+	}
+}
+
+// WithPostValidate sets a callback run for every field visited by the fill,
+// tagged or not, once its default (if any) was applied. Nested fields are
+// validated before the struct holding them. The errors returned by validate
+// are reported by FillContext, prefixed with the path of the field, and
+// ignored by Fill.
+//
+// Usage
+//
+//	validate := validator.New()
+//	filler := NewFiller(WithPostValidate(func(path string, value reflect.Value, field reflect.StructField) error {
+//	    return validate.Var(value.Interface(), field.Tag.Get("validate"))
+//	}))
+func WithPostValidate(validate func(path string, value reflect.Value, field reflect.StructField) error) Option {
+	return func(f *Filler) {
+		f.postValidate = validate
+	}
+}
+
+// WithUnsafeUnexported makes the fills set the unexported fields carrying a
+// default tag too, which reflection alone refuses, by writing them through
+// package unsafe. It is meant for legacy structs that can't be changed, and
+// comes with the usual caveats of unsafe: it bypasses the encapsulation of
+// the type, the invariants its methods rely on and any synchronization
+// guarding the field, and the fields of values that aren't addressable are
+// still skipped. Building with the godefault_nounsafe tag removes the unsafe
+// code, the option then being ignored. Without it, unexported fields are
+// always skipped.
+func WithUnsafeUnexported() Option {
+	return func(f *Filler) {
+		f.unexported = true
+	}
+}
+
+// WithPreprocessor sets a function rewriting the tag of every field before it
+// is parsed, e.g. to expand a macro syntax of your own. preprocess receives
+// the dotted path of the field and its raw tag, and returns the tag to use.
+// It runs before anything else, env: references and envs| mappings and date
+// placeholders included, and is not run for the values given to Apply or
+// ApplyArgs. CheckDefaults and the exporters, which don't use a Filler, see
+// the raw tags.
+func WithPreprocessor(preprocess func(fieldPath, rawTag string) string) Option {
+	return func(f *Filler) {
+		f.preprocess = preprocess
+	}
+}
+
+// WithFieldFilter sets a predicate consulted for every field before it is
+// filled; the fields it returns false for are skipped, nested structs and
+// slices with all their fields, e.g. to default a single section:
+//
+//	NewFiller(WithFieldFilter(func(fd *FieldData) bool {
+//	    return strings.HasPrefix(fd.Path(), "Server")
+//	}))
+//
+// The predicate sees the raw tag of the field. Apply skips the filtered
+// fields for every layer, ApplyArgs only for their defaults.
+func WithFieldFilter(filter func(fd *FieldData) bool) Option {
+	return func(f *Filler) {
+		f.filter = filter
+	}
+}
+
+// WithEmptyEnvIsSet chooses whether an environment variable set to an empty
+// value counts as set, resolving to the empty value, or as unset, which is
+// the default: env: and envindirect: references then use their fallback,
+// jwtclaim: references nothing and EnvLayer leaves the field to the next
+// layer. An envs| mapping uses its first entry either way, no entry can be
+// named after the empty value. Defaults read from gogmap count as unset when
+// empty either way. The interpretation applied is reported, see
+// FieldReport.EmptyEnv.
+func WithEmptyEnvIsSet(set bool) Option {
+	return func(f *Filler) {
+		f.emptyEnvIsSet = set
+	}
+}
+
+// WithEnvRequired chooses whether the environment is authoritative: an env:
+// or envindirect: reference without a fallback whose variable is unset, or
+// an envs| mapping whose variable is unset, then fails its field with an
+// ErrRequired, the field left alone, rather than leave it empty or use the
+// first entry of the mapping, which is the default. References with a
+// fallback use it either way:
+//
+//	filler := godefault.NewFiller(godefault.WithEnvRequired(true))
+//	err := filler.FillContext(ctx, &cfg) // Token: required field is not set: $TOKEN is unset
+//
+// Whether an empty variable is unset follows WithEmptyEnvIsSet.
+func WithEnvRequired(required bool) Option {
+	return func(f *Filler) {
+		f.envRequired = required
+	}
+}
+
+// WithJSONNumber decodes the numbers of json: defaults as json.Number rather
+// than float64, which keeps large integers exact, see jsonPrefix.
+func WithJSONNumber() Option {
+	return func(f *Filler) {
+		f.jsonNumber = true
+	}
+}
+
+// WithHonorJSONDash skips the fields tagged `json:"-"`, usually runtime state
+// such as mutexes and caches, even when they have a default tag: the fills
+// leave them alone, reporting them as SourceJSONDash, and ListDefaultFields
+// and the Extract functions leave them out. Fields the json tag only renames
+// are filled as usual.
+func WithHonorJSONDash(honor bool) Option {
+	return func(f *Filler) {
+		f.honorJSONDash = honor
+	}
+}
+
+// WithRequireMarker restricts the defaults to the fields carrying the marker
+// tag, for structs whose default tags are also read by another library:
+//
+//	type Config struct {
+//	    Port  int    `default:"8080" config:"true"`
+//	    Stage string `default:"build"` // left alone
+//	}
+//
+//	NewFiller(WithRequireMarker("config"))
+//
+// A field is marked by the tag with any value but "", "-" and "false". Nested
+// structs, pointers to them and slices of them are descended into unless
+// they carry the tag unmarked, the marker applies to the fields in them. The
+// fields left unmarked are skipped like those of WithFieldFilter, and left
+// out of the descriptions of the defaults too.
+func WithRequireMarker(tag string) Option {
+	return func(f *Filler) {
+		f.marker = tag
+	}
+}
+
+// marks reports whether f fills the field sf, see WithRequireMarker.
+func (f *Filler) marks(sf reflect.StructField) bool {
+	if f.marker == "" {
+		return true
+	}
+	value, ok := sf.Tag.Lookup(f.marker)
+	if !ok {
+		return f.isDescended(sf.Type)
+	}
+	switch value {
+	case "", "-", "false":
+		return false
+	}
+
+	return true
+}
+
+// skipsJSONDash reports whether f skips the field sf, see WithHonorJSONDash.
+func (f *Filler) skipsJSONDash(sf reflect.StructField) bool {
+	return f.honorJSONDash && sf.Tag.Get("json") == "-"
+}
+
+// WithKinds restricts the fills to the fields of the listed kinds, pointers
+// counting as the kind they point to, for example to only default the
+// numbers of a struct:
+//
+//	godefault.NewFiller(godefault.WithKinds(reflect.Int, reflect.Float64))
+//
+// The fields of other kinds are left alone and reported as
+// SourceSkippedKind; leaving out reflect.Struct stops the descent into
+// nested structs, time.Time ones included. Without WithKinds all kinds are
+// filled.
+func WithKinds(kinds ...reflect.Kind) Option {
+	return func(f *Filler) {
+		f.kinds = make(map[reflect.Kind]bool, len(kinds))
+		for _, kind := range kinds {
+			f.kinds[kind] = true
+		}
+	}
+}
+
+// skipsKind reports whether f skips the field sf for its kind, see
+// WithKinds.
+func (f *Filler) skipsKind(sf reflect.StructField) bool {
+	return f.kinds != nil && !f.kinds[derefType(sf.Type).Kind()]
+}
+
+// skipSource returns the Source the fills report the field sf they skip
+// under, see WithHonorJSONDash and WithKinds, or "" when they don't skip
+// it.
+func (f *Filler) skipSource(sf reflect.StructField) string {
+	switch {
+	case f.skipsJSONDash(sf):
+		return SourceJSONDash
+	case f.skipsKind(sf):
+		return SourceSkippedKind
+	}
+
+	return ""
+}
+
+// WithMaxSliceFill caps the elements of each slice of structs the fills and
+// Apply descend into to the first max, as a guard against configurations
+// holding huge slices: the elements after them are left untouched, neither filled
+// nor reported. It only limits the descent into the elements, a slice made
+// by its default, e.g. `default:"make:1000"` or a json: one, still gets all
+// of its elements, the ones after the first max zero for make:. 0, the
+// default, doesn't cap them.
+func WithMaxSliceFill(max int) Option {
+	return func(f *Filler) {
+		f.maxSliceFill = max
+	}
+}
+
+// sliceFillCount returns how many of the n elements of a slice of structs
+// the fills of f descend into, see WithMaxSliceFill.
+func (f *Filler) sliceFillCount(n int) int {
+	if f.maxSliceFill > 0 && n > f.maxSliceFill {
+		return f.maxSliceFill
+	}
+
+	return n
+}
@@ -0,0 +1,366 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type OptionsSuite struct{}
+
+var _ = Suite(&OptionsSuite{})
+
+type ExampleValidate struct {
+	Name    string `default:"app"`
+	Port    int
+	Server  Child
+	Peers   []Child
+	Ignored int `default:"-"`
+}
+
+func (s *OptionsSuite) TestWithPostValidate(c *C) {
+	var paths []string
+	filler := NewFiller(WithPostValidate(func(path string, value reflect.Value, field reflect.StructField) error {
+		paths = append(paths, path)
+		if field.Name == "Port" && value.Int() == 0 {
+			return errors.New("required")
+		}
+		if path == "Name" && value.String() != "app" {
+			return errors.New("default not applied")
+		}
+		return nil
+	}))
+
+	foo := &ExampleValidate{Peers: []Child{{Name: "alice"}}}
+	err := filler.FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, "Port: required")
+	c.Assert(paths, DeepEquals, []string{
+		"Name",
+		"Port",
+		"Server.Name",
+		"Server.Age",
+		"Server",
+		"Peers[0].Name",
+		"Peers[0].Age",
+		"Peers",
+	})
+
+	// Fill ignores the errors, and plans validate too.
+	paths = nil
+	filler.Fill(&ExampleValidate{})
+	c.Assert(paths, HasLen, 6)
+
+	plan, err := filler.Compile(reflect.TypeOf(ExampleValidate{}))
+	c.Assert(err, IsNil)
+	c.Assert(plan.Apply(&ExampleValidate{Port: 1}), IsNil)
+}
+
+type ExamplePreprocess struct {
+	Port   int      `default:"@port"`
+	Name   string   `default:"app"`
+	Hosts  []string `default:"[@a,b]"`
+	Server struct {
+		Port int `default:"@port"`
+	}
+}
+
+func (s *OptionsSuite) TestWithPreprocessor(c *C) {
+	var paths []string
+	filler := NewFiller(WithPreprocessor(func(fieldPath, rawTag string) string {
+		paths = append(paths, fieldPath)
+		if rawTag == "@port" {
+			return "env:GODEFAULT_TEST_UNSET:8080|+1"
+		}
+		return rawTag
+	}))
+
+	foo := &ExamplePreprocess{Name: "preset"}
+	c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Port, Equals, 8081)
+	c.Assert(foo.Name, Equals, "preset")
+	c.Assert(foo.Hosts, DeepEquals, []string{"@a", "b"})
+	c.Assert(foo.Server.Port, Equals, 8081)
+	c.Assert(paths, DeepEquals, []string{"Port", "Hosts", "Server", "Server.Port"})
+
+	// Values given to Apply are not tags.
+	bar := &ExamplePreprocess{}
+	c.Assert(filler.Apply(bar, MapLayer(map[string]string{"port": "@port"})), ErrorMatches, `Port: strconv.ParseInt: parsing "@port": invalid syntax`)
+}
+
+type ExampleFilter struct {
+	Name       string `default:"app"`
+	Deprecated string `default:"old" deprecated:"true"`
+	Server     struct {
+		Host string `default:"localhost"`
+		Port int    `default:"8080"`
+	}
+	Client struct {
+		Port int `default:"9090"`
+	}
+}
+
+func (s *OptionsSuite) TestWithFieldFilter(c *C) {
+	filler := NewFiller(WithFieldFilter(func(fd *FieldData) bool {
+		return fd.Field.Tag.Get("deprecated") == "" && fd.Path() != "Client.Port"
+	}))
+
+	foo := &ExampleFilter{}
+	filler.Fill(foo)
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.Deprecated, Equals, "")
+	c.Assert(foo.Server.Port, Equals, 8080)
+	c.Assert(foo.Client.Port, Equals, 0)
+
+	plan, err := filler.Compile(reflect.TypeOf(ExampleFilter{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleFilter{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(*bar, DeepEquals, *foo)
+}
+
+func (s *OptionsSuite) TestWithFieldFilterSection(c *C) {
+	filler := NewFiller(WithFieldFilter(func(fd *FieldData) bool {
+		return strings.HasPrefix(fd.Path(), "Server")
+	}))
+
+	foo := &ExampleFilter{}
+	c.Assert(filler.Apply(foo, MapLayer(map[string]string{"name": "x", "server.host": "example.com"}), TagLayer()), IsNil)
+	c.Assert(foo.Name, Equals, "")
+	c.Assert(foo.Server.Host, Equals, "example.com")
+	c.Assert(foo.Server.Port, Equals, 8080)
+	c.Assert(foo.Client.Port, Equals, 0)
+}
+
+type ExampleMarkerServer struct {
+	Host string `default:"localhost"`
+	Port int    `default:"8080" config:"true"`
+}
+
+type ExampleMarker struct {
+	Name    string `default:"app" config:"true"`
+	Stage   string `default:"build"`
+	Level   int    `default:"high" config:"false"`
+	Server  ExampleMarkerServer
+	Servers []ExampleMarkerServer `default:"make:1"`
+	Backups []ExampleMarkerServer `default:"make:1" config:"-"`
+}
+
+func (s *OptionsSuite) TestWithRequireMarker(c *C) {
+	filler := NewFiller(WithRequireMarker("config"))
+	foo := &ExampleMarker{}
+	filler.Fill(foo)
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.Stage, Equals, "")
+	c.Assert(foo.Level, Equals, 0)
+	c.Assert(foo.Server, DeepEquals, ExampleMarkerServer{Port: 8080})
+	c.Assert(foo.Servers, HasLen, 1)
+	c.Assert(foo.Servers[0], DeepEquals, ExampleMarkerServer{Port: 8080})
+	c.Assert(foo.Backups, IsNil)
+
+	plan, err := filler.Compile(reflect.TypeOf(ExampleMarker{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleMarker{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(*bar, DeepEquals, *foo)
+
+	bar = &ExampleMarker{}
+	c.Assert(filler.Apply(bar, MapLayer(map[string]string{"stage": "x", "server.host": "h"}), TagLayer()), IsNil)
+	c.Assert(bar.Stage, Equals, "")
+	c.Assert(bar.Server, DeepEquals, ExampleMarkerServer{Port: 8080})
+
+	fields, err := ListDefaultFields(&ExampleMarker{}, WithRequireMarker("config"))
+	c.Assert(err, IsNil)
+	var paths []string
+	for _, field := range fields {
+		paths = append(paths, field.Path)
+	}
+	c.Assert(paths, DeepEquals, []string{"Name", "Server.Port", "Servers", "Servers[].Port"})
+	c.Assert(filler.CheckDefaults(&ExampleMarker{}), HasLen, 0)
+	c.Assert(CheckDefaults(&ExampleMarker{}), HasLen, 1)
+}
+
+type ExampleJSONDashCache struct {
+	Size int `default:"128"`
+}
+
+type ExampleJSONDash struct {
+	Name    string               `default:"app" json:"name"`
+	Renamed string               `default:"dash" json:"-,"`
+	Cache   map[string]string    `default:"{a:b}" json:"-"`
+	Retries int                  `default:"env:GODEFAULT_TEST_RETRIES:3" json:"-"`
+	State   ExampleJSONDashCache `json:"-"`
+}
+
+func (s *OptionsSuite) TestWithHonorJSONDash(c *C) {
+	foo := &ExampleJSONDash{}
+	SetDefaults(foo)
+	c.Assert(foo.Retries, Equals, 3)
+	c.Assert(foo.State.Size, Equals, 128)
+
+	report := &FillReport{}
+	filler := NewFiller(WithHonorJSONDash(true), WithReport(report))
+	foo = &ExampleJSONDash{}
+	c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.Renamed, Equals, "dash")
+	c.Assert(foo.Cache, IsNil)
+	c.Assert(foo.Retries, Equals, 0)
+	c.Assert(foo.State.Size, Equals, 0)
+
+	// The nested struct is neither filled nor reported.
+	c.Assert(report.Fields, HasLen, 4)
+	c.Assert(report.Fields[2], DeepEquals, FieldReport{Path: "Cache", Source: SourceJSONDash, Value: "map[]", Tag: "{a:b}"})
+	c.Assert(report.Fields[3].Path, Equals, "Retries")
+	c.Assert(report.Fields[3].Source, Equals, "skipped: json-dash")
+
+	report.Fields = nil
+	bar := &ExampleJSONDash{}
+	c.Assert(filler.Apply(bar, TagLayer()), IsNil)
+	c.Assert(report.Fields, HasLen, 4)
+	for _, field := range report.Fields {
+		c.Assert(field.Source == SourceJSONDash, Equals, field.Path == "Cache" || field.Path == "Retries", Commentf("%s", field.Path))
+	}
+	c.Assert(bar.Retries, Equals, 0)
+	c.Assert(bar.Name, Equals, "app")
+
+	plan, err := Compile(reflect.TypeOf(ExampleJSONDash{}), WithHonorJSONDash(true))
+	c.Assert(err, IsNil)
+	bar = &ExampleJSONDash{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Retries, Equals, 0)
+	c.Assert(bar.Renamed, Equals, "dash")
+
+	fields, err := ListDefaultFields(&ExampleJSONDash{}, WithHonorJSONDash(true))
+	c.Assert(err, IsNil)
+	c.Assert(fields, HasLen, 2)
+	c.Assert(fields[1].Path, Equals, "Renamed")
+
+	defaults, err := ExtractDefaults(&ExampleJSONDash{}, WithHonorJSONDash(true))
+	c.Assert(err, IsNil)
+	c.Assert(defaults, DeepEquals, map[string]interface{}{"name": "app", "-": "dash"})
+
+	fields, err = ListDefaultFields(&ExampleJSONDash{})
+	c.Assert(err, IsNil)
+	c.Assert(fields, HasLen, 5)
+
+	doc, err := filler.GenerateDoc(&ExampleJSONDash{})
+	c.Assert(err, IsNil)
+	c.Assert(doc, Equals, "| Field | Type | Default |\n| --- | --- | --- |\n| name | string | `app` |\n| - | string | `dash` |\n")
+	schema, err := filler.GenerateJSONSchema(&ExampleJSONDash{})
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(schema), "retries"), Equals, false)
+	c.Assert(strings.Contains(string(schema), `"name"`), Equals, true)
+	c.Assert(filler.EnvKeys(&ExampleJSONDash{}), HasLen, 0)
+	c.Assert(EnvKeys(&ExampleJSONDash{}), DeepEquals, []string{"GODEFAULT_TEST_RETRIES"})
+}
+
+type ExampleKinds struct {
+	Name    string        `default:"app"`
+	Port    int           `default:"8080"`
+	Ratio   *float64      `default:"0.5"`
+	Tags    []string      `default:"a,b"`
+	Timeout time.Duration `default:"5s"`
+	Nested  ExampleKindsNested
+}
+
+type ExampleKindsNested struct {
+	Size int `default:"128"`
+}
+
+func (s *OptionsSuite) TestWithKinds(c *C) {
+	report := &FillReport{}
+	filler := NewFiller(WithKinds(reflect.Int, reflect.Float64), WithReport(report))
+	foo := &ExampleKinds{}
+	c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Name, Equals, "")
+	c.Assert(foo.Port, Equals, 8080)
+	c.Assert(*foo.Ratio, Equals, 0.5)
+	c.Assert(foo.Tags, IsNil)
+	c.Assert(foo.Timeout, Equals, time.Duration(0))
+	// Without reflect.Struct the nested struct is not descended into.
+	c.Assert(foo.Nested.Size, Equals, 0)
+
+	c.Assert(report.Fields, HasLen, 5)
+	for _, field := range report.Fields {
+		skipped := field.Path == "Name" || field.Path == "Tags" || field.Path == "Timeout"
+		c.Assert(field.Source == SourceSkippedKind, Equals, skipped, Commentf("%s", field.Path))
+	}
+
+	foo = &ExampleKinds{}
+	c.Assert(NewFiller(WithKinds(reflect.Struct, reflect.Int)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Port, Equals, 8080)
+	c.Assert(foo.Nested.Size, Equals, 128)
+	c.Assert(foo.Name, Equals, "")
+
+	report.Fields = nil
+	bar := &ExampleKinds{}
+	c.Assert(filler.Apply(bar, TagLayer()), IsNil)
+	c.Assert(bar.Port, Equals, 8080)
+	c.Assert(bar.Name, Equals, "")
+	c.Assert(report.Fields[0].Source, Equals, SourceSkippedKind)
+
+	plan, err := Compile(reflect.TypeOf(ExampleKinds{}), WithKinds(reflect.String))
+	c.Assert(err, IsNil)
+	bar = &ExampleKinds{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Name, Equals, "app")
+	c.Assert(bar.Port, Equals, 0)
+
+	fields, err := ListDefaultFields(&ExampleKinds{}, WithKinds(reflect.Int))
+	c.Assert(err, IsNil)
+	c.Assert(fields, HasLen, 1)
+	c.Assert(fields[0].Path, Equals, "Port")
+
+	// Timeout is an int64.
+	baz := &ExampleKinds{}
+	c.Assert(NewFiller(WithKinds(reflect.Int64)).FillContext(context.Background(), baz), IsNil)
+	c.Assert(baz.Timeout, Equals, 5*time.Second)
+	c.Assert(baz.Port, Equals, 0)
+}
+
+type ExampleMaxSliceFillItem struct {
+	Name string `default:"item"`
+}
+
+type ExampleMaxSliceFill struct {
+	Items []ExampleMaxSliceFillItem
+	Made  []ExampleMaxSliceFillItem `default:"make:3"`
+	Ports []int                     `default:"[1,2,3]"`
+}
+
+func (s *OptionsSuite) TestWithMaxSliceFill(c *C) {
+	names := func(items []ExampleMaxSliceFillItem) []string {
+		var names []string
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	foo := &ExampleMaxSliceFill{Items: make([]ExampleMaxSliceFillItem, 3)}
+	c.Assert(NewFiller(WithMaxSliceFill(2)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(names(foo.Items), DeepEquals, []string{"item", "item", ""})
+	c.Assert(names(foo.Made), DeepEquals, []string{"item", "item", ""})
+	c.Assert(foo.Ports, DeepEquals, []int{1, 2, 3})
+
+	plan, err := NewFiller(WithMaxSliceFill(1)).Compile(reflect.TypeOf(ExampleMaxSliceFill{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleMaxSliceFill{Items: make([]ExampleMaxSliceFillItem, 3)}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(names(bar.Items), DeepEquals, []string{"item", "", ""})
+	c.Assert(names(bar.Made), DeepEquals, []string{"item", "", ""})
+
+	qux := &ExampleMaxSliceFill{Items: make([]ExampleMaxSliceFillItem, 3)}
+	c.Assert(NewFiller(WithMaxSliceFill(1)).Apply(qux, TagLayer()), IsNil)
+	c.Assert(names(qux.Items), DeepEquals, []string{"item", "", ""})
+	c.Assert(names(qux.Made), DeepEquals, []string{"item", "", ""})
+	c.Assert(qux.Ports, DeepEquals, []int{1, 2, 3})
+
+	baz := &ExampleMaxSliceFill{Items: make([]ExampleMaxSliceFillItem, 3)}
+	c.Assert(NewFiller(WithMaxSliceFill(0)).FillContext(context.Background(), baz), IsNil)
+	c.Assert(names(baz.Items), DeepEquals, []string{"item", "item", "item"})
+}
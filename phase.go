@@ -0,0 +1,153 @@
+package godefault
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Phase is the step of a fill in which a default tag is resolved. Within a
+// struct, the fields of a phase are filled in declaration order, after every
+// field of the previous phases, derived fields after the siblings they refer
+// to, so derived values can rely on the values they derive from whatever
+// order the fields are declared in:
+//
+//	URL  string `default:"ref:Base"` // PhaseDerived, after Base
+//	Base string `default:"env:BASE_URL:http://localhost"`
+//
+// The order is part of the API: it doesn't change between fills, nor with
+// the options of the Filler, so neither do the fill reports and errors.
+type Phase int
+
+const (
+	// PhaseValues are the tags that depend on nothing else in the struct:
+	// literals, env: references, placeholders, durations and the like. The
+	// nested structs and the elements of slices and maps are filled in this
+	// phase too, each with its own phases.
+	PhaseValues Phase = iota
+	// PhaseDerived are the tags computed from a sibling field once it is
+	// filled: len: and ref: references, expr: expressions, and choose:
+	// defaults, which may be seeded by a sibling. A derived field is filled
+	// after the derived siblings it refers to.
+	PhaseDerived
+	// PhaseLookups are the lookup: and ratio: references, resolved once the
+	// whole fill is done, in the order they were met.
+	PhaseLookups
+)
+
+// TagPhase returns the phase in which the default tag value is resolved.
+func TagPhase(value string) Phase {
+	switch {
+	case isDeferredTag(value):
+		return PhaseLookups
+	case isDerivedTag(value):
+		return PhaseDerived
+	}
+
+	return PhaseValues
+}
+
+// isDeferredTag reports whether value is resolved once the whole fill is
+// done, see PhaseLookups.
+func isDeferredTag(value string) bool {
+	return isLookupRef(value) || isRatioRef(value)
+}
+
+// isDerivedTag reports whether value is resolved from a sibling field, see
+// PhaseDerived.
+func isDerivedTag(value string) bool {
+	return isLenRef(value) || isSiblingRef(value) || isChooseRef(value) || isExprRef(value)
+}
+
+// derivedDeps returns the names of the siblings the derived tag value of sf
+// refers to.
+func derivedDeps(sf reflect.StructField, value string) []string {
+	switch {
+	case isSiblingRef(value):
+		name, _, _ := parseSiblingRef(value)
+		return []string{name}
+	case isLenRef(value):
+		name, _ := splitIntTransform(strings.TrimPrefix(value, lenRefPrefix))
+		return []string{name}
+	case isExprRef(value):
+		_, names, _ := parseExprRef(value)
+		return names
+	case isChooseRef(value):
+		if name, ok := sf.Tag.Lookup(seedFromTag); ok && name != hostnameSeed {
+			return []string{name}
+		}
+	}
+
+	return nil
+}
+
+// derivedLast returns fields in the order of their phase, the fields of
+// PhaseDerived moved after the other ones, each after the derived siblings
+// it refers to. The lookups are deferred by SetDefaultValue.
+func derivedLast(fields []*FieldData) []*FieldData {
+	derived := make(map[string]*FieldData)
+	for _, field := range fields {
+		if isDerivedTag(field.TagValue) {
+			derived[field.Field.Name] = field
+		}
+	}
+	if len(derived) == 0 {
+		return fields
+	}
+
+	ordered := make([]*FieldData, 0, len(fields))
+	for _, field := range fields {
+		if !isDerivedTag(field.TagValue) {
+			ordered = append(ordered, field)
+		}
+	}
+	// Depth first, in declaration order; the fields of a cycle are failed by
+	// derivedCycle.
+	seen := make(map[string]bool, len(derived))
+	var visit func(field *FieldData)
+	visit = func(field *FieldData) {
+		if seen[field.Field.Name] {
+			return
+		}
+		seen[field.Field.Name] = true
+		for _, name := range derivedDeps(field.Field, field.TagValue) {
+			if dep, ok := derived[name]; ok {
+				visit(dep)
+			}
+		}
+		ordered = append(ordered, field)
+	}
+	for _, field := range fields {
+		if isDerivedTag(field.TagValue) {
+			visit(field)
+		}
+	}
+
+	return ordered
+}
+
+// derivedCycle reports the first of names, the siblings field refers to,
+// whose derived defaults lead back to field.
+func derivedCycle(field *FieldData, names []string) (string, bool) {
+	t, tag := field.siblings.Type(), field.owner().Tag
+	for _, name := range names {
+		seen := make(map[string]bool)
+		pending := []string{name}
+		for len(pending) != 0 {
+			next := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if next == field.Field.Name {
+				return name, true
+			}
+			sf, ok := t.FieldByName(next)
+			if seen[next] || !ok {
+				continue
+			}
+			seen[next] = true
+			if value := sf.Tag.Get(tag); isDerivedTag(value) {
+				pending = append(pending, derivedDeps(sf, value)...)
+			}
+		}
+	}
+
+	return "", false
+}
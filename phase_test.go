@@ -0,0 +1,185 @@
+package godefault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type PhaseSuite struct{}
+
+var _ = Suite(&PhaseSuite{})
+
+type ExamplePhases struct {
+	URL      string        `default:"ref:Base"`
+	Count    int           `default:"len:Hosts"`
+	Timeout  time.Duration `default:"ref:Default"`
+	Limits   ExampleLimits `default:"ref:Defaults"`
+	Primary  string        `default:"lookup:Hosts[0]"`
+	Base     string        `default:"env:GODEFAULT_TEST_BASE:http://localhost"`
+	Hosts    []string      `default:"[a,b]"`
+	Default  time.Duration `default:"5s"`
+	Defaults ExampleLimits
+	Preset   string `default:"ref:Base"`
+	Pointer  *int   `default:"ref:Count"`
+	Chained  string `default:"ref:URL"`
+}
+
+func (s *PhaseSuite) TestSiblingRef(c *C) {
+	os.Setenv("GODEFAULT_TEST_BASE", "http://example.com")
+	defer os.Unsetenv("GODEFAULT_TEST_BASE")
+
+	foo := &ExamplePhases{Preset: "kept"}
+	SetDefaults(foo)
+
+	c.Assert(foo.URL, Equals, "http://example.com")
+	c.Assert(foo.Count, Equals, 2)
+	c.Assert(foo.Timeout, Equals, 5*time.Second)
+	c.Assert(foo.Limits.Conns, Equals, 100)
+	c.Assert(foo.Primary, Equals, "a")
+	c.Assert(foo.Preset, Equals, "kept")
+	c.Assert(*foo.Pointer, Equals, 2)
+	c.Assert(foo.Chained, Equals, "http://example.com")
+
+	// Copies don't share memory.
+	*foo.Pointer = 3
+	c.Assert(foo.Count, Equals, 2)
+}
+
+func (s *PhaseSuite) TestSiblingRefPlanAndLayers(c *C) {
+	expected := &ExamplePhases{}
+	SetDefaults(expected)
+
+	plan, err := Compile(reflect.TypeOf(ExamplePhases{}))
+	c.Assert(err, IsNil)
+	foo := &ExamplePhases{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo, DeepEquals, expected)
+
+	bar := &ExamplePhases{}
+	c.Assert(Apply(bar, MapLayer(map[string]string{"base": "http://layer"}), TagLayer()), IsNil)
+	c.Assert(bar.URL, Equals, "http://layer")
+	c.Assert(bar.Limits.Conns, Equals, 100)
+}
+
+func (s *PhaseSuite) TestSiblingRefErrors(c *C) {
+	foo := &struct {
+		Missing  string `default:"ref:Unknown"`
+		Invalid  string `default:"ref:a.b"`
+		Mismatch int    `default:"ref:Name"`
+		Name     string `default:"foo"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Missing: ref:Unknown: no such sibling field\n`+
+		`Invalid: invalid sibling reference "ref:a.b", expected ref:Field\[\+suffix\]\n`+
+		`Mismatch: ref:Name: string can't be assigned to int`)
+
+	c.Assert(CheckDefaults(foo), HasLen, 1)
+	c.Assert(CheckDefaults(&ExamplePhases{}), HasLen, 0)
+}
+
+func (s *PhaseSuite) TestTagPhase(c *C) {
+	c.Assert(TagPhase("8080"), Equals, PhaseValues)
+	c.Assert(TagPhase("env:PORT:8080"), Equals, PhaseValues)
+	c.Assert(TagPhase("len:Items"), Equals, PhaseDerived)
+	c.Assert(TagPhase("ref:Base"), Equals, PhaseDerived)
+	c.Assert(TagPhase("lookup:Hosts[0]"), Equals, PhaseLookups)
+
+	fields, err := ListDefaultFields(&ExamplePhases{})
+	c.Assert(err, IsNil)
+	c.Assert(fields[0].Phase, Equals, PhaseDerived)
+	c.Assert(fields[4].Phase, Equals, PhaseLookups)
+	c.Assert(fields[5].Phase, Equals, PhaseValues)
+}
+
+type ExampleOrder struct {
+	Idle     int               `default:"expr:Max/2"`
+	Chained  string            `default:"ref:Alias"`
+	Alias    string            `default:"ref:Name"`
+	Name     string            `default:"env:GODEFAULT_TEST_UNSET:app"`
+	Max      int               `default:"len:Hosts|*10"`
+	Hosts    []string          `default:"[a,b,c]"`
+	Labels   map[string]string `default:"{z:1,a:2,m:3}"`
+	Limits   ExampleLimits
+	Primary  string          `default:"lookup:Hosts[0]"`
+	Engine   string          `default:"choose:v1=50,v2=50" seedfrom:"Name"`
+	Backends []ExampleLimits `default:"make:3"`
+	Invalid  map[int]int     `default:"{1:1,x:2,y:3}"`
+}
+
+func (s *PhaseSuite) TestDeterministicOrder(c *C) {
+	fill := func() string {
+		report := &FillReport{}
+		foo := &ExampleOrder{}
+		err := NewFiller(WithReport(report)).FillContext(context.Background(), foo)
+		return fmt.Sprintf("%v\n%+v\n%+v", err, report.Fields, foo)
+	}
+
+	first := fill()
+	c.Assert(first, Matches, `(?s)Invalid: invalid map key: .*"x".*`)
+	for i := 0; i < 100; i++ {
+		c.Assert(fill(), Equals, first)
+	}
+
+	var paths []string
+	report := &FillReport{}
+	NewFiller(WithReport(report)).FillContext(context.Background(), &ExampleOrder{})
+	for _, field := range report.Fields {
+		paths = append(paths, field.Path)
+	}
+	c.Assert(paths, DeepEquals, []string{
+		"Name", "Hosts", "Labels", "Limits.Conns", "Primary",
+		"Backends[0].Conns", "Backends[1].Conns", "Backends[2].Conns", "Invalid",
+		"Max", "Idle", "Alias", "Chained", "Engine",
+	})
+}
+
+func (s *PhaseSuite) TestDeterministicOverrides(c *C) {
+	for i := 0; i < 100; i++ {
+		foo := &ExamplePhases{}
+		c.Assert(Apply(foo, MapLayer(map[string]string{"Base": "upper", "base": "lower", "BASE": "caps"}), TagLayer()), IsNil)
+		c.Assert(foo.URL, Equals, "lower")
+
+		bar := &struct {
+			Limits ExampleLimits `default:"json:{\"conns\":1,\"Conns\":2,\"x\":0,\"a\":0}"`
+		}{}
+		err := SetDefaultsContext(context.Background(), bar)
+		c.Assert(err, ErrorMatches, `Limits: invalid json: value: no field "a" in godefault.ExampleLimits`)
+	}
+}
+
+type ExampleSiblingSuffix struct {
+	APIURL  string  `default:"ref:BaseURL+/api/v1"`
+	Health  *string `default:"ref:APIURL+/health"`
+	BaseURL string  `default:"env:GODEFAULT_TEST_BASE:http://localhost"`
+	Plus    string  `default:"ref:BaseURL+/a+b"`
+	Empty   string  `default:"ref:Unset+/x"`
+	Unset   string
+}
+
+func (s *PhaseSuite) TestSiblingRefSuffix(c *C) {
+	os.Setenv("GODEFAULT_TEST_BASE", "https://example.com")
+	defer os.Unsetenv("GODEFAULT_TEST_BASE")
+
+	foo := &ExampleSiblingSuffix{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+	c.Assert(foo.APIURL, Equals, "https://example.com/api/v1")
+	c.Assert(*foo.Health, Equals, "https://example.com/api/v1/health")
+	c.Assert(foo.Plus, Equals, "https://example.com/a+b")
+	c.Assert(foo.Empty, Equals, "/x")
+	c.Assert(CheckDefaults(foo), HasLen, 0)
+
+	bar := &struct {
+		Port   int    `default:"8080"`
+		Addr   string `default:"ref:Port+/x"`
+		Number int    `default:"ref:Addr+1"`
+	}{}
+	err := SetDefaultsContext(context.Background(), bar)
+	c.Assert(err, ErrorMatches, `Addr: ref:Port\+/x: Port of type int is not a string\n`+
+		`Number: ref:Addr\+1: string can't be assigned to int`)
+	c.Assert(CheckDefaults(bar), HasLen, 1)
+}
@@ -0,0 +1,252 @@
+package godefault
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Plan is a fill precompiled for one struct type. Compiling resolves once
+// which filler handles every field and parses the tags that don't depend on
+// the environment, so applying the plan is a loop over the fields that only
+// assigns the precomputed values. Fields the plan can't precompute, e.g.
+// those handled by registered fillers, env: references or pointers, are
+// filled exactly like Fill does.
+//
+// A Plan is safe for concurrent use.
+type Plan struct {
+	filler  *Filler
+	typ     reflect.Type
+	steps   []planStep
+	dynamic bool
+}
+
+type planStepKind int
+
+const (
+	stepValue planStepKind = iota
+	stepStruct
+	stepStructSlice
+	stepDynamic
+)
+
+type planStep struct {
+	kind  planStepKind
+	index int
+	field reflect.StructField
+	tag   string
+
+	// value and err are the precomputed result of the filler, for stepValue.
+	value reflect.Value
+	err   error
+	// plan fills the nested struct, or every element of the struct slice.
+	plan *Plan
+}
+
+// Compile returns the Plan filling values of the struct type t, using a
+// Filler configured by opts.
+//
+// Usage
+//
+//	var configPlan, _ = Compile(reflect.TypeOf(Config{}))
+//
+//	func handle() {
+//	    var config Config
+//	    configPlan.Apply(&config)
+//	}
+func Compile(t reflect.Type, opts ...Option) (*Plan, error) {
+	filler := getDefaultFiller()
+	if len(opts) != 0 {
+		filler = NewFiller(opts...)
+	}
+
+	return filler.Compile(t)
+}
+
+// Compile returns the Plan filling values of the struct type t with f, which
+// caches it for the next calls and FillElement. The fillers of f must not be
+// changed afterwards.
+func (f *Filler) Compile(t reflect.Type) (*Plan, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, invalidTargetf("godefault: expected a struct type, got %v", t)
+	}
+
+	return f.plan(t), nil
+}
+
+func (f *Filler) compile(t reflect.Type, prefix string) *Plan {
+	p := &Plan{filler: f, typ: t}
+	// Protobuf messages are filled depending on their values, validated or
+	// reported or logged fills visit every field, preprocessed tags are only
+	// known at fill time, as are those read with the conventions of another
+	// library, and unexported, filtered, replayed or opaque fields need the
+	// dynamic path.
+	if f.protoCompat || f.postValidate != nil || f.report != nil || f.logger != nil ||
+		f.preprocess != nil || f.replay != nil || f.opaque != nil || f.compat != "" || f.unexported || f.filter != nil || isProtoMessage(reflect.New(t).Elem()) {
+		p.dynamic = true
+		return p
+	}
+	// Meta fields record the visits of SetDefaultValues, which also calls
+	// the Default methods of the types of the untagged fields.
+	if hasMetaField(t) || hasMethodDefaultField(t) {
+		p.dynamic = true
+		return p
+	}
+	// Derived tags need their siblings that SetDefaultValues fills first.
+	for i := 0; i < t.NumField(); i++ {
+		if isDerivedTag(t.Field(i).Tag.Get(f.Tag)) {
+			p.dynamic = true
+			return p
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(f.Tag)
+		// Zero sentinels leave the empty fields a plan fills as they are,
+		// but for the []byte ones, which they make non-nil.
+		zero := isZeroSentinel(sf.Type, tag) && !(sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Uint8)
+		if sf.PkgPath != "" || tag == "-" || f.skipSource(sf) != "" || !f.marks(sf) || zero {
+			continue
+		}
+
+		state := &fillState{}
+		field := &FieldData{
+			Value:    reflect.New(sf.Type).Elem(),
+			Field:    sf,
+			TagValue: tag,
+			filler:   f,
+			state:    state,
+		}
+		fn := f.getFunction(field)
+		if fn == nil {
+			continue
+		}
+
+		step := planStep{kind: stepDynamic, index: i, field: sf, tag: tag}
+		builtin := f.builtins[funcPointer(fn)]
+		switch {
+		case f.strict && isRequired(sf):
+			// Checked by SetDefaultValues.
+		case builtin && isPrecomputable(sf.Type) && isStaticTag(tag):
+			fn(field)
+			// The slice filler leaves values it can't split untouched.
+			if sf.Type.Kind() == reflect.Slice && field.Value.IsNil() {
+				continue
+			}
+			step.kind = stepValue
+			step.value = field.Value
+			if len(state.errs) != 0 {
+				fe := *state.errs[0].(*FieldError)
+				fe.Path = prefix + fe.Path
+				step.err = &fe
+			}
+		case builtin && isStructType(sf.Type) && sf.Type.Kind() == reflect.Struct && !isNullType(sf.Type) && tag == structModeRecurse:
+			step.kind = stepStruct
+			step.plan = f.compile(sf.Type, prefix+sf.Name+".")
+		case builtin && sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Struct && tag == "":
+			step.kind = stepStructSlice
+			step.plan = f.compile(sf.Type.Elem(), prefix+sf.Name+".")
+		}
+		p.steps = append(p.steps, step)
+	}
+
+	return p
+}
+
+// isPrecomputable reports whether the built-in filler of t produces a value
+// that only depends on the tag. Slices are copied by cloneValue on every fill.
+func isPrecomputable(t reflect.Type) bool {
+	if t == durationType || t == timeType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		// []byte defaults are only set on nil slices.
+		elem := t.Elem()
+		return elem.Kind() != reflect.Uint8 && elem.Kind() != reflect.Struct && isPrecomputable(elem)
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// Apply fills variable, which must be a pointer to a value of the compiled
+// type. Like FillContext, it returns an error per tag that failed to parse.
+func (p *Plan) Apply(variable interface{}) error {
+	value := reflect.ValueOf(variable)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Type().Elem() != p.typ {
+		return invalidTargetf("godefault: expected a non-nil *%s, got %T", p.typ, variable)
+	}
+
+	state := &fillState{recover: true, root: value.Elem()}
+	p.apply(value.Elem(), nil, state)
+	p.filler.resolveLookups(state)
+
+	return joinErrors(state.errs)
+}
+
+func (p *Plan) apply(value reflect.Value, parent *FieldData, state *fillState) {
+	if p.dynamic {
+		p.filler.SetDefaultValues(p.filler.getFieldsFromValue(value, parent, state))
+		return
+	}
+
+	for i := range p.steps {
+		step := &p.steps[i]
+		fieldValue := value.Field(step.index)
+		switch step.kind {
+		case stepValue:
+			if isEmptyValue(fieldValue) {
+				fieldValue.Set(cloneValue(step.value))
+				if step.err != nil {
+					state.errs = append(state.errs, step.err)
+				}
+			}
+		case stepStruct:
+			step.plan.apply(fieldValue, p.parent(step, fieldValue, parent, state), state)
+		case stepStructSlice:
+			field := p.parent(step, fieldValue, parent, state)
+			for j := 0; j < p.filler.sliceFillCount(fieldValue.Len()); j++ {
+				elem := fieldValue.Index(j)
+				step.plan.apply(elem, field.element(elem, strconv.Itoa(j)), state)
+			}
+		case stepDynamic:
+			p.filler.SetDefaultValues([]*FieldData{p.parent(step, fieldValue, parent, state)})
+		}
+	}
+}
+
+func (p *Plan) parent(step *planStep, value reflect.Value, parent *FieldData, state *fillState) *FieldData {
+	return &FieldData{
+		Value:    value,
+		Field:    step.field,
+		TagValue: step.tag,
+		Parent:   parent,
+		filler:   p.filler,
+		state:    state,
+	}
+}
+
+// cloneValue returns a copy of a precomputed value, with slices copied
+// deeply so that fills don't share them.
+func cloneValue(value reflect.Value) reflect.Value {
+	if value.Kind() != reflect.Slice {
+		return value
+	}
+
+	clone := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+	for i := 0; i < value.Len(); i++ {
+		clone.Index(i).Set(cloneValue(value.Index(i)))
+	}
+
+	return clone
+}
+
+func funcPointer(fn FillerFunc) uintptr {
+	return reflect.ValueOf(fn).Pointer()
+}
@@ -0,0 +1,108 @@
+package godefault
+
+import (
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type PlanSuite struct{}
+
+var _ = Suite(&PlanSuite{})
+
+type ExamplePlan struct {
+	Basic    ExampleBasic
+	Pointers ExamplePointers
+	Env      int    `default:"env:GODEFAULT_TEST_PORT:8080|+1"`
+	Invalid  int    `default:"foo"`
+	Skipped  string `default:"-"`
+	Named    string `default:"bar"`
+}
+
+func (s *PlanSuite) TestApplyMatchesSetDefaults(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExamplePlan{}))
+	c.Assert(err, IsNil)
+
+	expected := &ExamplePlan{}
+	SetDefaults(expected)
+
+	for i := 0; i < 2; i++ {
+		foo := &ExamplePlan{}
+		c.Assert(plan.Apply(foo), ErrorMatches, `Invalid: strconv.ParseInt: parsing "foo": invalid syntax`)
+
+		// The date placeholders are resolved on every fill.
+		foo.Basic.DateTime = expected.Basic.DateTime
+		c.Assert(foo, DeepEquals, expected)
+	}
+}
+
+func (s *PlanSuite) TestApplyWithValues(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExampleBasic{}))
+	c.Assert(err, IsNil)
+
+	foo := &ExampleBasic{
+		Integer:  55,
+		String:   "bar",
+		Children: []Child{{Name: "alice"}, {Name: "bob", Age: 2}},
+	}
+	c.Assert(plan.Apply(foo), IsNil)
+
+	c.Assert(foo.Integer, Equals, 55)
+	c.Assert(foo.Integer8, Equals, int8(8))
+	c.Assert(foo.String, Equals, "bar")
+	c.Assert(foo.Struct.Integer, Equals, 33)
+	c.Assert(foo.Children[0].Age, Equals, 10)
+	c.Assert(foo.Children[1].Age, Equals, 2)
+
+	// Fills don't share memory.
+	bar := &ExampleBasic{}
+	c.Assert(plan.Apply(bar), IsNil)
+	bar.IntSlice[0] = 9
+	c.Assert(foo.IntSlice, DeepEquals, []int{1, 2, 3, 4})
+}
+
+func (s *PlanSuite) TestApplyFillerFuncs(c *C) {
+	calls := 0
+	filler := NewFiller()
+	filler.FuncByName = map[string]FillerFunc{
+		"Named": func(field *FieldData) {
+			calls++
+			field.Value.SetString("baz")
+		},
+	}
+	plan, err := filler.Compile(reflect.TypeOf(ExamplePlan{}))
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 2; i++ {
+		foo := &ExamplePlan{}
+		plan.Apply(foo)
+		c.Assert(foo.Named, Equals, "baz")
+	}
+	c.Assert(calls, Equals, 2)
+}
+
+func (s *PlanSuite) TestApplyInvalid(c *C) {
+	_, err := Compile(reflect.TypeOf(0))
+	c.Assert(err, ErrorMatches, "godefault: expected a struct type, got int")
+
+	plan, err := Compile(reflect.TypeOf(ExampleBasic{}))
+	c.Assert(err, IsNil)
+	c.Assert(plan.Apply(ExampleBasic{}), ErrorMatches, `godefault: expected a non-nil \*godefault.ExampleBasic, got godefault.ExampleBasic`)
+	c.Assert(plan.Apply(&ExamplePlan{}), ErrorMatches, `godefault: expected a non-nil \*godefault.ExampleBasic, got \*godefault.ExamplePlan`)
+}
+
+func (s *PlanSuite) BenchmarkSetDefaults(c *C) {
+	for i := 0; i < c.N; i++ {
+		foo := &ExampleBasic{}
+		SetDefaults(foo)
+	}
+}
+
+func (s *PlanSuite) BenchmarkApply(c *C) {
+	plan, _ := Compile(reflect.TypeOf(ExampleBasic{}))
+	c.ResetTimer()
+	for i := 0; i < c.N; i++ {
+		foo := &ExampleBasic{}
+		plan.Apply(foo)
+	}
+}
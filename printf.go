@@ -0,0 +1,83 @@
+package godefault
+
+import (
+	"fmt"
+	"strings"
+)
+
+// printfPrefix introduces a default formatted from environment variables,
+// the format followed by the variables giving its operands, separated by
+// bars:
+//
+//	DSN  string   `default:"printf:postgres://%s/%s|DB_HOST|DB_NAME"`
+//	Addr HostPort `default:"printf:%s:%s|HOST|PORT"`
+//
+// The operands are strings, so the verbs are %s, %q or %v; the format can't
+// hold a bar. An unset variable is an empty operand, unless the Filler is
+// built WithEnvRequired, which fails the field instead.
+const printfPrefix = "printf:"
+
+func isPrintfRef(value string) bool {
+	return strings.HasPrefix(value, printfPrefix)
+}
+
+// parsePrintfRef splits a printf: default into its format and the variables
+// of its operands.
+func parsePrintfRef(value string) (format string, keys []string, err error) {
+	parts := strings.Split(value[len(printfPrefix):], "|")
+	format, keys = parts[0], parts[1:]
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("invalid printf value %q, expected %sFORMAT|KEY|...", value, printfPrefix)
+	}
+	for _, key := range keys {
+		if key == "" {
+			return "", nil, fmt.Errorf("invalid printf value %q: empty variable name", value)
+		}
+	}
+
+	return format, keys, nil
+}
+
+// checkPrintfRef validates a printf: default, whose format must have a verb
+// per variable.
+func checkPrintfRef(value string) error {
+	format, keys, err := parsePrintfRef(value)
+	if err != nil {
+		return err
+	}
+	operands := make([]interface{}, len(keys))
+	for i := range operands {
+		operands[i] = ""
+	}
+	if formatted := fmt.Sprintf(format, operands...); strings.Contains(formatted, "%!") {
+		return fmt.Errorf("invalid printf value %q: %d variables don't match the format, %s", value, len(keys), formatted)
+	}
+
+	return nil
+}
+
+// resolvePrintfRef formats the printf: default of field, reporting the
+// variables that were set.
+func resolvePrintfRef(field *FieldData) (string, []string) {
+	format, keys, err := parsePrintfRef(field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return "", nil
+	}
+
+	var found []string
+	operands := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, ok := field.lookupEnv(key)
+		switch {
+		case ok:
+			found = append(found, key)
+		case field.owner().envRequired:
+			field.fail(envUnsetError(key))
+			return "", nil
+		}
+		operands[i] = value
+	}
+
+	return fmt.Sprintf(format, operands...), found
+}
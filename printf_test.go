@@ -0,0 +1,67 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type PrintfSuite struct{}
+
+var _ = Suite(&PrintfSuite{})
+
+type ExamplePrintf struct {
+	DSN     string   `default:"printf:postgres://%s/%s|GODEFAULT_TEST_DB_HOST|GODEFAULT_TEST_DB_NAME"`
+	Addr    HostPort `default:"printf:%s:%s|GODEFAULT_TEST_DB_HOST|GODEFAULT_TEST_DB_PORT"`
+	Quoted  string   `default:"printf:name=%q|GODEFAULT_TEST_DB_NAME"`
+	Missing string   `default:"printf:[%s]|GODEFAULT_TEST_UNSET"`
+}
+
+func (s *PrintfSuite) TestPrintf(c *C) {
+	os.Setenv("GODEFAULT_TEST_DB_HOST", "db.internal")
+	os.Setenv("GODEFAULT_TEST_DB_NAME", "app")
+	os.Setenv("GODEFAULT_TEST_DB_PORT", "5432")
+	defer os.Unsetenv("GODEFAULT_TEST_DB_HOST")
+	defer os.Unsetenv("GODEFAULT_TEST_DB_NAME")
+	defer os.Unsetenv("GODEFAULT_TEST_DB_PORT")
+
+	foo := &ExamplePrintf{}
+	report := &FillReport{}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.DSN, Equals, "postgres://db.internal/app")
+	c.Assert(foo.Addr, Equals, HostPort{"db.internal", 5432})
+	c.Assert(foo.Quoted, Equals, `name="app"`)
+	c.Assert(foo.Missing, Equals, "[]")
+	c.Assert(report.Fields[0].Source, Equals, SourceEnv)
+
+	foo = &ExamplePrintf{}
+	err := NewFiller(WithEnvRequired(true)).FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Missing: required field is not set: \$GODEFAULT_TEST_UNSET is unset`)
+	c.Assert(errors.Is(err, ErrRequired), Equals, true)
+	c.Assert(foo.Missing, Equals, "")
+
+	c.Assert(EnvKeys(&ExamplePrintf{}), DeepEquals, []string{"GODEFAULT_TEST_DB_HOST", "GODEFAULT_TEST_DB_NAME", "GODEFAULT_TEST_DB_PORT", "GODEFAULT_TEST_UNSET"})
+	c.Assert(TagFeatures(reflect.TypeOf(""), "printf:%s|HOST"), DeepEquals, []Feature{FeatureEnv})
+}
+
+func (s *PrintfSuite) TestPrintfErrors(c *C) {
+	foo := &struct {
+		NoKeys  string `default:"printf:%s"`
+		Empty   string `default:"printf:%s||B"`
+		TooMany string `default:"printf:%s|A|B"`
+		TooFew  string `default:"printf:%s:%s|A"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `NoKeys: invalid printf value "printf:%s", expected printf:FORMAT\|KEY\|...\n`+
+		`Empty: invalid printf value "printf:%s\|\|B": empty variable name`)
+	c.Assert(foo.NoKeys, Equals, "")
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 4)
+	c.Assert(errs[2], ErrorMatches, `TooMany: invalid printf value "printf:%s\|A\|B": 2 variables don't match the format, %!\(EXTRA string=\)`)
+	c.Assert(errs[3], ErrorMatches, `TooFew: invalid printf value .*: 1 variables don't match the format, :%!s\(MISSING\)`)
+}
@@ -0,0 +1,38 @@
+package godefault
+
+import "reflect"
+
+// Structs generated by protoc-gen-go carry internal state (unexported fields
+// and, with older generators, XXX_ fields), pointer scalars for proto2 and
+// "optional" fields, and oneof members wrapped in interface fields. In the
+// protobuf compatibility mode the filler:
+//
+//   - skips XXX_ fields, unexported fields are never touched anyway;
+//   - fills pointer scalars like any other pointer, allocating them only when
+//     there is a default;
+//   - descends into a oneof wrapper only when a member is already set, since
+//     choosing the member is up to the caller.
+//
+// The mode is enabled for any struct whose pointer has a ProtoReflect method,
+// or for every struct with WithProtoCompat.
+
+// isProtoMessage reports whether v is a generated protobuf message.
+func isProtoMessage(v reflect.Value) bool {
+	_, ok := reflect.PtrTo(v.Type()).MethodByName("ProtoReflect")
+	return ok
+}
+
+// getOneofFields returns the fields of the member held by the oneof interface
+// value, or nothing when no member is set.
+func (f *Filler) getOneofFields(value reflect.Value, parent *FieldData, state *fillState) []*FieldData {
+	if value.Kind() != reflect.Interface || value.IsNil() {
+		return nil
+	}
+
+	member := value.Elem()
+	if member.Kind() != reflect.Ptr || member.IsNil() || member.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	return f.getFieldsFromValue(member.Elem(), parent, state)
+}
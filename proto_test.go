@@ -0,0 +1,57 @@
+package godefault
+
+import (
+	"github.com/sonnt85/godefault/testdata/protodemo"
+	. "gopkg.in/check.v1"
+)
+
+type ProtoSuite struct{}
+
+var _ = Suite(&ProtoSuite{})
+
+func (s *ProtoSuite) TestSetDefaultsProtoMessage(c *C) {
+	msg := &protodemo.Config{}
+	SetDefaults(msg)
+
+	c.Assert(*msg.Name, Equals, "demo")
+	c.Assert(*msg.Port, Equals, int32(8080))
+	c.Assert(msg.Enabled, IsNil)
+	c.Assert(msg.Retries, Equals, uint32(3))
+	c.Assert(msg.Tls, IsNil)
+	c.Assert(msg.Source, IsNil)
+	c.Assert(msg.XXX_unrecognized, IsNil)
+	c.Assert(msg.XXX_sizecache, Equals, int32(0))
+}
+
+func (s *ProtoSuite) TestSetDefaultsProtoMessageSetValues(c *C) {
+	name := "custom"
+	enabled := false
+	msg := &protodemo.Config{
+		Name:    &name,
+		Enabled: &enabled,
+		Tls:     &protodemo.Tls{},
+		Source:  &protodemo.Config_Url{},
+	}
+	SetDefaults(msg)
+
+	c.Assert(*msg.Name, Equals, "custom")
+	c.Assert(*msg.Enabled, Equals, false)
+	c.Assert(msg.Tls.MinVersion, Equals, "1.2")
+	c.Assert(msg.Source.(*protodemo.Config_Url).Url, Equals, "https://example.com")
+}
+
+type ExampleLegacyProto struct {
+	Name             string `default:"foo"`
+	XXX_unrecognized []byte `default:"raw"`
+}
+
+func (s *ProtoSuite) TestWithProtoCompat(c *C) {
+	foo := &ExampleLegacyProto{}
+	NewFiller(WithProtoCompat()).Fill(foo)
+	c.Assert(foo.Name, Equals, "foo")
+	c.Assert(foo.XXX_unrecognized, IsNil)
+
+	bar := &ExampleLegacyProto{}
+	NewFiller().Fill(bar)
+	c.Assert(string(bar.XXX_unrecognized), Equals, "raw")
+}
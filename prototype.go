@@ -0,0 +1,126 @@
+package godefault
+
+import (
+	"reflect"
+)
+
+// FillFromPrototype uses proto as the defaults of dst: every zero field of
+// dst is set to a deep copy of the corresponding field of proto, recursing
+// into nested structs and set pointers to structs. dst must be a non-nil
+// pointer to a struct, proto a value of the same struct type or a pointer to
+// it. Fields of dst that are already set, including slices and maps with
+// elements, are kept.
+//
+// Usage
+//
+//	var defaultConfig = Config{Hosts: []string{"a", "b"}, Server: Server{Port: 8080}}
+//
+//	var config Config
+//	FillFromPrototype(&config, defaultConfig)
+func FillFromPrototype(dst, proto interface{}) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return invalidTargetf("godefault: expected a non-nil pointer to a struct, got %T", dst)
+	}
+
+	protoValue := reflect.ValueOf(proto)
+	if protoValue.Kind() == reflect.Ptr && !protoValue.IsNil() {
+		protoValue = protoValue.Elem()
+	}
+	if !protoValue.IsValid() || protoValue.Type() != dstValue.Elem().Type() {
+		return invalidTargetf("godefault: expected a prototype of type %s, got %T", dstValue.Elem().Type(), proto)
+	}
+
+	c := &cloner{copies: make(map[uintptr]reflect.Value)}
+	mergePrototype(dstValue.Elem(), protoValue, c)
+
+	return nil
+}
+
+func mergePrototype(dst, proto reflect.Value, c *cloner) {
+	for i := 0; i < dst.NumField(); i++ {
+		field, protoField := dst.Field(i), proto.Field(i)
+		if !field.CanSet() || isZeroValue(protoField) {
+			continue
+		}
+
+		t := field.Type()
+		switch {
+		case isStructType(t):
+			mergePrototype(field, protoField, c)
+		case t.Kind() == reflect.Ptr && isStructType(t.Elem()) && !field.IsNil():
+			mergePrototype(field.Elem(), protoField.Elem(), c)
+		case isZeroValue(field):
+			field.Set(c.clone(protoField))
+		}
+	}
+}
+
+// isZeroValue reports whether value is unset, empty slices and maps included.
+func isZeroValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+
+	return value.IsZero()
+}
+
+// cloner deeply copies values, keeping shared and cyclic pointers shared in
+// the copy.
+type cloner struct {
+	copies map[uintptr]reflect.Value
+}
+
+func (c *cloner) clone(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		if clone, ok := c.copies[value.Pointer()]; ok {
+			return clone
+		}
+		clone := reflect.New(value.Type().Elem())
+		c.copies[value.Pointer()] = clone
+		clone.Elem().Set(c.clone(value.Elem()))
+		return clone
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		clone := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			clone.Index(i).Set(c.clone(value.Index(i)))
+		}
+		return clone
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		clone := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			clone.SetMapIndex(iter.Key(), c.clone(iter.Value()))
+		}
+		return clone
+	case reflect.Array, reflect.Struct:
+		clone := reflect.New(value.Type()).Elem()
+		clone.Set(value)
+		if value.Kind() == reflect.Array {
+			for i := 0; i < value.Len(); i++ {
+				clone.Index(i).Set(c.clone(value.Index(i)))
+			}
+			return clone
+		}
+		// Unexported fields, e.g. of time.Time, are copied as they are.
+		for i := 0; i < value.NumField(); i++ {
+			if clone.Field(i).CanSet() {
+				clone.Field(i).Set(c.clone(value.Field(i)))
+			}
+		}
+		return clone
+	}
+
+	return value
+}
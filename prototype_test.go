@@ -0,0 +1,92 @@
+package godefault
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type PrototypeSuite struct{}
+
+var _ = Suite(&PrototypeSuite{})
+
+type ExamplePrototype struct {
+	Name     string
+	Port     int
+	Enabled  bool
+	Timeout  time.Duration
+	Start    time.Time
+	Hosts    []string
+	Labels   map[string]string
+	Children []Child
+	Server   struct {
+		Addr string
+		Port int
+	}
+	Child *Child
+	Next  *ExamplePrototype
+	Array [2]int
+	Any   interface{}
+}
+
+func (s *PrototypeSuite) TestFillFromPrototype(c *C) {
+	start := time.Date(2023, 1, 5, 15, 4, 5, 0, time.UTC)
+	proto := &ExamplePrototype{
+		Name:     "app",
+		Port:     8080,
+		Enabled:  true,
+		Timeout:  time.Second,
+		Start:    start,
+		Hosts:    []string{"a", "b"},
+		Labels:   map[string]string{"env": "dev"},
+		Children: []Child{{Name: "alice", Age: 3}},
+		Child:    &Child{Name: "bob", Age: 4},
+		Array:    [2]int{1, 2},
+		Any:      "foo",
+	}
+	proto.Server.Addr = "0.0.0.0"
+	proto.Server.Port = 80
+	proto.Next = proto
+
+	foo := &ExamplePrototype{Port: 9090, Hosts: []string{}}
+	foo.Server.Port = 81
+	c.Assert(FillFromPrototype(foo, proto), IsNil)
+
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.Port, Equals, 9090)
+	c.Assert(foo.Enabled, Equals, true)
+	c.Assert(foo.Timeout, Equals, time.Second)
+	c.Assert(foo.Start.Equal(start), Equals, true)
+	c.Assert(foo.Hosts, DeepEquals, []string{"a", "b"})
+	c.Assert(foo.Labels, DeepEquals, map[string]string{"env": "dev"})
+	c.Assert(foo.Children, DeepEquals, []Child{{Name: "alice", Age: 3}})
+	c.Assert(foo.Server.Addr, Equals, "0.0.0.0")
+	c.Assert(foo.Server.Port, Equals, 81)
+	c.Assert(*foo.Child, Equals, Child{Name: "bob", Age: 4})
+	c.Assert(foo.Array, Equals, [2]int{1, 2})
+	c.Assert(foo.Any, Equals, "foo")
+
+	// The copy is deep, cycles included.
+	c.Assert(foo.Child == proto.Child, Equals, false)
+	c.Assert(foo.Next == proto, Equals, false)
+	c.Assert(foo.Next.Next, Equals, foo.Next)
+	foo.Hosts[0] = "c"
+	foo.Labels["env"] = "prod"
+	c.Assert(proto.Hosts, DeepEquals, []string{"a", "b"})
+	c.Assert(proto.Labels, DeepEquals, map[string]string{"env": "dev"})
+}
+
+func (s *PrototypeSuite) TestFillFromPrototypeNested(c *C) {
+	proto := ExamplePrototype{Child: &Child{Name: "bob", Age: 4}}
+	foo := &ExamplePrototype{Child: &Child{Name: "alice"}}
+	c.Assert(FillFromPrototype(foo, proto), IsNil)
+
+	c.Assert(*foo.Child, Equals, Child{Name: "alice", Age: 4})
+}
+
+func (s *PrototypeSuite) TestFillFromPrototypeInvalid(c *C) {
+	c.Assert(FillFromPrototype(ExamplePrototype{}, ExamplePrototype{}), ErrorMatches, "godefault: expected a non-nil pointer to a struct, got godefault.ExamplePrototype")
+	c.Assert(FillFromPrototype(&ExamplePrototype{}, Child{}), ErrorMatches, "godefault: expected a prototype of type godefault.ExamplePrototype, got godefault.Child")
+	c.Assert(FillFromPrototype(&ExamplePrototype{}, nil), ErrorMatches, "godefault: expected a prototype of type godefault.ExamplePrototype, got <nil>")
+	c.Assert(FillFromPrototype(&ExamplePrototype{}, (*ExamplePrototype)(nil)), NotNil)
+}
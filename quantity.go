@@ -0,0 +1,139 @@
+package godefault
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// quantityPrefix marks numeric defaults written in the Kubernetes resource
+// quantity grammar: a decimal number followed by an optional suffix, either
+// binary (Ki, Mi, Gi, Ti, Pi, Ei), decimal SI (n, u, m, k, M, G, T, P, E) or a
+// decimal exponent (e3, E-3).
+//
+//	MemoryBytes int64   `default:"quantity:512Mi"` // 536870912
+//	CPUCores    float64 `default:"quantity:250m"`  // 0.25
+//
+// Integer fields only accept quantities that are whole numbers.
+const quantityPrefix = "quantity:"
+
+var quantitySuffixes = map[string]*big.Rat{
+	"Ki": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 10)),
+	"Mi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 20)),
+	"Gi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 30)),
+	"Ti": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 40)),
+	"Pi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 50)),
+	"Ei": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 60)),
+	"n":  pow10(-9),
+	"u":  pow10(-6),
+	"m":  pow10(-3),
+	"":   pow10(0),
+	"k":  pow10(3),
+	"M":  pow10(6),
+	"G":  pow10(9),
+	"T":  pow10(12),
+	"P":  pow10(15),
+	"E":  pow10(18),
+}
+
+func pow10(exp int) *big.Rat {
+	n := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), n)
+	}
+
+	return new(big.Rat).SetInt(n)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+func isQuantity(value string) bool {
+	return strings.HasPrefix(value, quantityPrefix)
+}
+
+// parseQuantity parses a quantity without its prefix into an exact number.
+func parseQuantity(value string) (*big.Rat, error) {
+	i := 0
+	if i < len(value) && (value[i] == '+' || value[i] == '-') {
+		i++
+	}
+	digits := 0
+	for ; i < len(value) && (value[i] >= '0' && value[i] <= '9' || value[i] == '.'); i++ {
+		if value[i] != '.' {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return nil, fmt.Errorf("invalid quantity %q", value)
+	}
+
+	number, ok := new(big.Rat).SetString(value[:i])
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", value)
+	}
+
+	suffix := value[i:]
+	if multiplier, ok := quantitySuffixes[suffix]; ok {
+		return number.Mul(number, multiplier), nil
+	}
+	// "E" alone is the exa suffix, followed by digits it is an exponent.
+	if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		exp, err := strconv.Atoi(suffix[1:])
+		if err == nil && abs(exp) <= 64 {
+			return number.Mul(number, pow10(exp)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid quantity %q: unknown suffix %q", value, suffix)
+}
+
+func parseQuantityInt(value string, t reflect.Type) (int64, error) {
+	q, err := parseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	if !q.IsInt() {
+		return 0, fmt.Errorf("quantity %s is not a whole number", value)
+	}
+	if n := q.Num(); !n.IsInt64() || reflect.Zero(t).OverflowInt(n.Int64()) {
+		return 0, overflowf("quantity %s overflows %s", value, t)
+	}
+
+	return q.Num().Int64(), nil
+}
+
+func parseQuantityUint(value string, t reflect.Type) (uint64, error) {
+	q, err := parseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	if !q.IsInt() {
+		return 0, fmt.Errorf("quantity %s is not a whole number", value)
+	}
+	if n := q.Num(); n.Sign() < 0 || !n.IsUint64() || reflect.Zero(t).OverflowUint(n.Uint64()) {
+		return 0, overflowf("quantity %s overflows %s", value, t)
+	}
+
+	return q.Num().Uint64(), nil
+}
+
+func parseQuantityFloat(value string, t reflect.Type) (float64, error) {
+	q, err := parseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := q.Float64()
+	if reflect.Zero(t).OverflowFloat(f) {
+		return 0, overflowf("quantity %s overflows %s", value, t)
+	}
+
+	return f, nil
+}
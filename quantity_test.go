@@ -0,0 +1,107 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type QuantitySuite struct{}
+
+var _ = Suite(&QuantitySuite{})
+
+type ExampleQuantity struct {
+	MemoryBytes int64   `default:"quantity:512Mi"`
+	DiskBytes   uint64  `default:"quantity:10G"`
+	CPUCores    float64 `default:"quantity:250m"`
+	Exponent    int     `default:"quantity:1e3"`
+	Fraction    int32   `default:"quantity:1.5Ki"`
+	Plain       int     `default:"quantity:42"`
+	Elements    []int64 `default:"[quantity:1k,quantity:2Ki]"`
+}
+
+func (s *QuantitySuite) TestSetDefaultsQuantity(c *C) {
+	foo := &ExampleQuantity{}
+	SetDefaults(foo)
+
+	c.Assert(foo.MemoryBytes, Equals, int64(536870912))
+	c.Assert(foo.DiskBytes, Equals, uint64(10000000000))
+	c.Assert(foo.CPUCores, Equals, 0.25)
+	c.Assert(foo.Exponent, Equals, 1000)
+	c.Assert(foo.Fraction, Equals, int32(1536))
+	c.Assert(foo.Plain, Equals, 42)
+	c.Assert(foo.Elements, DeepEquals, []int64{1000, 2048})
+}
+
+func (s *QuantitySuite) TestParseQuantity(c *C) {
+	int64Type := reflect.TypeOf(int64(0))
+	float64Type := reflect.TypeOf(float64(0))
+
+	for value, expected := range map[string]int64{
+		"1":      1,
+		"+2k":    2000,
+		"-3M":    -3000000,
+		"1Gi":    1 << 30,
+		"2Ti":    2 << 40,
+		"1Ei":    1 << 60,
+		"5E":     5000000000000000000,
+		"2E3":    2000,
+		"2000m":  2,
+		"0.5Ki":  512,
+		"100e-2": 1,
+	} {
+		n, err := parseQuantityInt(value, int64Type)
+		c.Assert(err, IsNil, Commentf("%s", value))
+		c.Assert(n, Equals, expected, Commentf("%s", value))
+	}
+
+	for value, expected := range map[string]float64{
+		"250m":  0.25,
+		"1.5":   1.5,
+		"100u":  0.0001,
+		"3n":    3e-9,
+		"1.5Ki": 1536,
+	} {
+		f, err := parseQuantityFloat(value, float64Type)
+		c.Assert(err, IsNil, Commentf("%s", value))
+		c.Assert(f, Equals, expected, Commentf("%s", value))
+	}
+}
+
+func (s *QuantitySuite) TestParseQuantityInvalid(c *C) {
+	int8Type := reflect.TypeOf(int8(0))
+	uintType := reflect.TypeOf(uint(0))
+	float32Type := reflect.TypeOf(float32(0))
+
+	for _, value := range []string{"", "Mi", "-", ".", "1.2.3", "1Xi", "1mi", "1e", "1eX", "1e1000"} {
+		_, err := parseQuantityInt(value, int8Type)
+		c.Assert(err, ErrorMatches, "invalid quantity .*", Commentf("%s", value))
+	}
+
+	_, err := parseQuantityInt("1500m", int8Type)
+	c.Assert(err, ErrorMatches, "quantity 1500m is not a whole number")
+	_, err = parseQuantityInt("1Ki", int8Type)
+	c.Assert(err, ErrorMatches, "quantity 1Ki overflows int8")
+	_, err = parseQuantityInt("16Ei", reflect.TypeOf(int64(0)))
+	c.Assert(err, ErrorMatches, "quantity 16Ei overflows int64")
+	_, err = parseQuantityUint("-1k", uintType)
+	c.Assert(err, ErrorMatches, "quantity -1k overflows uint")
+	_, err = parseQuantityFloat("1e64", float32Type)
+	c.Assert(err, ErrorMatches, "quantity 1e64 overflows float32")
+}
+
+func (s *QuantitySuite) TestFillContextQuantityErrors(c *C) {
+	foo := &struct {
+		Small   int8  `default:"quantity:1Ki"`
+		Unknown int64 `default:"quantity:1Qi"`
+		Valid   int64 `default:"quantity:1Ki"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Small: quantity 1Ki overflows int8\nUnknown: invalid quantity "1Qi": unknown suffix "Qi"`)
+	c.Assert(foo.Small, Equals, int8(0))
+	c.Assert(foo.Unknown, Equals, int64(0))
+	c.Assert(foo.Valid, Equals, int64(1024))
+
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+}
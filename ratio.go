@@ -0,0 +1,138 @@
+package godefault
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ratioPrefix introduces the default of a duration computed from another
+// duration of the struct being filled, multiplied or divided by a factor,
+// plus or minus a constant:
+//
+//	Timeout     time.Duration `default:"30s"`
+//	DialTimeout time.Duration `default:"ratio:Timeout*0.1+50ms"`
+//
+// The path is dotted from the root of the fill like the one of a lookup:
+// reference, and resolved along with them once the rest of the fill is done,
+// the ratio: fields it leads to first. The result is truncated to the
+// nanosecond, then moved into the range of the min and max tags.
+const ratioPrefix = "ratio:"
+
+var ratioPattern = regexp.MustCompile(`^([^*/]+)([*/])(\d+(?:\.\d*)?|\.\d+)(?:([+-])(.+))?$`)
+
+type ratioRef struct {
+	path []lookupSegment
+	// op is '*' or '/'.
+	op       byte
+	factor   float64
+	constant time.Duration
+}
+
+func isRatioRef(value string) bool {
+	return strings.HasPrefix(value, ratioPrefix)
+}
+
+// parseRatioRef splits a ratio: reference.
+func parseRatioRef(value string) (ratioRef, error) {
+	match := ratioPattern.FindStringSubmatch(strings.TrimPrefix(value, ratioPrefix))
+	if match == nil {
+		return ratioRef{}, fmt.Errorf("invalid ratio reference %q, expected %sPath.To.Duration*factor[+duration]", value, ratioPrefix)
+	}
+	path, err := parseLookupRef(lookupPrefix + match[1])
+	if err != nil {
+		return ratioRef{}, fmt.Errorf("invalid ratio reference %q: bad path %s", value, match[1])
+	}
+	factor, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return ratioRef{}, err
+	}
+	if match[2] == "/" && factor == 0 {
+		return ratioRef{}, fmt.Errorf("invalid ratio reference %q: division by zero", value)
+	}
+
+	ref := ratioRef{path: path, op: match[2][0], factor: factor}
+	if match[4] != "" {
+		if ref.constant, err = parseDurationValue(match[5]); err != nil {
+			return ratioRef{}, fmt.Errorf("invalid ratio reference %q: %w", value, err)
+		}
+		if match[4] == "-" {
+			ref.constant = -ref.constant
+		}
+	}
+
+	return ref, nil
+}
+
+// checkRatioRef validates the ratio: default of a field of type t.
+func checkRatioRef(t reflect.Type, value string) error {
+	if derefType(t) != durationType {
+		return fmt.Errorf("%s: %s is not a %s", value, t, durationType)
+	}
+	_, err := parseRatioRef(value)
+
+	return err
+}
+
+// apply returns the duration of the ratio for the referenced duration d.
+func (ref ratioRef) apply(d time.Duration) (time.Duration, error) {
+	result := float64(d)
+	if ref.op == '/' {
+		result /= ref.factor
+	} else {
+		result *= ref.factor
+	}
+	result += float64(ref.constant)
+	if math.IsNaN(result) || result >= math.MaxInt64 || result < math.MinInt64 {
+		return 0, overflowf("duration %s %c %g is out of range", d, ref.op, ref.factor)
+	}
+
+	return time.Duration(result), nil
+}
+
+// resolveRatio sets field to the duration its ratio: reference computes,
+// resolving the pending field it reads first through r.
+func (r *lookupResolver) resolveRatio(field *FieldData) {
+	if derefType(field.Field.Type) != durationType {
+		field.fail(parseErrorOf(fmt.Errorf("%s: %s is not a %s", field.TagValue, field.Field.Type, durationType)))
+		return
+	}
+	ref, err := parseRatioRef(field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return
+	}
+
+	value := r.root
+	for _, segment := range ref.path {
+		if value, err = lookupSegmentValue(value, segment); err != nil {
+			field.fail(fmt.Errorf("%s: %w", field.TagValue, err))
+			return
+		}
+	}
+	if !r.resolveTarget(field, value) {
+		return
+	}
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			field.fail(fmt.Errorf("%s: nil pointer", field.TagValue))
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Type() != durationType {
+		field.fail(fmt.Errorf("%s: %s is not a %s", field.TagValue, value.Type(), durationType))
+		return
+	}
+
+	d, err := ref.apply(time.Duration(value.Int()))
+	if err != nil {
+		field.fail(fmt.Errorf("%s: %w", field.TagValue, err))
+		return
+	}
+	setCopy(field, reflect.ValueOf(clampDuration(field, d)))
+}
@@ -0,0 +1,105 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type RatioSuite struct{}
+
+var _ = Suite(&RatioSuite{})
+
+type ExampleRatio struct {
+	// Declared first, computed from ratio: fields declared after it.
+	Idle    time.Duration `default:"ratio:Client.Dial/2"`
+	Timeout time.Duration `default:"30s"`
+	Client  struct {
+		Dial  time.Duration  `default:"ratio:Timeout*0.1+50ms"`
+		Read  *time.Duration `default:"ratio:Timeout*.5-1s"`
+		Limit time.Duration  `default:"ratio:Timeout*2" max:"45s"`
+	}
+}
+
+func (s *RatioSuite) TestRatio(c *C) {
+	foo := &ExampleRatio{}
+	c.Assert(NewFiller().FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Timeout, Equals, 30*time.Second)
+	c.Assert(foo.Client.Dial, Equals, 3050*time.Millisecond)
+	c.Assert(*foo.Client.Read, Equals, 14*time.Second)
+	c.Assert(foo.Client.Limit, Equals, 45*time.Second)
+	c.Assert(foo.Idle, Equals, 1525*time.Millisecond)
+}
+
+func (s *RatioSuite) TestRatioOverridden(c *C) {
+	foo := &ExampleRatio{Timeout: 10 * time.Second}
+	foo.Client.Dial = time.Second
+	c.Assert(NewFiller().FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Client.Dial, Equals, time.Second)
+	c.Assert(*foo.Client.Read, Equals, 4*time.Second)
+	c.Assert(foo.Idle, Equals, 500*time.Millisecond)
+
+	plan, err := NewFiller().Compile(reflect.TypeOf(ExampleRatio{}))
+	c.Assert(err, IsNil)
+	foo = &ExampleRatio{Timeout: 10 * time.Second}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Client.Dial, Equals, 1050*time.Millisecond)
+}
+
+func (s *RatioSuite) TestRatioTruncated(c *C) {
+	foo := &struct {
+		Base  time.Duration `default:"1ns"`
+		Third time.Duration `default:"ratio:Base*0.9"`
+	}{}
+	c.Assert(NewFiller().FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Third, Equals, time.Duration(0))
+}
+
+func (s *RatioSuite) TestRatioErrors(c *C) {
+	foo := &struct {
+		A       time.Duration `default:"ratio:B*1"`
+		B       time.Duration `default:"ratio:A*1"`
+		Self    time.Duration `default:"ratio:Self*2"`
+		Port    int           `default:"8080"`
+		ToInt   time.Duration `default:"ratio:Port*2"`
+		Missing time.Duration `default:"ratio:Nope*2"`
+		Huge    time.Duration `default:"ratio:Max*2"`
+		Max     time.Duration `default:"2562047h"`
+	}{}
+	err := NewFiller().FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `B: ratio:A\*1: cycle through A\n`+
+		`A: ratio:B\*1: cycle through B\n`+
+		`Self: ratio:Self\*2: cycle through Self\n`+
+		`ToInt: ratio:Port\*2: int is not a time.Duration\n`+
+		`Missing: ratio:Nope\*2: no field Nope in .*\n`+
+		`Huge: ratio:Max\*2: duration 2562047h0m0s \* 2 is out of range`)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+
+	bar := &struct {
+		Port int `default:"ratio:Port*2"`
+	}{}
+	c.Assert(NewFiller().FillContext(context.Background(), bar), ErrorMatches, `Port: ratio:Port\*2: int is not a time.Duration`)
+}
+
+func (s *RatioSuite) TestCheckRatio(c *C) {
+	for value, expected := range map[string]string{
+		"ratio:Timeout*0.1":       "",
+		"ratio:A.B[x]/3+1m":       "",
+		"ratio:Timeout":           `invalid ratio reference "ratio:Timeout", expected ratio:Path.To.Duration\*factor\[\+duration\]`,
+		"ratio:Timeout*x":         `invalid ratio reference .*`,
+		"ratio:Timeout/0":         `invalid ratio reference "ratio:Timeout/0": division by zero`,
+		"ratio:Timeout*2+forever": `invalid ratio reference "ratio:Timeout\*2\+forever": .*`,
+		"ratio:A..B*2":            `invalid ratio reference "ratio:A..B\*2": bad path A..B`,
+	} {
+		err := checkTagValue(durationType, value)
+		if expected == "" {
+			c.Assert(err, IsNil, Commentf(value))
+		} else {
+			c.Assert(err, ErrorMatches, expected, Commentf(value))
+		}
+	}
+	c.Assert(checkTagValue(reflect.TypeOf(0), "ratio:Timeout*2"), ErrorMatches, `ratio:Timeout\*2: int is not a time.Duration`)
+}
@@ -0,0 +1,56 @@
+package godefault
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is reported for a field whose filler panicked, see WithRecover.
+type PanicError struct {
+	// Value is the value the filler panicked with.
+	Value interface{}
+	// Stack is the stack of the panic, captured WithPanicStack only.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// WithRecover makes Fill recover from the panics of the fillers, as the
+// error-returning fills always do: the field whose filler panicked is left
+// as it is and the fill goes on with the others. Fill drops the errors.
+func WithRecover() Option {
+	return func(f *Filler) {
+		f.recover = true
+	}
+}
+
+// WithPanicStack captures the stack of the panics recovered, in
+// PanicError.Stack, for debugging. Capturing it is slow.
+func WithPanicStack() Option {
+	return func(f *Filler) {
+		f.panicStack = true
+	}
+}
+
+// recovers reports whether the panics of the fillers of field are turned
+// into errors.
+func (field *FieldData) recovers() bool {
+	return field.state != nil && field.state.recover
+}
+
+// recoverPanic fails field with a PanicError when its filler panicked. It
+// must be deferred by the function calling the filler.
+func (field *FieldData) recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	err := &PanicError{Value: r}
+	if field.owner().panicStack {
+		err.Stack = debug.Stack()
+	}
+	field.fail(err)
+}
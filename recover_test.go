@@ -0,0 +1,92 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type RecoverSuite struct{}
+
+var _ = Suite(&RecoverSuite{})
+
+type ExamplePanic struct {
+	Name   string `default:"app"`
+	Broken string `default:"x"`
+	Port   int    `default:"8080"`
+	Child  struct {
+		Broken string `default:"y"`
+		Age    int    `default:"10"`
+	}
+}
+
+func panickingFiller(opts ...Option) *Filler {
+	f := NewFiller(opts...)
+	f.FuncByName = map[string]FillerFunc{
+		"Broken": func(field *FieldData) {
+			panic("boom")
+		},
+	}
+
+	return f
+}
+
+func (s *RecoverSuite) TestFillContextRecovers(c *C) {
+	foo := &ExamplePanic{}
+	err := panickingFiller().FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, "Broken: panic: boom\nChild.Broken: panic: boom")
+
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.Port, Equals, 8080)
+	c.Assert(foo.Child.Age, Equals, 10)
+
+	var panicErr *PanicError
+	c.Assert(errors.As(err, &panicErr), Equals, true)
+	c.Assert(panicErr.Value, Equals, "boom")
+	c.Assert(panicErr.Stack, IsNil)
+}
+
+func (s *RecoverSuite) TestFillPanics(c *C) {
+	c.Assert(func() { panickingFiller().Fill(&ExamplePanic{}) }, PanicMatches, "boom")
+
+	foo := &ExamplePanic{}
+	panickingFiller(WithRecover()).Fill(foo)
+	c.Assert(foo.Port, Equals, 8080)
+	c.Assert(foo.Child.Age, Equals, 10)
+}
+
+func (s *RecoverSuite) TestPanicStack(c *C) {
+	err := panickingFiller(WithPanicStack()).FillContext(context.Background(), &ExamplePanic{})
+
+	var panicErr *PanicError
+	c.Assert(errors.As(err, &panicErr), Equals, true)
+	c.Assert(string(panicErr.Stack), Matches, "(?s).*panickingFiller.*")
+}
+
+func (s *RecoverSuite) TestOtherEntryPointsRecover(c *C) {
+	f := panickingFiller()
+
+	plan, err := f.Compile(reflect.TypeOf(ExamplePanic{}))
+	c.Assert(err, IsNil)
+	foo := &ExamplePanic{}
+	c.Assert(plan.Apply(foo), ErrorMatches, "Broken: panic: boom\nChild.Broken: panic: boom")
+	c.Assert(foo.Port, Equals, 8080)
+
+	bar := &ExamplePanic{}
+	c.Assert(f.Apply(bar, panickingLayer{}, TagLayer()), ErrorMatches, "(?s)Name: panic: layer\n.*")
+	c.Assert(bar.Port, Equals, 8080)
+
+	c.Assert(f.ApplyArgs(&ExamplePanic{}, []string{"--broken=z"}), ErrorMatches, "(?s)Broken: panic: boom.*")
+}
+
+type panickingLayer struct{}
+
+func (panickingLayer) Fill(path string, field *FieldData) bool {
+	if path == "Name" {
+		panic("layer")
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// siblingRefPrefix introduces a default copied from a sibling field, the
+// field of the same struct it names, once that one is filled, see
+// PhaseDerived:
+//
+//	Primary  string `default:"ref:Fallback"`
+//	Fallback string `default:"env:FALLBACK_HOST:localhost"`
+//
+// The value is deep copied, converted like lookup: references do. A missing
+// sibling fails the field. A string sibling can be followed by a suffix, the
+// field set to the value of the sibling with the suffix appended:
+//
+//	BaseURL string `default:"env:BASE_URL:http://localhost"`
+//	APIURL  string `default:"ref:BaseURL+/api/v1"`
+const siblingRefPrefix = "ref:"
+
+var siblingRefPattern = regexp.MustCompile(`^(\w+)(\+.*)?$`)
+
+func isSiblingRef(value string) bool {
+	return strings.HasPrefix(value, siblingRefPrefix)
+}
+
+// parseSiblingRef returns the name of the sibling of a ref: reference, and
+// its suffix, "+" included, if any.
+func parseSiblingRef(value string) (name, suffix string, err error) {
+	match := siblingRefPattern.FindStringSubmatch(strings.TrimPrefix(value, siblingRefPrefix))
+	if match == nil {
+		return "", "", fmt.Errorf("invalid sibling reference %q, expected %sField[+suffix]", value, siblingRefPrefix)
+	}
+
+	return match[1], match[2], nil
+}
+
+// checkSiblingRef validates the ref: default of a field of type t, whose
+// suffix, if any, needs a string.
+func checkSiblingRef(t reflect.Type, value string) error {
+	_, suffix, err := parseSiblingRef(value)
+	if err == nil && suffix != "" && derefType(t).Kind() != reflect.String {
+		return fmt.Errorf("%s: %s can't have a suffix, it is not a string", value, t)
+	}
+
+	return err
+}
+
+// resolveSiblingRef sets field, when still zero, to a copy of the sibling
+// its ref: reference names, or to its string followed by the suffix.
+func resolveSiblingRef(field *FieldData) {
+	name, suffix, err := parseSiblingRef(field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return
+	}
+	if !isZeroValue(field.Value) {
+		return
+	}
+
+	if !field.siblings.IsValid() {
+		field.fail(unknownPathf("%s: no such sibling field", field.TagValue))
+		return
+	}
+	sf, ok := field.siblings.Type().FieldByName(name)
+	if !ok || sf.PkgPath != "" || len(sf.Index) != 1 {
+		field.fail(unknownPathf("%s: no such sibling field", field.TagValue))
+		return
+	}
+	sibling := field.siblings.Field(sf.Index[0])
+	if suffix == "" {
+		setCopy(field, sibling)
+		return
+	}
+
+	for sibling.Kind() == reflect.Ptr && !sibling.IsNil() {
+		sibling = sibling.Elem()
+	}
+	if sibling.Kind() != reflect.String {
+		field.fail(fmt.Errorf("%s: %s of type %s is not a string", field.TagValue, name, sibling.Type()))
+		return
+	}
+	setCopy(field, reflect.ValueOf(sibling.String()+suffix[1:]))
+}
@@ -0,0 +1,54 @@
+package godefault
+
+import (
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+var regexpType = reflect.TypeOf(regexp.Regexp{})
+
+// fillRegexp compiles the default of a regexp.Regexp field, usually behind a
+// pointer:
+//
+//	Pattern *regexp.Regexp `default:"^[a-z]+$"`
+//
+// A regexp already set is left alone.
+func fillRegexp(field *FieldData) {
+	if field.TagValue == "" || !field.Value.IsZero() {
+		return
+	}
+
+	re, err := field.owner().compileRegexp(field.TagValue)
+	field.check(err)
+	if err == nil {
+		// Regexps are immutable, copies share the compiled program.
+		field.Value.Set(reflect.ValueOf(re).Elem())
+	}
+}
+
+// compileRegexp compiles pattern, once per Filler configured
+// WithRegexpCache.
+func (f *Filler) compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if f.regexps == nil {
+		return regexp.Compile(pattern)
+	}
+
+	if re, ok := f.regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err == nil {
+		f.regexps.Store(pattern, re)
+	}
+
+	return re, err
+}
+
+// WithRegexpCache compiles each regexp default once for the Filler, which
+// saves the work when many values, or many fields, share patterns.
+func WithRegexpCache() Option {
+	return func(f *Filler) {
+		f.regexps = &sync.Map{}
+	}
+}
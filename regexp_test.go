@@ -0,0 +1,58 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	. "gopkg.in/check.v1"
+)
+
+type RegexpSuite struct{}
+
+var _ = Suite(&RegexpSuite{})
+
+type ExampleRegexp struct {
+	Pattern  *regexp.Regexp `default:"^[a-z]+$"`
+	Value    regexp.Regexp  `default:"[0-9]+"`
+	Preset   *regexp.Regexp `default:"x"`
+	Untagged *regexp.Regexp
+}
+
+func (s *RegexpSuite) TestRegexp(c *C) {
+	preset := regexp.MustCompile("y")
+	foo := &ExampleRegexp{Preset: preset}
+	SetDefaults(foo)
+
+	c.Assert(foo.Pattern.String(), Equals, "^[a-z]+$")
+	c.Assert(foo.Pattern.MatchString("abc"), Equals, true)
+	c.Assert(foo.Value.MatchString("a1"), Equals, true)
+	c.Assert(foo.Preset, Equals, preset)
+	c.Assert(foo.Untagged, IsNil)
+}
+
+func (s *RegexpSuite) TestRegexpErrors(c *C) {
+	foo := &struct {
+		Pattern *regexp.Regexp `default:"[a-"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Pattern: error parsing regexp: .*`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(foo.Pattern, IsNil)
+
+	c.Assert(CheckDefaults(foo), HasLen, 1)
+	c.Assert(CheckDefaults(&ExampleRegexp{}), HasLen, 0)
+}
+
+func (s *RegexpSuite) TestRegexpCache(c *C) {
+	filler := NewFiller(WithRegexpCache())
+	foo, bar := &ExampleRegexp{}, &ExampleRegexp{}
+	filler.Fill(foo)
+	filler.Fill(bar)
+
+	c.Assert(foo.Pattern.MatchString("abc"), Equals, true)
+	c.Assert(foo.Pattern, Not(Equals), bar.Pattern)
+	cached, ok := filler.regexps.Load("^[a-z]+$")
+	c.Assert(ok, Equals, true)
+	c.Assert(cached.(*regexp.Regexp).String(), Equals, "^[a-z]+$")
+}
@@ -0,0 +1,199 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// The sources a FieldReport attributes a value to.
+const (
+	// SourcePreset is a value set before the fill, which was kept.
+	SourcePreset = "preset"
+	// SourceTag is a value set from the default tag.
+	SourceTag = "tag"
+	// SourceNone is a field the fill left zero.
+	SourceNone = "none"
+	// SourceEnv is a value read from the environment by an env:,
+	// envindirect: or jwtclaim: reference, or set by EnvLayer.
+	SourceEnv = "env"
+	// SourcePlaceholder is a value computed from {{date:...}} or
+	// {{time:...}} placeholders.
+	SourcePlaceholder = "placeholder"
+	// SourceVar is a value read from a variable registered with RegisterVar
+	// or RegisterBuildVar.
+	SourceVar = "var"
+	// SourceKV is a value read from a KeyValueSource by a kv: reference.
+	SourceKV = "kv"
+	// SourceHost is a value picked after the hostname by a host: rule.
+	SourceHost = "host"
+	// SourceGenerated is a value produced by a gen: generator, or a free
+	// port, see freePortKeyword.
+	SourceGenerated = "generated"
+	// SourceError is a field whose value failed to parse.
+	SourceError = "error"
+	// SourceExplicitZero is a field set to its zero value by a "zero"
+	// default, see zeroSentinel.
+	SourceExplicitZero = "explicit-zero"
+	// SourceRestricted is a field whose default uses a feature the Filler
+	// doesn't allow, see Restrict.
+	SourceRestricted = "restricted"
+	// SourceJSONDash is a field tagged `json:"-"` left alone, see
+	// WithHonorJSONDash.
+	SourceJSONDash = "skipped: json-dash"
+	// SourceSkippedKind is a field of a kind left alone, see WithKinds.
+	SourceSkippedKind = "skipped: kind"
+)
+
+// FillReport collects what the fills of a Filler configured WithReport did,
+// one FieldReport per leaf field visited, in visiting order. Nested structs
+// are not reported themselves, their fields are.
+type FillReport struct {
+	Fields []FieldReport
+}
+
+// FieldReport describes how a fill handled one field.
+type FieldReport struct {
+	// Path is the dotted path of the field.
+	Path string
+	// Source is where the value comes from: one of the Source constants, or
+	// the layer that set it, see Apply.
+	Source string
+	// Value is the final value formatted with fmt, "****" for secret fields.
+	Value string
+	// Tag is the raw default tag, "****" for secret fields.
+	Tag string
+	// EmptyEnv is EmptyEnvSet or EmptyEnvUnset when the fill read an
+	// environment variable set to an empty value for the field, after the
+	// interpretation applied, see WithEmptyEnvIsSet.
+	EmptyEnv string
+	// Choice is the branch a choose: default picked, "****" for secret
+	// fields, see choosePrefix.
+	Choice string
+}
+
+// WithReport makes the fills append a FieldReport per field to report. The
+// report is not safe for use by concurrent fills.
+func WithReport(report *FillReport) Option {
+	return func(f *Filler) {
+		f.report = report
+	}
+}
+
+// FillEvent describes a tagged field visited by a fill, see WithLogger.
+type FillEvent struct {
+	// Path is the dotted path of the field.
+	Path string
+	// Value is the final value, formatted on demand.
+	Value EventValue
+	// Source is where the value comes from, like FieldReport.Source.
+	Source string
+	// Tag is the raw default tag, "****" for secret fields.
+	Tag string
+	// EmptyEnv is like FieldReport.EmptyEnv.
+	EmptyEnv string
+	// Choice is like FieldReport.Choice.
+	Choice string
+}
+
+// EventValue is the value of a FillEvent. It is only formatted when String
+// is called, "****" for secret fields, so that events which are filtered out
+// cost nothing.
+type EventValue struct {
+	value  reflect.Value
+	secret bool
+}
+
+func (v EventValue) String() string {
+	if v.secret {
+		return secretValue
+	}
+	if !v.value.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprint(v.value.Interface())
+}
+
+// WithLogger calls log once per tagged leaf field visited by the fills, once
+// its value is final, e.g. to log the defaults applied at startup. A Fill
+// of a value with nested structs reports their fields, not the structs.
+//
+//	filler := NewFiller(WithLogger(func(e FillEvent) {
+//	    log.Printf("default applied path=%s value=%s source=%s", e.Path, e.Value, e.Source)
+//	}))
+func WithLogger(log func(e FillEvent)) Option {
+	return func(f *Filler) {
+		f.logger = log
+	}
+}
+
+// visited runs the checks due once field got its final value, and records
+// the source of that value.
+func (f *Filler) visited(field *FieldData, source string) {
+	if f.strict && isRequired(field.Field) && !f.handled(field) {
+		field.fail(ErrRequired)
+		source = SourceError
+	}
+	if f.postValidate != nil {
+		if err := f.postValidate(field.Path(), field.Value, field.Field); err != nil {
+			field.fail(err)
+			source = SourceError
+		}
+	}
+	choice := field.choice
+	if choice != "" {
+		choice = redact(field.Field, choice)
+	}
+	if f.logger != nil && !f.isDescended(field.Field.Type) {
+		if tag, ok := field.Field.Tag.Lookup(f.Tag); ok {
+			f.logger(FillEvent{
+				Path:     field.Path(),
+				Value:    EventValue{value: field.Value, secret: isSecret(field.Field)},
+				Source:   source,
+				Tag:      redact(field.Field, tag),
+				EmptyEnv: field.emptyEnv,
+				Choice:   choice,
+			})
+		}
+	}
+	if !f.isDescended(field.Field.Type) {
+		recordMeta(field, source)
+	}
+	if f.report != nil && !f.isDescended(field.Field.Type) {
+		f.report.Fields = append(f.report.Fields, FieldReport{
+			Path:     field.Path(),
+			Source:   source,
+			Value:    EventValue{value: field.Value, secret: isSecret(field.Field)}.String(),
+			Tag:      redact(field.Field, field.Field.Tag.Get(f.Tag)),
+			EmptyEnv: field.emptyEnv,
+			Choice:   choice,
+		})
+	}
+}
+
+// skipped records that the fill left field alone for the reason source,
+// without running the checks of visited. Like there, nested structs are not
+// reported.
+func (f *Filler) skipped(field *FieldData, source string) {
+	if f.report != nil && !f.isDescended(field.Field.Type) {
+		f.report.Fields = append(f.report.Fields, FieldReport{
+			Path:   field.Path(),
+			Source: source,
+			Value:  EventValue{value: field.Value, secret: isSecret(field.Field)}.String(),
+			Tag:    redact(field.Field, field.Field.Tag.Get(f.Tag)),
+		})
+	}
+}
+
+// isDescended reports whether the fills of f descend into the fields of
+// values of type t rather than filling them as a whole.
+func (f *Filler) isDescended(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return f.isStructType(t.Elem())
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Struct && !f.opaque[t.Elem()]
+	}
+
+	return f.isStructType(t)
+}
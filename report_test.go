@@ -0,0 +1,101 @@
+package godefault
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	. "gopkg.in/check.v1"
+)
+
+type ReportSuite struct{}
+
+var _ = Suite(&ReportSuite{})
+
+type ExampleReport struct {
+	Name   string `default:"app"`
+	Port   int    `default:"8080"`
+	Token  string `default:"s3cr3t" secret:"true"`
+	Env    string `default:"env:GODEFAULT_TEST_UNSET"`
+	Plain  int
+	Child  Child
+	Peers  []Child
+	Parent *Child
+}
+
+func (s *ReportSuite) TestWithReport(c *C) {
+	var report FillReport
+	foo := &ExampleReport{Port: 9090, Peers: []Child{{}}}
+	NewFiller(WithReport(&report)).Fill(foo)
+
+	c.Assert(report.Fields, DeepEquals, []FieldReport{
+		{Path: "Name", Source: SourceTag, Value: "app", Tag: "app"},
+		{Path: "Port", Source: SourcePreset, Value: "9090", Tag: "8080"},
+		{Path: "Token", Source: SourceTag, Value: "****", Tag: "****"},
+		{Path: "Env", Source: SourceNone, Value: "", Tag: "env:GODEFAULT_TEST_UNSET"},
+		{Path: "Plain", Source: SourceNone, Value: "0", Tag: ""},
+		{Path: "Child.Name", Source: SourceNone, Value: "", Tag: ""},
+		{Path: "Child.Age", Source: SourceTag, Value: "10", Tag: "10"},
+		{Path: "Peers[0].Name", Source: SourceNone, Value: "", Tag: ""},
+		{Path: "Peers[0].Age", Source: SourceTag, Value: "10", Tag: "10"},
+	})
+}
+
+type ExampleLogger struct {
+	Name        string `default:"app"`
+	Port        int    `default:"8080"`
+	Token       string `default:"s3cr3t" secret:"true"`
+	Host        string `default:"env:GODEFAULT_TEST_LOGGER_HOST:localhost"`
+	Indirect    string `default:"envindirect:GODEFAULT_TEST_LOGGER_SOURCE"`
+	Date        string `default:"backup-{{date:0,0,0}}"`
+	Invalid     int    `default:"x"`
+	Untagged    int
+	Child       Child
+	Placeholder string `default:"{{ .Name }}"`
+}
+
+func (s *ReportSuite) TestWithLogger(c *C) {
+	os.Setenv("GODEFAULT_TEST_LOGGER_HOST", "example.com")
+	os.Setenv("GODEFAULT_TEST_LOGGER_SOURCE", "GODEFAULT_TEST_LOGGER_HOST")
+	defer os.Unsetenv("GODEFAULT_TEST_LOGGER_HOST")
+	defer os.Unsetenv("GODEFAULT_TEST_LOGGER_SOURCE")
+
+	var events []FillEvent
+	filler := NewFiller(WithLogger(func(e FillEvent) {
+		events = append(events, e)
+	}))
+
+	foo := &ExampleLogger{Port: 9090}
+	c.Assert(filler.FillContext(context.Background(), foo), ErrorMatches, `Invalid: .*`)
+
+	var lines []string
+	for _, e := range events {
+		lines = append(lines, fmt.Sprintf("%s %s %s", e.Path, e.Source, e.Tag))
+	}
+	c.Assert(lines, DeepEquals, []string{
+		"Name tag app",
+		"Port preset 8080",
+		"Token tag ****",
+		"Host env env:GODEFAULT_TEST_LOGGER_HOST:localhost",
+		"Indirect env envindirect:GODEFAULT_TEST_LOGGER_SOURCE",
+		"Date placeholder backup-{{date:0,0,0}}",
+		"Invalid error x",
+		"Child.Age tag 10",
+		"Placeholder tag {{ .Name }}",
+	})
+	c.Assert(events[1].Value.String(), Equals, "9090")
+	c.Assert(events[2].Value.String(), Equals, "****")
+	c.Assert(events[3].Value.String(), Equals, "example.com")
+}
+
+func (s *ReportSuite) TestWithLoggerEnvFallback(c *C) {
+	var sources []string
+	NewFiller(WithLogger(func(e FillEvent) {
+		sources = append(sources, e.Source)
+	})).Fill(&struct {
+		Host string `default:"env:GODEFAULT_TEST_UNSET:localhost"`
+		Zero string `default:"env:GODEFAULT_TEST_UNSET"`
+	}{})
+
+	c.Assert(sources, DeepEquals, []string{SourceTag, SourceNone})
+}
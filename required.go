@@ -0,0 +1,25 @@
+package godefault
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// isRequired reports whether the field carries a true required tag.
+func isRequired(sf reflect.StructField) bool {
+	required, _ := strconv.ParseBool(sf.Tag.Get("required"))
+	return required
+}
+
+// handled reports whether field has a value after the fill, or was left at
+// zero on purpose: by an argument, by an empty default tag, or by a default resolving to the
+// zero value such as "0". A default resolving to nothing, e.g. an env:
+// reference to an unset variable without fallback, doesn't count.
+func (f *Filler) handled(field *FieldData) bool {
+	if !isZeroValue(field.Value) || field.state.isAssigned(field) {
+		return true
+	}
+	tag, ok := field.Field.Tag.Lookup(f.Tag)
+
+	return ok && (tag == "" || field.TagValue != "")
+}
@@ -0,0 +1,60 @@
+package godefault
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type RequiredSuite struct{}
+
+var _ = Suite(&RequiredSuite{})
+
+type ExampleEmptyTag struct {
+	Bool     bool           `default:""`
+	Int      int            `default:""`
+	Uint     uint           `default:""`
+	Float    float64        `default:""`
+	String   string         `default:""`
+	Duration time.Duration  `default:""`
+	Time     time.Time      `default:""`
+	Bytes    []byte         `default:""`
+	Ints     []int          `default:""`
+	Pointer  *int           `default:""`
+	Null     sql.NullString `default:""`
+	Child    Child          `default:""`
+}
+
+func (s *RequiredSuite) TestEmptyTagLeavesZero(c *C) {
+	foo := &ExampleEmptyTag{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo, DeepEquals, &ExampleEmptyTag{Child: Child{Age: 10}})
+}
+
+type ExampleRequired struct {
+	Untagged int    `required:"true"`
+	Zero     int    `default:"" required:"true"`
+	Explicit int    `default:"0" required:"true"`
+	Default  string `default:"foo" required:"true"`
+	Env      string `default:"env:GODEFAULT_TEST_UNSET" required:"true"`
+	Preset   int    `required:"true"`
+	Optional int    `required:"false"`
+}
+
+func (s *RequiredSuite) TestWithStrict(c *C) {
+	foo := &ExampleRequired{Preset: 1}
+	err := SetDefaultsContext(context.Background(), foo, WithStrict())
+	c.Assert(err, ErrorMatches, "Untagged: required field is not set\nEnv: required field is not set")
+	c.Assert(foo.Default, Equals, "foo")
+
+	plan, err := Compile(reflect.TypeOf(ExampleRequired{}), WithStrict())
+	c.Assert(err, IsNil)
+	c.Assert(plan.Apply(&ExampleRequired{Untagged: 1, Env: "bar", Preset: 1}), IsNil)
+
+	// Required fields are only checked in strict mode.
+	c.Assert(SetDefaultsContext(context.Background(), &ExampleRequired{}), IsNil)
+}
@@ -0,0 +1,169 @@
+package godefault
+
+import "strings"
+
+// resolveTagValue replaces a reference in the tag value of field, such as
+// env:PORT:8080, printf:%s:%s|HOST|PORT, choose:v1=90,v2=10,
+// var:main.Version, jwtclaim:TOKEN:sub, kv:config/port, len:Items,
+// expr:Max/2, host:..., gen:uuid, numcpu or freeport, by what it resolves to. It runs
+// once per field before the field's filler, so every filler, built-in or
+// registered, receives the resolved value. The preprocessor of the filler, if
+// any, runs first.
+func resolveTagValue(field *FieldData) {
+	if field.resolved {
+		return
+	}
+	field.resolved = true
+
+	if preprocess := field.owner().preprocess; preprocess != nil && !field.notTag {
+		field.TagValue = preprocess(field.Path(), field.TagValue)
+	}
+	if compat := field.owner().compat; compat != "" && !field.notTag {
+		field.TagValue = compatTag(field.Field.Type, field.TagValue, compat)
+	}
+	if !field.notTag {
+		sizeUnit(field)
+	}
+	if restricts(field) {
+		field.TagValue = ""
+		return
+	}
+	if isLenRef(field.TagValue) {
+		field.TagValue = resolveLenRef(field)
+		return
+	}
+	if isExprRef(field.TagValue) {
+		field.TagValue = resolveExprRef(field)
+		return
+	}
+	if isChooseRef(field.TagValue) {
+		value, ok := resolveChooseRef(field)
+		field.TagValue = value
+		if ok {
+			field.choice = value
+		}
+	}
+	if isVarRef(field.TagValue) {
+		value, name, found := resolveVarRef(field)
+		field.TagValue = value
+		if found {
+			field.source, field.sourceName = SourceVar, name
+		}
+	}
+	if strings.Contains(field.TagValue, "{{") && placeholderPattern.MatchString(field.TagValue) {
+		field.source = SourcePlaceholder
+	}
+	if isPrintfRef(field.TagValue) {
+		value, found := resolvePrintfRef(field)
+		field.TagValue = value
+		if len(found) != 0 {
+			field.source, field.sourceName = SourceEnv, strings.Join(found, ",")
+		}
+	}
+	if strings.HasPrefix(field.TagValue, envRefPrefix) || strings.HasPrefix(field.TagValue, envIndirectPrefix) {
+		// The last lookup tells whether the value or the fallback was used.
+		found := false
+		name := ""
+		field.TagValue = resolveEnvRef(field.TagValue, field.Field.Type, func(key string) (string, bool) {
+			value, ok := field.lookupEnv(key)
+			found, name = ok, key
+			return value, ok
+		})
+		if found {
+			sep := defaultSeparator
+			if !field.notTag {
+				sep = separatorOf(field.Field)
+			}
+			field.TagValue = envList(field, field.TagValue, sep)
+			field.source, field.sourceName = SourceEnv, name
+		} else if field.TagValue == "" && field.owner().envRequired {
+			field.fail(envUnsetError(name))
+		}
+	}
+	if isJWTClaimRef(field.TagValue) {
+		key, _, _ := parseJWTClaimRef(field.TagValue)
+		value, found, err := resolveJWTClaimRef(field.TagValue, field.lookupEnv)
+		field.check(err)
+		field.TagValue = value
+		if found {
+			field.source, field.sourceName = SourceEnv, key
+		}
+	}
+	if isKVRef(field.TagValue) {
+		value, name, found, err := resolveKVRef(field, field.TagValue)
+		if err != nil {
+			field.fail(err)
+		}
+		field.TagValue = value
+		if found {
+			field.source, field.sourceName = SourceKV, name
+		}
+	}
+	if isHostRef(field.TagValue) {
+		value, err := resolveHostRef(field.TagValue)
+		field.check(err)
+		field.TagValue, field.source = value, SourceHost
+	}
+	if isGenRef(field.TagValue) {
+		value, err := resolveGenRef(field)
+		if err != nil {
+			field.fail(parseErrorOf(err))
+		}
+		field.TagValue, field.source = value, SourceGenerated
+	}
+	if isIntegerType(field.Field.Type) {
+		value, err := resolveCPUExpr(field.TagValue)
+		field.check(err)
+		field.TagValue = value
+	}
+	if isIntegerType(field.Field.Type) && isFreePort(field.TagValue) {
+		value, err := resolveFreePort(field)
+		if err != nil {
+			field.fail(err)
+		}
+		field.TagValue, field.source = value, SourceGenerated
+	}
+}
+
+// tagSource returns the source of the value field got from its tag, failed
+// telling whether the tag failed to parse.
+func tagSource(field *FieldData, failed bool) string {
+	switch {
+	case field.source == SourceRestricted:
+		return SourceRestricted
+	case failed:
+		return SourceError
+	case field.TagValue == "":
+		return SourceNone
+	case field.source != "":
+		return field.source
+	}
+
+	return SourceTag
+}
+
+// isStaticTag reports whether the built-in fillers turn value into the same
+// result on every fill, i.e. it contains nothing resolved at fill time:
+// references handled by resolveTagValue, envs| mappings, date placeholders
+// and day keywords.
+func isStaticTag(value string) bool {
+	return !strings.HasPrefix(value, envRefPrefix) &&
+		!isCPUExpr(value) &&
+		!isFreePort(value) &&
+		!strings.HasPrefix(value, envIndirectPrefix) &&
+		!isLenRef(value) &&
+		!isExprRef(value) &&
+		!isHostRef(value) &&
+		!isGenRef(value) &&
+		!isJWTClaimRef(value) &&
+		!isPrintfRef(value) &&
+		!isChooseRef(value) &&
+		!isLookupRef(value) &&
+		!isRatioRef(value) &&
+		!isKVRef(value) &&
+		!isVarRef(value) &&
+		!isSiblingRef(value) &&
+		!strings.HasPrefix(value, "envs|") &&
+		!isDayKeyword(value) &&
+		!strings.Contains(value, "{{")
+}
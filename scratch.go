@@ -0,0 +1,100 @@
+package godefault
+
+import "sync"
+
+// Scratch holds the values the fillers of one fill share, such as a client
+// created by the first field needing it, a counter or the name of a profile,
+// see FieldData.Scratch. It is created for each fill, seeded with the values
+// of WithScratchValue, and dropped once the fill is over. Like those of
+// context.Context, keys should be of types of their own package to avoid
+// collisions:
+//
+//	type secretsClientKey struct{}
+//
+//	filler.FuncByName["Password"] = func(field *godefault.FieldData) {
+//	    client := field.Scratch().Memoize(secretsClientKey{}, func() interface{} {
+//	        return secrets.NewClient()
+//	    }).(*secrets.Client)
+//	    ...
+//	}
+//
+// A Scratch is safe for concurrent use, its values being shared by every
+// field of the fill. The values themselves are not locked: a map stored in
+// it is only safe to change from fillers that don't run concurrently, which
+// those of a single fill never do. The built-in fillers keep the labelled
+// gen: values, the free ports and the pointers being filled in it, under
+// keys of their own.
+type Scratch struct {
+	mu     sync.Mutex
+	values map[interface{}]interface{}
+}
+
+// scratchValue is a value seeded by WithScratchValue.
+type scratchValue struct {
+	key, value interface{}
+}
+
+// WithScratchValue seeds the Scratch of every fill with value at key, e.g.
+// the profile the custom fillers pick their defaults for. The fillers may
+// replace it, for the rest of their fill only.
+func WithScratchValue(key, value interface{}) Option {
+	return func(f *Filler) {
+		f.scratch = append(f.scratch, scratchValue{key: key, value: value})
+	}
+}
+
+// newScratch returns the Scratch of a fill by f, seeded with its values.
+func newScratch(f *Filler) *Scratch {
+	s := &Scratch{values: make(map[interface{}]interface{}, len(f.scratch))}
+	for _, seed := range f.scratch {
+		s.values[seed.key] = seed.value
+	}
+
+	return s
+}
+
+// Value returns the value at key, nil if there is none.
+func (s *Scratch) Value(key interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key]
+}
+
+// SetValue sets the value at key for the rest of the fill.
+func (s *Scratch) SetValue(key, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+}
+
+// Memoize returns the value at key, setting it to the result of create first
+// when there is none. create runs once per fill, with s locked, so it must not
+// use s.
+func (s *Scratch) Memoize(key interface{}, create func() interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		value = create()
+		s.values[key] = value
+	}
+
+	return value
+}
+
+// Scratch returns the Scratch of the fill of field, shared by all of its
+// fields, see Scratch.
+func (field *FieldData) Scratch() *Scratch {
+	if field.state == nil {
+		field.state = &fillState{}
+	}
+	state := field.state
+	if state.scratch == nil {
+		state.scratch = newScratch(field.owner())
+	}
+
+	return state.scratch
+}
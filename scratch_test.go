@@ -0,0 +1,74 @@
+package godefault
+
+import (
+	"context"
+	"strconv"
+
+	. "gopkg.in/check.v1"
+)
+
+type ScratchSuite struct{}
+
+var _ = Suite(&ScratchSuite{})
+
+type ExampleScratch struct {
+	First   string `default:"a"`
+	Second  string `default:"b"`
+	Profile string `default:"c"`
+	Nested  struct {
+		Third string `default:"d"`
+	}
+}
+
+type scratchCounterKey struct{}
+
+type scratchProfileKey struct{}
+
+type scratchClientKey struct{}
+
+func (s *ScratchSuite) TestScratch(c *C) {
+	clients := 0
+	count := func(field *FieldData) {
+		n, _ := field.Scratch().Value(scratchCounterKey{}).(int)
+		field.Scratch().SetValue(scratchCounterKey{}, n+1)
+		client := field.Scratch().Memoize(scratchClientKey{}, func() interface{} {
+			clients++
+			return clients
+		})
+		field.Value.SetString(field.TagValue + strconv.Itoa(n) + "/" + strconv.Itoa(client.(int)))
+	}
+	filler := NewFiller(WithScratchValue(scratchProfileKey{}, "prod"))
+	filler.FuncByName = map[string]FillerFunc{
+		"First":  count,
+		"Second": count,
+		"Third":  count,
+		"Profile": func(field *FieldData) {
+			field.Value.SetString(field.Scratch().Value(scratchProfileKey{}).(string))
+			field.Scratch().SetValue(scratchProfileKey{}, "changed")
+		},
+	}
+
+	foo := &ExampleScratch{}
+	c.Assert(filler.FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.First, Equals, "a0/1")
+	c.Assert(foo.Second, Equals, "b1/1")
+	c.Assert(foo.Profile, Equals, "prod")
+	c.Assert(foo.Nested.Third, Equals, "d2/1")
+
+	// Every fill starts over from the seeds.
+	bar := &ExampleScratch{}
+	filler.Fill(bar)
+	c.Assert(bar.First, Equals, "a0/2")
+	c.Assert(bar.Profile, Equals, "prod")
+	c.Assert(bar.Nested.Third, Equals, "d2/2")
+}
+
+func (s *ScratchSuite) TestScratchValue(c *C) {
+	scratch := newScratch(NewFiller())
+	c.Assert(scratch.Value("missing"), IsNil)
+	scratch.SetValue("key", 1)
+	c.Assert(scratch.Value("key"), Equals, 1)
+	c.Assert(scratch.Memoize("key", func() interface{} { return 2 }), Equals, 1)
+	c.Assert(scratch.Memoize("other", func() interface{} { return 3 }), Equals, 3)
+	c.Assert(scratch.Value("other"), Equals, 3)
+}
@@ -0,0 +1,26 @@
+package godefault
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// secretValue replaces the default of a field tagged `secret:"true"` in every
+// diagnostic output (docs, schemas, validation errors). Filling is not
+// affected.
+const secretValue = "****"
+
+// isSecret reports whether the field carries a true secret tag.
+func isSecret(sf reflect.StructField) bool {
+	secret, _ := strconv.ParseBool(sf.Tag.Get("secret"))
+	return secret
+}
+
+// redact returns value, or secretValue when sf is a secret field.
+func redact(sf reflect.StructField, value string) string {
+	if isSecret(sf) {
+		return secretValue
+	}
+
+	return value
+}
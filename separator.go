@@ -0,0 +1,77 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// sepTag names the tag choosing the separator of the elements of a slice or
+// map default, a comma otherwise, for elements full of commas such as URLs:
+//
+//	Mirrors []string          `default:"[https://a.example/x,y;https://b.example]" sep:";"`
+//	Weights map[string]int    `default:"{a:1|b:2}" sep:"|"`
+//	Hosts   []string          `default:"[a.example b.example]" sep:" "`
+//
+// The separator is a single rune, which can't be a double quote nor a bracket
+// or, for maps, a brace or a colon. It applies to the top level elements only,
+// those of nested defaults keep using commas. A whitespace separator
+// separates the elements by runs of any whitespace. See splitTagList for the
+// escapes.
+const sepTag = "sep"
+
+// defaultSeparator separates the elements of slice and map defaults without
+// a sepTag.
+const defaultSeparator = ','
+
+// fieldSeparator returns the separator of the elements of the default of sf,
+// see sepTag.
+func fieldSeparator(sf reflect.StructField) (rune, error) {
+	value, ok := sf.Tag.Lookup(sepTag)
+	if !ok {
+		return defaultSeparator, nil
+	}
+
+	structural := `"[]`
+	t := sf.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Map {
+		structural = `"{}:`
+	}
+	sep, size := utf8.DecodeRuneInString(value)
+	if sep == utf8.RuneError || size != len(value) || strings.ContainsRune(structural, sep) {
+		return 0, fmt.Errorf("invalid separator %q, expected a single character but %s", value, strings.Join(strings.Split(structural, ""), " "))
+	}
+
+	return sep, nil
+}
+
+// separatorOf is fieldSeparator for the tools describing defaults, which
+// use commas when the sepTag is invalid, an error CheckDefaults reports.
+func separatorOf(sf reflect.StructField) rune {
+	sep, err := fieldSeparator(sf)
+	if err != nil {
+		return defaultSeparator
+	}
+
+	return sep
+}
+
+// separator returns the separator of the elements of the default of field,
+// failing the field when its sepTag is invalid. Elements of slices and maps
+// use commas.
+func separator(field *FieldData) (rune, bool) {
+	if field.notTag {
+		return defaultSeparator, true
+	}
+	sep, err := fieldSeparator(field.Field)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return 0, false
+	}
+
+	return sep, true
+}
@@ -0,0 +1,78 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type SeparatorSuite struct{}
+
+var _ = Suite(&SeparatorSuite{})
+
+type ExampleSeparator struct {
+	Mirrors []string          `default:"[https://a.example/x,y; https://b.example]" sep:";"`
+	Hosts   []string          `default:"[a.example  b.example\tc.example]" sep:" "`
+	Weights map[string]int    `default:"{a:1|b:2}" sep:"|"`
+	Pipes   []string          `default:"[a||b|c]" sep:"|"`
+	Nested  map[string][]int  `default:"{a:[1,2];b:[3]}" sep:";"`
+	Quoted  []string          `default:"[\"a;b\";c]" sep:";"`
+	Escaped []string          `default:"[a|;b;c]" sep:";"`
+	Arrows  []string          `default:"[a→b→c]" sep:"→"`
+	Labels  map[string]string `default:"{team:core;env:prod|:eu}" sep:";"`
+}
+
+func (s *SeparatorSuite) TestSeparator(c *C) {
+	foo := &ExampleSeparator{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Mirrors, DeepEquals, []string{"https://a.example/x,y", "https://b.example"})
+	c.Assert(foo.Hosts, DeepEquals, []string{"a.example", "b.example", "c.example"})
+	c.Assert(foo.Weights, DeepEquals, map[string]int{"a": 1, "b": 2})
+	c.Assert(foo.Pipes, DeepEquals, []string{"a|b", "c"})
+	c.Assert(foo.Nested, DeepEquals, map[string][]int{"a": {1, 2}, "b": {3}})
+	c.Assert(foo.Quoted, DeepEquals, []string{"a;b", "c"})
+	c.Assert(foo.Escaped, DeepEquals, []string{"a;b", "c"})
+	c.Assert(foo.Arrows, DeepEquals, []string{"a", "b", "c"})
+	c.Assert(foo.Labels, DeepEquals, map[string]string{"team": "core", "env": "prod:eu"})
+
+	c.Assert(CheckDefaults(foo), HasLen, 0)
+}
+
+func (s *SeparatorSuite) TestSeparatorPlan(c *C) {
+	expected := &ExampleSeparator{}
+	SetDefaults(expected)
+
+	plan, err := Compile(reflect.TypeOf(ExampleSeparator{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleSeparator{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo, DeepEquals, expected)
+}
+
+func (s *SeparatorSuite) TestSeparatorErrors(c *C) {
+	foo := &struct {
+		Long    []string          `default:"[a;;b]" sep:";;"`
+		Empty   []string          `default:"[a]" sep:""`
+		Bracket []string          `default:"[a]b]" sep:"]"`
+		Quote   []string          `default:"[a\"b]" sep:"\""`
+		Colon   map[string]string `default:"{a:b}" sep:":"`
+		Brace   map[string]string `default:"{a:b}" sep:"{"`
+		Slice   []string          `default:"[a:b]" sep:":"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Long: invalid separator ";;", expected a single character but " \[ \]\n`+
+		`Empty: invalid separator "", .*\nBracket: invalid separator "\]", .*\nQuote: invalid separator "\\"", .*\n`+
+		`Colon: invalid separator ":", expected a single character but " { } :\nBrace: invalid separator "{", .*`)
+	c.Assert(foo.Long, IsNil)
+	c.Assert(foo.Slice, DeepEquals, []string{"a", "b"})
+
+	c.Assert(CheckDefaults(foo), HasLen, 6)
+}
+
+func (s *SeparatorSuite) TestSeparatorSchema(c *C) {
+	defaults, err := ExtractDefaults(&ExampleSeparator{})
+	c.Assert(err, IsNil)
+	c.Assert(defaults["Mirrors"], DeepEquals, []interface{}{"https://a.example/x,y", "https://b.example"})
+}
@@ -0,0 +1,74 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// sizeUnitTag names the tag choosing the unit of the kilo to exa suffixes
+// of the quantities of a field, for byte sizes where 1K is ambiguous:
+//
+//	CacheBytes int64    `default:"quantity:64K" sizeunit:"iec"` // 65536
+//	DiskBytes  int64    `default:"quantity:64K" sizeunit:"si"`  // 64000
+//	Buffers    []uint32 `default:"[quantity:4k,quantity:1Mi]" sizeunit:"iec"`
+//
+// It is one of:
+//
+//	si     k, K, M, G, T, P and E are powers of 1000
+//	iec    k, K, M, G, T, P and E are powers of 1024
+//
+// The i suffixes stay binary, and the other ones, e.g. m, decimal whatever
+// the unit. K, which the quantity grammar doesn't know, is only accepted with
+// the tag. Without it the suffixes are read as they always are, SI decimal
+// and binary with an i.
+const sizeUnitTag = "sizeunit"
+
+const (
+	sizeUnitSI  = "si"
+	sizeUnitIEC = "iec"
+)
+
+// sizeSuffixPattern matches the quantities with a kilo to exa suffix, the
+// character after it telling the binary suffixes and the exponents apart.
+var sizeSuffixPattern = regexp.MustCompile(`(quantity:[+-]?[0-9.]+)([kKMGTPE])([i0-9+-]?)`)
+
+// sizeUnitValue rewrites the quantities of value, the default of sf, into the
+// unit of its sizeUnitTag, see sizeUnitTag.
+func sizeUnitValue(sf reflect.StructField, value string) (string, error) {
+	unit, ok := sf.Tag.Lookup(sizeUnitTag)
+	if !ok {
+		return value, nil
+	}
+	if unit != sizeUnitSI && unit != sizeUnitIEC {
+		return "", fmt.Errorf("invalid size unit %q, expected %q or %q", unit, sizeUnitSI, sizeUnitIEC)
+	}
+
+	return sizeSuffixPattern.ReplaceAllStringFunc(value, func(quantity string) string {
+		m := sizeSuffixPattern.FindStringSubmatch(quantity)
+		if m[3] != "" {
+			return quantity
+		}
+		suffix := m[2]
+		if suffix == "K" {
+			suffix = "k"
+		}
+		if unit == sizeUnitIEC {
+			if suffix == "k" {
+				suffix = "K"
+			}
+			suffix += "i"
+		}
+		return m[1] + suffix
+	}), nil
+}
+
+// sizeUnit rewrites the default of field into the unit of its sizeUnitTag,
+// failing the field when the tag is invalid.
+func sizeUnit(field *FieldData) {
+	value, err := sizeUnitValue(field.Field, field.TagValue)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+	}
+	field.TagValue = value
+}
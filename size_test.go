@@ -0,0 +1,63 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type SizeSuite struct{}
+
+var _ = Suite(&SizeSuite{})
+
+type ExampleSize struct {
+	Binary   int64    `default:"quantity:64K" sizeunit:"iec"`
+	Decimal  int64    `default:"quantity:64K" sizeunit:"si"`
+	Native   int64    `default:"quantity:64k"`
+	Explicit uint64   `default:"quantity:1Mi" sizeunit:"si"`
+	Exponent int      `default:"quantity:2E3" sizeunit:"iec"`
+	Exa      uint64   `default:"quantity:1E" sizeunit:"iec"`
+	Milli    float64  `default:"quantity:250m" sizeunit:"iec"`
+	Buffers  []uint32 `default:"[quantity:4k,quantity:1M]" sizeunit:"iec"`
+	Pointer  *int64   `default:"env:SIZE_UNSET:quantity:2G" sizeunit:"iec"`
+}
+
+func (s *SizeSuite) TestSetDefaultsSizeUnit(c *C) {
+	foo := &ExampleSize{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Binary, Equals, int64(64<<10))
+	c.Assert(foo.Decimal, Equals, int64(64000))
+	c.Assert(foo.Native, Equals, int64(64000))
+	c.Assert(foo.Explicit, Equals, uint64(1<<20))
+	c.Assert(foo.Exponent, Equals, 2000)
+	c.Assert(foo.Exa, Equals, uint64(1<<60))
+	c.Assert(foo.Milli, Equals, 0.25)
+	c.Assert(foo.Buffers, DeepEquals, []uint32{4 << 10, 1 << 20})
+	c.Assert(*foo.Pointer, Equals, int64(2<<30))
+	c.Assert(CheckDefaults(foo), HasLen, 0)
+}
+
+func (s *SizeSuite) TestSizeUnitErrors(c *C) {
+	foo := &struct {
+		Unknown  int `default:"quantity:1K" sizeunit:"binary"`
+		Untagged int `default:"quantity:1K"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `(?s)Unknown: invalid size unit "binary", expected "si" or "iec".*Untagged: .*unknown suffix "K".*`)
+	c.Assert(foo.Unknown, Equals, 0)
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+}
+
+func (s *SizeSuite) TestSizeUnitValue(c *C) {
+	sf := reflect.StructField{Tag: `sizeunit:"iec"`}
+	value, err := sizeUnitValue(sf, "[quantity:1k,quantity:2Ki,quantity:3E-1,quantity:4E+2,quantity:5T]")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "[quantity:1Ki,quantity:2Ki,quantity:3E-1,quantity:4E+2,quantity:5Ti]")
+
+	sf.Tag = `sizeunit:"si"`
+	value, err = sizeUnitValue(sf, "quantity:1K")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "quantity:1k")
+}
@@ -0,0 +1,97 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Snapshot renders the defaults of the struct type behind v, one line per
+// tagged field sorted by path, for golden files that make a change of
+// defaults show up in code review, see godefaulttest.SnapshotEqual:
+//
+//	Name = app
+//	Port (tag) = env:PORT:8080
+//	Server.Timeout = 30s
+//
+// The rendering only depends on the tags: the defaults reading anything
+// outside the struct, such as env:, kv:, host: references, processor counts
+// and date placeholders, and the gen: defaults and choose: draws, which
+// differ from one fill to the next, are rendered as their raw tag, marked
+// "(tag)", and the references to other fields are resolved. Durations are rendered like time.Duration.String,
+// times in UTC as RFC 3339, maps sorted by key and secrets as "****". v
+// itself is left alone; the defaults that fail to parse are left out, and
+// returned as the error.
+func Snapshot(v interface{}) (string, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return "", err
+	}
+
+	// Values are formatted once the fill is done, lookups and ratios
+	// included.
+	var events []FillEvent
+	filler := NewFiller(WithLogger(func(e FillEvent) {
+		events = append(events, e)
+	}))
+	filler.Restrict(FeatureReferences)
+	err = filler.FillContext(context.Background(), reflect.New(t).Interface())
+
+	var lines []string
+	for _, e := range events {
+		switch e.Source {
+		case SourceNone, SourceError:
+		case SourceRestricted:
+			lines = append(lines, fmt.Sprintf("%s (tag) = %s", e.Path, e.Tag))
+		default:
+			lines = append(lines, fmt.Sprintf("%s = %s", e.Path, snapshotValue(e.Value)))
+		}
+	}
+	sort.Strings(lines)
+	snapshot := ""
+	for _, line := range lines {
+		snapshot += line + "\n"
+	}
+
+	return snapshot, withoutPolicyErrors(err)
+}
+
+// snapshotValue formats the value of a field for Snapshot.
+func snapshotValue(v EventValue) string {
+	if v.secret {
+		return secretValue
+	}
+	value := v.value
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "<nil>"
+		}
+		value = value.Elem()
+	}
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(value.Interface())
+}
+
+// withoutPolicyErrors returns err without its ErrPolicy errors, those of the
+// defaults Snapshot renders as raw tags.
+func withoutPolicyErrors(err error) error {
+	errs, ok := err.(AggregateError)
+	if !ok {
+		errs = AggregateError{err}
+	}
+
+	var kept []error
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrPolicy) {
+			kept = append(kept, err)
+		}
+	}
+
+	return joinErrors(kept)
+}
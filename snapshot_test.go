@@ -0,0 +1,98 @@
+package godefault
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SnapshotSuite struct{}
+
+var _ = Suite(&SnapshotSuite{})
+
+type ExampleSnapshot struct {
+	Name     string            `default:"app"`
+	Port     int               `default:"env:GODEFAULT_TEST_SNAPSHOT_PORT:8080"`
+	Started  string            `default:"{{date:0,0,0}}"`
+	Workers  int               `default:"numcpu"`
+	Timeout  time.Duration     `default:"90s"`
+	Idle     time.Duration     `default:"ratio:Timeout/2"`
+	Epoch    time.Time         `default:"2020-01-02T03:04:05+07:00"`
+	Labels   map[string]string `default:"{b:2,a:1}"`
+	Password string            `default:"s3cr3t" secret:"true"`
+	Retries  *int              `default:"3"`
+	Untagged int
+	Rules    []Child `default:"make:2"`
+}
+
+const exampleSnapshot = `Epoch = 2020-01-01T20:04:05Z
+Idle = 45s
+Labels = map[a:1 b:2]
+Name = app
+Password = ****
+Port (tag) = env:GODEFAULT_TEST_SNAPSHOT_PORT:8080
+Retries = 3
+Rules[0].Age = 10
+Rules[1].Age = 10
+Started (tag) = {{date:0,0,0}}
+Timeout = 1m30s
+Workers (tag) = numcpu
+`
+
+func (s *SnapshotSuite) TestSnapshot(c *C) {
+	os.Setenv("GODEFAULT_TEST_SNAPSHOT_PORT", "9000")
+	defer os.Unsetenv("GODEFAULT_TEST_SNAPSHOT_PORT")
+
+	foo := &ExampleSnapshot{Name: "preset"}
+	snapshot, err := Snapshot(foo)
+	c.Assert(err, IsNil)
+	c.Assert(snapshot, Equals, exampleSnapshot)
+	c.Assert(foo.Name, Equals, "preset")
+
+	snapshot, err = Snapshot(&struct {
+		Name string `default:"app"`
+		Port int    `default:"many"`
+	}{})
+	c.Assert(err, ErrorMatches, `Port: strconv.ParseInt: parsing "many": invalid syntax`)
+	c.Assert(snapshot, Equals, "Name = app\n")
+
+	snapshot, err = Snapshot(&struct{ Port int }{})
+	c.Assert(err, IsNil)
+	c.Assert(snapshot, Equals, "")
+}
+
+func (s *SnapshotSuite) TestSnapshotGenerated(c *C) {
+	v := &struct {
+		ID   string `default:"gen:uuid"`
+		Name string `default:"app"`
+	}{}
+	snapshot, err := Snapshot(v)
+	c.Assert(err, IsNil)
+	c.Assert(snapshot, Equals, "ID (tag) = gen:uuid\nName = app\n")
+
+	again, err := Snapshot(v)
+	c.Assert(err, IsNil)
+	c.Assert(again, Equals, snapshot)
+}
+
+func (s *SnapshotSuite) TestSnapshotChoose(c *C) {
+	v := &struct {
+		ID     string `default:"server-1"`
+		Engine string `default:"choose:v1=50,v2=50"`
+		Cache  string `default:"choose:lru=50,arc=50" seedfrom:"hostname"`
+		Shard  string `default:"choose:a=50,b=50" seedfrom:"ID"`
+	}{}
+	snapshot, err := Snapshot(v)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(snapshot, "Engine (tag) = choose:v1=50,v2=50\n"), Equals, true, Commentf("%s", snapshot))
+	c.Assert(strings.Contains(snapshot, "Cache (tag) = choose:lru=50,arc=50\n"), Equals, true, Commentf("%s", snapshot))
+	c.Assert(strings.Contains(snapshot, "Shard = "), Equals, true, Commentf("%s", snapshot))
+
+	for i := 0; i < 20; i++ {
+		again, err := Snapshot(v)
+		c.Assert(err, IsNil)
+		c.Assert(again, Equals, snapshot)
+	}
+}
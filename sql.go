@@ -0,0 +1,49 @@
+package godefault
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// nullTypes are the database/sql wrappers filled by fillNull. Each of them is a
+// struct whose first field holds the value, followed by a Valid flag.
+var nullTypes = []reflect.Type{
+	reflect.TypeOf(sql.NullString{}),
+	reflect.TypeOf(sql.NullInt64{}),
+	reflect.TypeOf(sql.NullInt32{}),
+	reflect.TypeOf(sql.NullFloat64{}),
+	reflect.TypeOf(sql.NullBool{}),
+	reflect.TypeOf(sql.NullTime{}),
+}
+
+func isNullType(t reflect.Type) bool {
+	for _, nullType := range nullTypes {
+		if t == nullType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fillNull fills the value of an invalid sql.Null* field from the tag and
+// marks it valid. An empty tag leaves the field NULL.
+func fillNull(field *FieldData) {
+	valid := field.Value.FieldByName("Valid")
+	if field.TagValue == "" || valid.Bool() {
+		return
+	}
+
+	filler := field.owner()
+	value := &FieldData{
+		Value:    field.Value.Field(0),
+		Field:    field.Value.Type().Field(0),
+		TagValue: field.TagValue,
+		Parent:   field,
+		filler:   filler,
+		state:    field.state,
+		resolved: true,
+	}
+	filler.SetDefaultValue(value)
+	valid.SetBool(true)
+}
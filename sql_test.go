@@ -0,0 +1,60 @@
+package godefault
+
+import (
+	"database/sql"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SQLSuite struct{}
+
+var _ = Suite(&SQLSuite{})
+
+type ExampleNull struct {
+	String   sql.NullString  `default:"foo"`
+	Int64    sql.NullInt64   `default:"64"`
+	Int32    sql.NullInt32   `default:"32"`
+	Float64  sql.NullFloat64 `default:"6.4"`
+	Bool     sql.NullBool    `default:"true"`
+	Time     sql.NullTime    `default:"2023-01-05 15:04:05"`
+	Empty    sql.NullString  `default:""`
+	Untagged sql.NullInt64
+	Pointer  *sql.NullString `default:"bar"`
+}
+
+func (s *SQLSuite) TestSetDefaultsNull(c *C) {
+	foo := &ExampleNull{}
+	SetDefaults(foo)
+
+	c.Assert(foo.String, Equals, sql.NullString{String: "foo", Valid: true})
+	c.Assert(foo.Int64, Equals, sql.NullInt64{Int64: 64, Valid: true})
+	c.Assert(foo.Int32, Equals, sql.NullInt32{Int32: 32, Valid: true})
+	c.Assert(foo.Float64, Equals, sql.NullFloat64{Float64: 6.4, Valid: true})
+	c.Assert(foo.Bool, Equals, sql.NullBool{Bool: true, Valid: true})
+	c.Assert(foo.Time.Valid, Equals, true)
+	c.Assert(foo.Time.Time.Equal(time.Date(2023, 1, 5, 15, 4, 5, 0, time.UTC)), Equals, true)
+	c.Assert(foo.Empty, Equals, sql.NullString{})
+	c.Assert(foo.Untagged, Equals, sql.NullInt64{})
+	c.Assert(*foo.Pointer, Equals, sql.NullString{String: "bar", Valid: true})
+}
+
+func (s *SQLSuite) TestSetDefaultsNullValid(c *C) {
+	foo := &ExampleNull{
+		String: sql.NullString{String: "", Valid: true},
+		Int64:  sql.NullInt64{Int64: 1, Valid: true},
+	}
+	SetDefaults(foo)
+
+	c.Assert(foo.String, Equals, sql.NullString{String: "", Valid: true})
+	c.Assert(foo.Int64, Equals, sql.NullInt64{Int64: 1, Valid: true})
+}
+
+func (s *SQLSuite) TestCheckDefaultsNull(c *C) {
+	errs := CheckDefaults(&struct {
+		Valid   sql.NullInt64 `default:"1"`
+		Invalid sql.NullInt64 `default:"one"`
+	}{})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Invalid: .*invalid syntax`)
+}
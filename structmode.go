@@ -0,0 +1,76 @@
+package godefault
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// The default tag of a struct field, not a pointer, chooses how its fields
+// are filled:
+//
+//	Server   ServerConfig                         // always, from their own tags
+//	Advanced TuningParams `default:"skipzero"` // only when Advanced is zero
+//	Legacy   LegacyParams `default:"-"`        // never
+//	Reset    ResetParams  `default:"zero"`     // zeroed, see zeroSentinel
+//
+// skipzero treats a section with any field set, e.g. by a config file, as
+// complete. Anything else is an error, the fields still getting their
+// defaults as without a tag: the tag of a struct has no value to parse, but
+// a lookup: or ref: reference, or a json: object or csv: values overriding
+// the defaults of some fields, see isInheritedDefault.
+const (
+	structModeRecurse  = ""
+	structModeSkipZero = "skipzero"
+)
+
+// checkStructMode validates the default tag of a struct field.
+func checkStructMode(value string) error {
+	switch value {
+	case structModeRecurse, structModeSkipZero, "-", zeroSentinel, forcedZeroSentinel:
+		return nil
+	}
+	if isLookupRef(value) {
+		_, err := parseLookupRef(value)
+		return err
+	}
+	if isSiblingRef(value) {
+		_, suffix, err := parseSiblingRef(value)
+		if err != nil || suffix == "" {
+			return err
+		}
+		return fmt.Errorf("%s: a struct can't have a suffix, it is not a string", value)
+	}
+	if isCSVDefault(value) {
+		_, err := csvValues(value)
+		return err
+	}
+	if isInheritedDefault(value) {
+		_, err := parseJSONValue(value, reflect.TypeOf(map[string]json.RawMessage(nil)), false)
+		return err
+	}
+
+	return fmt.Errorf("invalid struct default %q, expected %q or \"-\"", value, structModeSkipZero)
+}
+
+// skipsStruct reports whether the fields of the struct field are left alone
+// by its default tag, failing the field when the tag is invalid, its fields
+// then filled as usual.
+func skipsStruct(field *FieldData) bool {
+	if err := checkStructMode(field.TagValue); err != nil {
+		field.fail(parseErrorOf(err))
+		return false
+	}
+	if isZeroSentinel(field.Value.Type(), field.TagValue) {
+		setZero(field)
+		return true
+	}
+
+	return field.TagValue == structModeSkipZero && !field.Value.IsZero()
+}
+
+// isStructModeType reports whether the default tag of a field of type t is a
+// struct mode.
+func isStructModeType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && isStructType(t)
+}
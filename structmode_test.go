@@ -0,0 +1,85 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type StructModeSuite struct{}
+
+var _ = Suite(&StructModeSuite{})
+
+type TuningParams struct {
+	Workers int `default:"4"`
+	Queue   int `default:"100"`
+}
+
+type ExampleStructMode struct {
+	Always   TuningParams
+	Advanced TuningParams  `default:"skipzero"`
+	Fresh    TuningParams  `default:"skipzero"`
+	Skipped  TuningParams  `default:"-"`
+	Pointer  *TuningParams `default:"skipzero"`
+}
+
+func (s *StructModeSuite) TestSkipZero(c *C) {
+	foo := &ExampleStructMode{Always: TuningParams{Workers: 1}, Advanced: TuningParams{Workers: 1}}
+	SetDefaults(foo)
+
+	c.Assert(foo.Always, Equals, TuningParams{1, 100})
+	c.Assert(foo.Advanced, Equals, TuningParams{1, 0})
+	c.Assert(foo.Fresh, Equals, TuningParams{4, 100})
+	c.Assert(foo.Skipped, Equals, TuningParams{})
+	// Pointers are allocated by any tag, then filled.
+	c.Assert(*foo.Pointer, Equals, TuningParams{4, 100})
+}
+
+func (s *StructModeSuite) TestSkipZeroPlanAndLayers(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExampleStructMode{}))
+	c.Assert(err, IsNil)
+	foo := &ExampleStructMode{Advanced: TuningParams{Queue: 1}}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Advanced, Equals, TuningParams{0, 1})
+	c.Assert(foo.Fresh, Equals, TuningParams{4, 100})
+
+	bar := &ExampleStructMode{Advanced: TuningParams{Queue: 1}}
+	layer := MapLayer(map[string]string{"Advanced.Workers": "8", "Fresh.Workers": "8"})
+	c.Assert(Apply(bar, layer, TagLayer()), IsNil)
+	c.Assert(bar.Advanced, Equals, TuningParams{0, 1})
+	c.Assert(bar.Fresh, Equals, TuningParams{8, 100})
+}
+
+func (s *StructModeSuite) TestInvalidMode(c *C) {
+	foo := &struct {
+		Tuning TuningParams `default:"always"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Tuning: invalid struct default "always", expected "skipzero" or "-"`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	// The fields still get their defaults.
+	c.Assert(foo.Tuning, Equals, TuningParams{4, 100})
+
+	bar := &struct {
+		Tuning TuningParams `default:"always"`
+	}{}
+	SetDefaults(bar)
+	c.Assert(bar.Tuning, Equals, TuningParams{4, 100})
+
+	plan, err := Compile(reflect.TypeOf(*bar))
+	c.Assert(err, IsNil)
+	bar.Tuning = TuningParams{}
+	c.Assert(plan.Apply(bar), ErrorMatches, `Tuning: invalid struct default "always".*`)
+	c.Assert(bar.Tuning, Equals, TuningParams{4, 100})
+
+	bar.Tuning = TuningParams{}
+	c.Assert(Apply(bar, TagLayer()), ErrorMatches, `Tuning: invalid struct default "always".*`)
+	c.Assert(bar.Tuning, Equals, TuningParams{4, 100})
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Tuning: invalid struct default "always".*`)
+	c.Assert(CheckDefaults(&ExampleStructMode{}), HasLen, 0)
+}
@@ -0,0 +1,44 @@
+package godefault
+
+import (
+	"reflect"
+	"sync"
+)
+
+var syncMapType = reflect.TypeOf(sync.Map{})
+
+// fillSyncMap stores the entries of the default of a sync.Map field, written
+// like the default of a map[string]string:
+//
+//	Routes sync.Map `default:"{/:index,/health:ok}"`
+//
+// Keys and values are stored as strings. The filler never descends into a
+// sync.Map, an untagged one is left as its usable zero value, and a map
+// holding any entry is left alone.
+func fillSyncMap(field *FieldData) {
+	if field.TagValue == "" {
+		return
+	}
+	m := field.Value.Addr().Interface().(*sync.Map)
+	empty := true
+	m.Range(func(key, value interface{}) bool {
+		empty = false
+		return false
+	})
+	if !empty {
+		return
+	}
+
+	sep, ok := separator(field)
+	if !ok {
+		return
+	}
+	entries, ok, err := splitMapTagSep(field.TagValue, sep)
+	field.check(err)
+	if !ok || err != nil {
+		return
+	}
+	for _, entry := range entries {
+		m.Store(entry.key, fillElement(field, stringType, entry.value, entry.key).String())
+	}
+}
@@ -0,0 +1,52 @@
+package godefault
+
+import (
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+type SyncMapSuite struct{}
+
+var _ = Suite(&SyncMapSuite{})
+
+type ExampleSyncMap struct {
+	Routes   sync.Map  `default:"{/:index,/health:ok}"`
+	Zones    *sync.Map `default:"{eu:env:GODEFAULT_TEST_UNSET:paris}"`
+	Preset   sync.Map  `default:"{a:1}"`
+	Untagged sync.Map
+}
+
+func (s *SyncMapSuite) TestSyncMap(c *C) {
+	foo := &ExampleSyncMap{}
+	foo.Preset.Store("b", "2")
+	SetDefaults(foo)
+
+	entries := func(m *sync.Map) map[interface{}]interface{} {
+		result := map[interface{}]interface{}{}
+		m.Range(func(key, value interface{}) bool {
+			result[key] = value
+			return true
+		})
+		return result
+	}
+	c.Assert(entries(&foo.Routes), DeepEquals, map[interface{}]interface{}{"/": "index", "/health": "ok"})
+	c.Assert(foo.Zones, NotNil)
+	c.Assert(entries(foo.Zones), DeepEquals, map[interface{}]interface{}{"eu": "paris"})
+	c.Assert(entries(&foo.Preset), DeepEquals, map[interface{}]interface{}{"b": "2"})
+	c.Assert(entries(&foo.Untagged), HasLen, 0)
+
+	foo.Untagged.Store("k", "v")
+	v, ok := foo.Untagged.Load("k")
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, "v")
+}
+
+func (s *SyncMapSuite) TestSyncMapErrors(c *C) {
+	c.Assert(CheckDefaults(&ExampleSyncMap{}), HasLen, 0)
+	errs := CheckDefaults(&struct {
+		Routes sync.Map `default:"[a,b]"`
+	}{})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `Routes: invalid map value "\[a,b\]", expected \{key:value,...\}`)
+}
@@ -0,0 +1,798 @@
+package godefault
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The parsers in this file turn a raw tag value into a typed value. They are
+// shared by the default filler, which reports their errors through
+// FieldData.check (Fill ignores them, keeping the historical "invalid value
+// becomes zero" behavior), and by CheckDefaults.
+//
+// Tags may come from override files, so every parser is defined for any
+// input: it returns a value or an error, never panics and never loops, the
+// patterns being RE2 ones, linear in the input. fuzz_test.go holds a target
+// per parser, seeded with the examples of their doc comments.
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	stringType   = reflect.TypeOf("")
+)
+
+var (
+	sliceTagPattern     = regexp.MustCompile(`^\[(.*)\]$`)
+	mapTagPattern       = regexp.MustCompile(`^\{(.*)\}$`)
+	placeholderPattern  = regexp.MustCompile(`\{\{(\w+):([^{}]*)\}\}`)
+	placeholderArgs     = regexp.MustCompile(`^-?\d*,-?\d*,-?\d*$`)
+	intTransformPattern = regexp.MustCompile(`\|([-+*])(\d+)$`)
+)
+
+// isIntegerType reports whether t, or the type it points to, is filled by
+// the integer parsers. time.Duration has its own parser.
+func isIntegerType(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t == durationType {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// splitIntTransform splits the transform ending an integer default from the
+// value it applies to. The transform is applied once the value is resolved
+// and parsed, and is one of:
+//
+//	|+N  add N
+//	|-N  subtract N
+//	|*N  multiply by N
+//
+// e.g. "env:PORT:8080|+1" is the value of $PORT, or 8080, plus one.
+func splitIntTransform(value string) (string, string) {
+	loc := intTransformPattern.FindStringIndex(value)
+	if loc == nil {
+		return value, ""
+	}
+
+	return value[:loc[0]], value[loc[0]:]
+}
+
+// applyIntTransform applies a transform returned by splitIntTransform to n,
+// failing when the result doesn't fit into t.
+func applyIntTransform(n int64, transform string, t reflect.Type) (int64, error) {
+	match := intTransformPattern.FindStringSubmatch(transform)
+	operand, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	result := new(big.Int).SetInt64(n)
+	switch match[1] {
+	case "+":
+		result.Add(result, big.NewInt(operand))
+	case "-":
+		result.Sub(result, big.NewInt(operand))
+	case "*":
+		result.Mul(result, big.NewInt(operand))
+	}
+	if !result.IsInt64() || reflect.Zero(t).OverflowInt(result.Int64()) {
+		return 0, overflowf("%d%s overflows %s", n, transform, t)
+	}
+
+	return result.Int64(), nil
+}
+
+// applyUintTransform is applyIntTransform for unsigned integers.
+func applyUintTransform(n uint64, transform string, t reflect.Type) (uint64, error) {
+	match := intTransformPattern.FindStringSubmatch(transform)
+	operand, err := strconv.ParseUint(match[2], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	result := new(big.Int).SetUint64(n)
+	switch match[1] {
+	case "+":
+		result.Add(result, new(big.Int).SetUint64(operand))
+	case "-":
+		result.Sub(result, new(big.Int).SetUint64(operand))
+	case "*":
+		result.Mul(result, new(big.Int).SetUint64(operand))
+	}
+	if !result.IsUint64() || reflect.Zero(t).OverflowUint(result.Uint64()) {
+		return 0, overflowf("%d%s overflows %s", n, transform, t)
+	}
+
+	return result.Uint64(), nil
+}
+
+func parseBoolValue(value string) (bool, error) {
+	return strconv.ParseBool(value)
+}
+
+// parseIntValue parses a base 10 integer or quantity, followed by an
+// optional transform, and reports an error when the result does not fit into
+// the destination type t.
+func parseIntValue(value string, t reflect.Type) (int64, error) {
+	value, transform := splitIntTransform(value)
+	n, err := parseIntLiteral(value, t)
+	if err != nil || transform == "" {
+		return n, err
+	}
+
+	return applyIntTransform(n, transform, t)
+}
+
+func parseIntLiteral(value string, t reflect.Type) (int64, error) {
+	if err := checkNotPercent(value, t); err != nil {
+		return 0, err
+	}
+	if isQuantity(value) {
+		return parseQuantityInt(value[len(quantityPrefix):], t)
+	}
+
+	if n, ok, err := enumValue(value, t); ok {
+		return n, err
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, enumError(value, t, err)
+	}
+	if reflect.Zero(t).OverflowInt(n) {
+		return 0, overflowf("value %s overflows %s", value, t)
+	}
+
+	return n, nil
+}
+
+// parseUintValue parses a base 10 unsigned integer or quantity, followed by an
+// optional transform, and reports an error when the result does not fit into
+// the destination type t.
+func parseUintValue(value string, t reflect.Type) (uint64, error) {
+	value, transform := splitIntTransform(value)
+	n, err := parseUintLiteral(value, t)
+	if err != nil || transform == "" {
+		return n, err
+	}
+
+	return applyUintTransform(n, transform, t)
+}
+
+func parseUintLiteral(value string, t reflect.Type) (uint64, error) {
+	if err := checkNotPercent(value, t); err != nil {
+		return 0, err
+	}
+	if isQuantity(value) {
+		return parseQuantityUint(value[len(quantityPrefix):], t)
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if reflect.Zero(t).OverflowUint(n) {
+		return 0, overflowf("value %s overflows %s", value, t)
+	}
+
+	return n, nil
+}
+
+// percentSuffix ends the defaults of float fields written as a percentage,
+// which are divided by 100:
+//
+//	Threshold float64   `default:"85%"`         // 0.85
+//	Steps     []float64 `default:"[10%,20%,50%]"`
+//
+// Integer fields reject it, 50% of an int being either 0 or 50.
+const percentSuffix = "%"
+
+// checkNotPercent rejects the percentages given to an integer field of type
+// t, see percentSuffix.
+func checkNotPercent(value string, t reflect.Type) error {
+	if strings.HasSuffix(value, percentSuffix) {
+		return fmt.Errorf("invalid %s value %q: percentages only apply to floats", t, value)
+	}
+
+	return nil
+}
+
+// parseFloatValue parses a floating point number, or a percentage, and
+// reports an error when it does not fit into the destination type t.
+func parseFloatValue(value string, t reflect.Type) (float64, error) {
+	if isQuantity(value) {
+		return parseQuantityFloat(value[len(quantityPrefix):], t)
+	}
+
+	number, scale := value, 1.0
+	if strings.HasSuffix(value, percentSuffix) {
+		number, scale = strings.TrimSuffix(value, percentSuffix), 100
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, err
+	}
+	n /= scale
+	if reflect.Zero(t).OverflowFloat(n) {
+		return 0, overflowf("value %s overflows %s", value, t)
+	}
+
+	return n, nil
+}
+
+func parseDurationValue(value string) (time.Duration, error) {
+	return time.ParseDuration(value)
+}
+
+// splitSliceTag splits the bracket syntax used by slice defaults, e.g.
+// "[1,2,3]", into its elements, see splitTagList. The boolean is false when
+// value does not use the bracket syntax.
+func splitSliceTag(value string) ([]string, bool, error) {
+	return splitSliceTagSep(value, defaultSeparator)
+}
+
+// splitSliceTagSep is splitSliceTag for elements separated by sep, see
+// sepTag.
+func splitSliceTagSep(value string, sep rune) ([]string, bool, error) {
+	matchs := sliceTagPattern.FindStringSubmatch(value)
+	if len(matchs) != 2 {
+		return nil, false, nil
+	}
+
+	entries, err := splitTagList(matchs[1], false, sep)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid slice value %q: %w", value, err)
+	}
+	elems := make([]string, len(entries))
+	for i, entry := range entries {
+		elems[i] = entry.value
+	}
+
+	return elems, true, nil
+}
+
+// mapEntry is a key:value pair of a map default, both raw.
+type mapEntry struct {
+	key, value string
+}
+
+// splitMapTag splits the brace syntax used by map defaults, e.g.
+// "{a:1,b:2}", into its entries, see splitTagList. The boolean is false when
+// value does not use the brace syntax.
+func splitMapTag(value string) ([]mapEntry, bool, error) {
+	return splitMapTagSep(value, defaultSeparator)
+}
+
+// splitMapTagSep is splitMapTag for entries separated by sep, see sepTag.
+func splitMapTagSep(value string, sep rune) ([]mapEntry, bool, error) {
+	matchs := mapTagPattern.FindStringSubmatch(value)
+	if len(matchs) != 2 {
+		return nil, false, nil
+	}
+
+	entries, err := splitTagList(matchs[1], true, sep)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return entries, true, nil
+}
+
+// splitTagList splits the body of a bracket or brace default into its
+// elements, separated by sep, a comma unless chosen by sepTag, or its
+// entries, key and value separated by the first colon when entries is set:
+//
+//	[a, b]            a and b, spaces around unquoted elements are trimmed
+//	[a|,b]            "a,b", "|," is a literal comma
+//	["a,b", " c]d "]  "a,b" and " c]d ", quoted with Go's escapes
+//	[,""]             two empty elements
+//	{k|:1:v}          "k:1" to "v", "|:" is a literal colon
+//	{"k:1":"v,w"}     "k:1" to "v,w"
+//	[[1,2],{a:[3]}]   "[1,2]" and "{a:[3]}"
+//
+// Only elements starting with a double quote are quoted, inside which "|,"
+// is kept as it is. Brackets and braces nest: a group they close, quotes
+// included, is kept as it is, escapes too, for the element it belongs to to
+// be split in turn, so that a []map[string][]int default descends one level
+// per element. A bracket or brace that isn't closed is a literal. An empty or blank body has no elements. "|" followed by
+// sep is a literal sep, "||" when sep is "|", which then can't escape colons.
+// A whitespace sep separates the elements by runs of any whitespace.
+func splitTagList(body string, entries bool, sep rune) ([]mapEntry, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return []mapEntry{}, nil
+	}
+
+	sc := &tagScanner{s: body, sep: sep, space: unicode.IsSpace(sep), colons: entries && sep != '|'}
+	var result []mapEntry
+	for {
+		start := sc.i
+		var entry mapEntry
+		var err error
+		if entries {
+			if entry.key, err = sc.part(true); err != nil {
+				return nil, err
+			}
+			if sc.i == len(sc.s) || sc.s[sc.i] != ':' {
+				return nil, fmt.Errorf("invalid map entry %q, expected key:value", body[start:sc.i])
+			}
+			sc.i++
+		}
+		if entry.value, err = sc.part(false); err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+		if !sc.skipSep() {
+			return result, nil
+		}
+	}
+}
+
+// tagScanner reads the elements of splitTagList.
+type tagScanner struct {
+	s   string
+	i   int
+	sep rune
+	// space is set for a whitespace sep, colons when "|:" is a literal colon.
+	space, colons bool
+}
+
+// sepAt returns the length of the separator at i, 0 if there is none.
+func (sc *tagScanner) sepAt(i int) int {
+	if i >= len(sc.s) {
+		return 0
+	}
+	r, size := utf8.DecodeRuneInString(sc.s[i:])
+	if r == sc.sep || (sc.space && unicode.IsSpace(r)) {
+		return size
+	}
+
+	return 0
+}
+
+// stopAt reports whether the part being read ends at i, at the end, a
+// separator or, for keys, a colon.
+func (sc *tagScanner) stopAt(i int, key bool) bool {
+	return i >= len(sc.s) || sc.sepAt(i) != 0 || (key && sc.s[i] == ':')
+}
+
+// skipSep consumes the separator that is next, a run of them for whitespace,
+// and reports whether there was one.
+func (sc *tagScanner) skipSep() bool {
+	size := sc.sepAt(sc.i)
+	if size == 0 {
+		return false
+	}
+	for sc.i += size; sc.space && sc.sepAt(sc.i) != 0; {
+		sc.i += sc.sepAt(sc.i)
+	}
+
+	return true
+}
+
+// part reads a quoted or unquoted part of an element, up to a separator, a
+// colon for keys, or the end. Unquoted, "|" followed by the separator, or a
+// colon in map defaults, is that character.
+func (sc *tagScanner) part(key bool) (string, error) {
+	start := sc.i
+	for sc.i < len(sc.s) && (sc.s[sc.i] == ' ' || sc.s[sc.i] == '\t') {
+		sc.i++
+	}
+	if sc.i < len(sc.s) && sc.s[sc.i] == '"' {
+		return sc.quoted(key)
+	}
+
+	var b strings.Builder
+	// Escapes come first, "||" being one when sep is "|".
+	for sc.i = start; sc.i < len(sc.s); {
+		if sc.s[sc.i] == '|' {
+			if size := sc.sepAt(sc.i + 1); size != 0 {
+				b.WriteString(sc.s[sc.i+1 : sc.i+1+size])
+				sc.i += 1 + size
+				continue
+			}
+			if sc.colons && sc.i+1 < len(sc.s) && sc.s[sc.i+1] == ':' {
+				b.WriteByte(':')
+				sc.i += 2
+				continue
+			}
+		}
+		if sc.stopAt(sc.i, key) {
+			break
+		}
+		if end := sc.group(sc.i); end > 0 {
+			b.WriteString(sc.s[sc.i:end])
+			sc.i = end
+			continue
+		}
+		b.WriteByte(sc.s[sc.i])
+		sc.i++
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// group returns the end of the bracket or brace group starting at i, nested
+// groups and quoted strings included, or 0 when there is none: i is not an
+// opening bracket or brace, or the group isn't closed, or is closed by the
+// wrong one.
+func (sc *tagScanner) group(i int) int {
+	var closers []byte
+	for ; i < len(sc.s); i++ {
+		switch c := sc.s[i]; {
+		case c == '[':
+			closers = append(closers, ']')
+		case c == '{':
+			closers = append(closers, '}')
+		case len(closers) == 0:
+			return 0
+		case c == ']' || c == '}':
+			if c != closers[len(closers)-1] {
+				return 0
+			}
+			if closers = closers[:len(closers)-1]; len(closers) == 0 {
+				return i + 1
+			}
+		case c == '"':
+			for i++; i < len(sc.s) && sc.s[i] != '"'; i++ {
+				if sc.s[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+// quoted reads the quoted part starting at the scanner, which only spaces
+// may follow before the end of the part.
+func (sc *tagScanner) quoted(key bool) (string, error) {
+	start := sc.i
+	for sc.i++; sc.i < len(sc.s) && sc.s[sc.i] != '"'; sc.i++ {
+		if sc.s[sc.i] == '\\' {
+			sc.i++
+		}
+	}
+	if sc.i >= len(sc.s) {
+		return "", fmt.Errorf("unterminated quoted element %s", sc.s[start:])
+	}
+	sc.i++
+	literal := sc.s[start:sc.i]
+	part, err := strconv.Unquote(literal)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted element %s", literal)
+	}
+
+	for !sc.stopAt(sc.i, key) && (sc.s[sc.i] == ' ' || sc.s[sc.i] == '\t') {
+		sc.i++
+	}
+	if !sc.stopAt(sc.i, key) {
+		return "", fmt.Errorf("unexpected %q after quoted element %s", sc.s[sc.i], literal)
+	}
+
+	return part, nil
+}
+
+// dataURIPrefix introduces a []byte default given as a data URI, RFC 2397,
+// whose payload is base64 or percent encoded:
+//
+//	Icon []byte `default:"data:image/png;base64,iVBORw0KGgo="`
+//	Text []byte `default:"data:,hello%20world"`
+//
+// Any other value is used as it is, but for a base64: prefix followed by
+// standard base64, which is terser for the elements of a [][]byte, and a
+// hex: prefix followed by hexadecimal digits:
+//
+//	Certs [][]byte `default:"[base64:AAA=,base64:BBB=]"`
+//	Key   []byte   `default:"hex:00ff"`
+//
+// See also fillBytes for the file: prefix.
+const (
+	dataURIPrefix = "data:"
+	base64Prefix  = "base64:"
+	hexPrefix     = "hex:"
+)
+
+// parseBytesValue parses a []byte default, see dataURIPrefix.
+func parseBytesValue(value string) ([]byte, error) {
+	if strings.HasPrefix(value, base64Prefix) {
+		data, err := base64.StdEncoding.DecodeString(value[len(base64Prefix):])
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 value %q: %w", value, err)
+		}
+		return data, nil
+	}
+	if strings.HasPrefix(value, hexPrefix) {
+		data, err := hex.DecodeString(value[len(hexPrefix):])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value %q: %w", value, err)
+		}
+		return data, nil
+	}
+	if !strings.HasPrefix(value, dataURIPrefix) {
+		return []byte(value), nil
+	}
+
+	i := strings.IndexByte(value, ',')
+	if i < 0 {
+		return nil, fmt.Errorf("invalid data URI %q, expected data:[<mediatype>][;base64],<data>", value)
+	}
+	header, payload := value[len(dataURIPrefix):i], value[i+1:]
+	if strings.HasSuffix(header, ";base64") {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data URI payload: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data URI payload: %w", err)
+	}
+
+	return []byte(data), nil
+}
+
+// checkPlaceholders validates the {{date:Y,M,D}} and {{time:h,m,s}}
+// placeholders of a string default, see parseDateTimeString. Braces not
+// written like a placeholder, e.g. {{ .Name }}, are left alone.
+func checkPlaceholders(value string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(value, -1) {
+		args, location, hasLocation := match[2], "", false
+		if i := strings.IndexByte(args, '@'); i >= 0 {
+			args, location, hasLocation = args[:i], args[i+1:], true
+		}
+		if (match[1] != "date" && match[1] != "time") || !placeholderArgs.MatchString(args) {
+			return fmt.Errorf("invalid placeholder %q, expected {{date:Y,M,D[@Location]}} or {{time:h,m,s[@Location]}}", match[0])
+		}
+		if hasLocation {
+			if _, err := time.LoadLocation(location); err != nil || location == "" {
+				return fmt.Errorf("invalid placeholder %q: unknown location %q", match[0], location)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkTagValue validates value against the parser the default filler uses
+// for type t. Values resolved at fill time are checked for their syntax only:
+// env: references against their fallback, envs| mappings and date
+// placeholders for their grammar.
+func checkTagValue(t reflect.Type, value string) error {
+	return checkTagValueSep(t, value, defaultSeparator)
+}
+
+// checkTagValueSep is checkTagValue for slice and map values whose elements
+// are separated by sep, see sepTag.
+func checkTagValueSep(t reflect.Type, value string, sep rune) error {
+	if isZeroSentinel(t, value) {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if isChooseRef(value) {
+		return checkChooseRef(value, func(branch string) error {
+			return checkTagValueSep(t, branch, sep)
+		})
+	}
+	// Variables are only known at fill time, their fallback is checked.
+	if isVarRef(value) {
+		transform := ""
+		if isIntegerType(t) {
+			value, transform = splitIntTransform(value)
+		}
+		_, _, fallback, err := parseVarRef(value)
+		if err != nil || fallback == "" {
+			return err
+		}
+		value = fallback + transform
+	}
+	// References are checked against their fallback, if any.
+	ref, prefix := value, envRefPrefix
+	if strings.HasPrefix(value, envIndirectPrefix) {
+		ref, prefix = envRefPrefix+value[len(envIndirectPrefix):], envIndirectPrefix
+	}
+	if _, fallback, ok := parseEnvRef(ref); ok {
+		if fallback == "" {
+			return nil
+		}
+		value = resolveEnvRef(value, t, nil)
+	} else if strings.HasPrefix(ref, envRefPrefix) {
+		return fmt.Errorf("invalid env reference %q, expected %sKEY[:fallback]", value, prefix)
+	}
+	// Keys are checked against their fallback, if any.
+	if isKVRef(value) {
+		transform := ""
+		if isIntegerType(t) {
+			value, transform = splitIntTransform(value)
+		}
+		_, _, fallback, err := parseKVRef(value)
+		if err != nil || fallback == "" {
+			return err
+		}
+		value = fallback + transform
+	}
+	if isZeroSentinel(t, value) {
+		return nil
+	}
+	// Formatted values are only known once the variables are read.
+	if isPrintfRef(value) {
+		return checkPrintfRef(value)
+	}
+	// Claims are only known once the token is read.
+	if isJWTClaimRef(value) {
+		_, _, err := parseJWTClaimRef(value)
+		return err
+	}
+	// Siblings are only known once the struct is filled.
+	if isExprRef(value) {
+		return checkExprRef(t, value)
+	}
+	if isSiblingRef(value) {
+		return checkSiblingRef(t, value)
+	}
+	// Lookups are only known once the struct is filled.
+	if isLookupRef(value) {
+		_, err := parseLookupRef(value)
+		return err
+	}
+	if isRatioRef(value) {
+		return checkRatioRef(t, value)
+	}
+	// Generators are only run at fill time.
+	if isGenRef(value) {
+		_, _, err := parseGenRef(value)
+		return err
+	}
+	// Every value a host: rule may pick is checked.
+	if isHostRef(value) {
+		rules, err := parseHostRules(value)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			if err := checkTagValueSep(t, rule.value, sep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if isIntegerType(t) {
+		resolved, err := resolveCPUExpr(value)
+		if err != nil {
+			return err
+		}
+		value = resolved
+		// Ports are only known at fill time, the field must hold them all.
+		if isFreePort(value) {
+			value = "65535"
+		}
+	}
+	// Lengths are only known at fill time, their transform is checked.
+	if isLenRef(value) {
+		if !isIntegerType(t) {
+			return nil
+		}
+		_, transform := splitIntTransform(value)
+		value = "0" + transform
+	}
+
+	switch t {
+	case durationType:
+		_, err := parseDurationValue(value)
+		return err
+	case timeType:
+		_, err := parseDateTime(value)
+		return err
+	case regexpType:
+		_, err := regexp.Compile(value)
+		return err
+	case syncMapType:
+		t = reflect.TypeOf(map[string]string(nil))
+	case hostPortType:
+		return checkHostPort(value)
+	}
+	if isNullType(t) {
+		return checkTagValue(t.Field(0).Type, value)
+	}
+	if isLooseType(t) {
+		_, err := parseJSONValue(value, t, false)
+		return err
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		_, err := parseBoolValue(value)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := parseIntValue(value, t)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err := parseUintValue(value, t)
+		return err
+	case reflect.Float32, reflect.Float64:
+		_, err := parseFloatValue(value, t)
+		return err
+	case reflect.String:
+		if strings.HasPrefix(value, "envs|") {
+			return checkEnvsValue(value)
+		}
+		return checkPlaceholders(value)
+	case reflect.Slice:
+		if isMakeLen(value) && t.Elem().Kind() != reflect.Uint8 {
+			_, err := parseMakeLen(value)
+			return err
+		}
+		switch {
+		case t.Elem().Kind() == reflect.Uint8:
+			return checkBytesValue(value)
+		case isJSONValue(value):
+			return checkJSONSlice(t, value)
+		case t.Elem().Kind() == reflect.Struct:
+			return nil
+		}
+		elems, ok, err := splitSliceTagSep(value, sep)
+		if !ok {
+			return fmt.Errorf("invalid slice value %q, expected [a,b,...]", value)
+		}
+		if err != nil {
+			return err
+		}
+		for _, elem := range elems {
+			if err := checkTagValue(t.Elem(), elem); err != nil {
+				return err
+			}
+		}
+	case reflect.Func:
+		return checkFuncValue(value)
+	case reflect.Chan:
+		_, err := parseChanValue(t, value)
+		return err
+	case reflect.Array:
+		if isStructType(t.Elem()) {
+			return checkArrayValue(t, value)
+		}
+	case reflect.Map:
+		entries, ok, err := splitMapTagSep(value, sep)
+		if !ok {
+			return fmt.Errorf("invalid map value %q, expected {key:value,...}", value)
+		}
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := checkTagValue(t.Key(), entry.key); err != nil {
+				return fmt.Errorf("invalid map key: %w", err)
+			}
+			if err := checkTagValue(t.Elem(), entry.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
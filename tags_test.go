@@ -0,0 +1,187 @@
+package godefault
+
+import (
+	"context"
+
+	. "gopkg.in/check.v1"
+)
+
+type TagsSuite struct{}
+
+var _ = Suite(&TagsSuite{})
+
+func (s *TagsSuite) TestSplitSliceTag(c *C) {
+	for value, expected := range map[string][]string{
+		`[]`:                   {},
+		`[ ]`:                  {},
+		`[a,b]`:                {"a", "b"},
+		`[ a , b ]`:            {"a", "b"},
+		`[,]`:                  {"", ""},
+		`[a,]`:                 {"a", ""},
+		`[""]`:                 {""},
+		`["",a, "" ]`:          {"", "a", ""},
+		`[a|,b,c]`:             {"a,b", "c"},
+		`["a,b","c]d"]`:        {"a,b", "c]d"},
+		`[" a ", b c]`:         {" a ", "b c"},
+		`["a\"b\\c"]`:          {`a"b\c`},
+		`["a|,b"]`:             {"a|,b"},
+		`[a"b]`:                {`a"b`},
+		`["x\ty",[1]]`:         {"x\ty", "[1]"},
+		`[a|:b]`:               {"a|:b"},
+		`["{{date:1,0,0}}",x]`: {"{{date:1,0,0}}", "x"},
+		`[{{date:1,0,0}},x]`:   {"{{date:1,0,0}}", "x"},
+		`[[1,2],[3]]`:          {"[1,2]", "[3]"},
+		`[ [1, 2] , [] ]`:      {"[1, 2]", "[]"},
+		`[{a:1,b:2},{}]`:       {"{a:1,b:2}", "{}"},
+		`[[["a,b"],[c|,d]]]`:   {`[["a,b"],[c|,d]]`},
+		`[["]",x]]`:            {`["]",x]`},
+		`[a[1,2]b,c]`:          {"a[1,2]b", "c"},
+		`[[a,b}]`:              {"[a", "b}"},
+		`[[a,b]`:               {"[a", "b"},
+		`[a],[b]`:              {"a]", "[b"},
+	} {
+		elems, ok, err := splitSliceTag(value)
+		c.Assert(ok, Equals, true, Commentf("%s", value))
+		c.Assert(err, IsNil, Commentf("%s", value))
+		c.Assert(elems, DeepEquals, expected, Commentf("%s", value))
+	}
+
+	for value, message := range map[string]string{
+		`["a]`:       `invalid slice value .*: unterminated quoted element "a`,
+		`["a"b]`:     `invalid slice value .*: unexpected 'b' after quoted element "a"`,
+		`["\q"]`:     `invalid slice value .*: invalid quoted element "\\q"`,
+		`[a, "b" c]`: `invalid slice value .*: unexpected 'c' after quoted element "b"`,
+	} {
+		_, ok, err := splitSliceTag(value)
+		c.Assert(ok, Equals, true, Commentf("%s", value))
+		c.Assert(err, ErrorMatches, message, Commentf("%s", value))
+	}
+
+	_, ok, _ := splitSliceTag("a,b")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *TagsSuite) TestSplitMapTag(c *C) {
+	for value, expected := range map[string][]mapEntry{
+		`{}`:                 {},
+		`{a:1,b:2}`:          {{"a", "1"}, {"b", "2"}},
+		`{ a : 1 , b: 2 }`:   {{"a", "1"}, {"b", "2"}},
+		`{a:}`:               {{"a", ""}},
+		`{"":""}`:            {{"", ""}},
+		`{a|:b:c|,d}`:        {{"a:b", "c,d"}},
+		`{a:b:c}`:            {{"a", "b:c"}},
+		`{"a:b":"c,d"}`:      {{"a:b", "c,d"}},
+		`{"a":1:2}`:          {{"a", "1:2"}},
+		`{"a|,b":c}`:         {{"a|,b", "c"}},
+		`{a:"x, y", b:"}"}`:  {{"a", "x, y"}, {"b", "}"}},
+		`{a:[1,2],b:[]}`:     {{"a", "[1,2]"}, {"b", "[]"}},
+		`{a:{b:1,c:2}}`:      {{"a", "{b:1,c:2}"}},
+		`{[a:b]:{c:[d,e]}}`:  {{"[a:b]", "{c:[d,e]}"}},
+		`{a:{{date:0,0,0}}}`: {{"a", "{{date:0,0,0}}"}},
+	} {
+		entries, ok, err := splitMapTag(value)
+		c.Assert(ok, Equals, true, Commentf("%s", value))
+		c.Assert(err, IsNil, Commentf("%s", value))
+		c.Assert(entries, DeepEquals, expected, Commentf("%s", value))
+	}
+
+	for value, message := range map[string]string{
+		`{a}`:       `invalid map entry "a", expected key:value`,
+		`{a:1,b}`:   `invalid map entry "b", expected key:value`,
+		`{a:"b":c}`: `unexpected ':' after quoted element "b"`,
+		`{"a}`:      `unterminated quoted element "a`,
+	} {
+		_, _, err := splitMapTag(value)
+		c.Assert(err, ErrorMatches, message, Commentf("%s", value))
+	}
+}
+
+func (s *TagsSuite) TestQuotedElements(c *C) {
+	foo := &struct {
+		Names  []string          `default:"[\"a,b\", \"c]d\", plain , \"\"]"`
+		Ports  []int             `default:"[ 80, 443 ]"`
+		Labels map[string]string `default:"{\"team:name\": \"core, infra\", env: prod}"`
+		Broken []string          `default:"[\"a]"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Broken: invalid slice value .*: unterminated quoted element "a`)
+
+	c.Assert(foo.Names, DeepEquals, []string{"a,b", "c]d", "plain", ""})
+	c.Assert(foo.Ports, DeepEquals, []int{80, 443})
+	c.Assert(foo.Labels, DeepEquals, map[string]string{"team:name": "core, infra", "env": "prod"})
+	c.Assert(foo.Broken, IsNil)
+
+	c.Assert(CheckDefaults(foo), HasLen, 1)
+}
+
+func (s *TagsSuite) TestPercent(c *C) {
+	foo := &struct {
+		Threshold float64            `default:"85%"`
+		Small     float32            `default:"0.5%"`
+		Steps     []float64          `default:"[10%,20%,50%]"`
+		Weights   map[string]float64 `default:"{a:25%,b:0.75}"`
+		Pointer   *float64           `default:"-5%"`
+		Invalid   float64            `default:"%"`
+		Workers   int                `default:"50%"`
+		Replicas  uint               `default:"10%|+1"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Invalid: strconv.ParseFloat: parsing "": invalid syntax\n`+
+		`Workers: invalid int value "50%": percentages only apply to floats\n`+
+		`Replicas: invalid uint value "10%": percentages only apply to floats`)
+
+	c.Assert(foo.Threshold, Equals, 0.85)
+	c.Assert(foo.Small, Equals, float32(0.005))
+	c.Assert(foo.Steps, DeepEquals, []float64{0.1, 0.2, 0.5})
+	c.Assert(foo.Weights, DeepEquals, map[string]float64{"a": 0.25, "b": 0.75})
+	c.Assert(*foo.Pointer, Equals, -0.05)
+	c.Assert(foo.Workers, Equals, 0)
+
+	c.Assert(CheckDefaults(foo), HasLen, 3)
+}
+
+type ExampleNestedContainers struct {
+	Matrix   [][]int                   `default:"[[1,2],[3],[]]"`
+	Rows     []map[string]int          `default:"[{a:1,b:2},{c:3}]"`
+	Groups   map[string][]string       `default:"{admins:[alice,bob],guests:[]}"`
+	Limits   map[string]map[string]int `default:"{eu:{cpu:2,mem:4},us:{cpu:1}}"`
+	Deep     [][]map[string][]int      `default:"[[{a:[1,2]},{b:[]}],[]]"`
+	Quoted   [][]string                `default:"[[\"a,b\",\"]\"],[c|,d]]"`
+	Top      [][]int                   `default:"[[1,2];[3,4]]" sep:";"`
+	Pointers []*[]string               `default:"[[a],[b,c]]"`
+	Dates    []string                  `default:"[{{date:0,0,0}},x]"`
+}
+
+func (s *TagsSuite) TestNestedContainers(c *C) {
+	foo := &ExampleNestedContainers{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Matrix, DeepEquals, [][]int{{1, 2}, {3}, {}})
+	c.Assert(foo.Rows, DeepEquals, []map[string]int{{"a": 1, "b": 2}, {"c": 3}})
+	c.Assert(foo.Groups, DeepEquals, map[string][]string{"admins": {"alice", "bob"}, "guests": {}})
+	c.Assert(foo.Limits, DeepEquals, map[string]map[string]int{"eu": {"cpu": 2, "mem": 4}, "us": {"cpu": 1}})
+	c.Assert(foo.Deep, DeepEquals, [][]map[string][]int{{{"a": {1, 2}}, {"b": {}}}, {}})
+	c.Assert(foo.Quoted, DeepEquals, [][]string{{"a,b", "]"}, {"c,d"}})
+	c.Assert(foo.Top, DeepEquals, [][]int{{1, 2}, {3, 4}})
+	c.Assert(foo.Pointers, HasLen, 2)
+	c.Assert(*foo.Pointers[1], DeepEquals, []string{"b", "c"})
+	c.Assert(foo.Dates[0], Matches, `\d{4}-\d{2}-\d{2}`)
+
+	c.Assert(CheckDefaults(&ExampleNestedContainers{}), HasLen, 0)
+}
+
+func (s *TagsSuite) TestNestedContainersErrors(c *C) {
+	foo := &struct {
+		Matrix [][]int          `default:"[[1,x],[2]]"`
+		Rows   []map[string]int `default:"[{a:1},{b}]"`
+		Groups map[string][]int `default:"{a:[1,\"2]}"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Matrix\[0\]\[1\]: .*invalid syntax\n`+
+		`Rows\[1\]: invalid map entry "b", expected key:value\n`+
+		`Groups: unterminated quoted element "2\]`)
+	c.Assert(foo.Matrix, DeepEquals, [][]int{{1, 0}, {2}})
+
+	errs := CheckDefaults(foo)
+	c.Assert(errs, HasLen, 3)
+}
@@ -0,0 +1,174 @@
+package godefault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// SourceReplayed is a value set from a Trace by ReplayTrace.
+const SourceReplayed = "replayed"
+
+// Trace records the values one fill resolved, see CaptureTrace. It encodes
+// to JSON, e.g. to keep it in a golden file.
+type Trace struct {
+	Fields []TraceField `json:"fields"`
+	// Skipped are the paths of the fields whose value encoding/json can't
+	// encode, or not as it decodes it, e.g. functions, sync.Map and
+	// regexp.Regexp values. They are filled from their default on replay.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// TraceField is the value a fill gave to one field.
+type TraceField struct {
+	// Path is the dotted path of the field.
+	Path string `json:"path"`
+	// Source is where the value came from, one of the Source constants.
+	Source string `json:"source"`
+	// Value is the value encoded with encoding/json, in clear even for
+	// secret fields.
+	Value json.RawMessage `json:"value"`
+}
+
+// CaptureTrace fills v, a pointer to a struct, with a Filler configured with
+// opts, and records the value every tagged field got from its default: the
+// environment variables, key-value stores and generators read, the branches
+// picked and the dates computed. Replaying the trace with ReplayTrace gives
+// the same values whatever the environment, which makes defaults depending
+// on it testable:
+//
+//	trace, err := godefault.CaptureTrace(&config)
+//	...
+//	err = godefault.ReplayTrace(&replayed, trace) // replayed equals config
+//
+// Fields that already had a value or whose default failed are not recorded,
+// nor are those whose value doesn't survive encoding, listed in
+// Trace.Skipped. The values are kept as encoding/json encodes them, secret
+// ones included, which keeps a trace out of logs. The error is the one of the
+// fill.
+func CaptureTrace(v interface{}, opts ...Option) (Trace, error) {
+	filler := NewFiller(opts...)
+	type captured struct {
+		path, source string
+		value        reflect.Value
+	}
+	var fields []captured
+	log := filler.logger
+	filler.logger = func(e FillEvent) {
+		switch e.Source {
+		case SourcePreset, SourceNone, SourceError, SourceRestricted:
+		default:
+			fields = append(fields, captured{path: e.Path, source: e.Source, value: e.Value.value})
+		}
+		if log != nil {
+			log(e)
+		}
+	}
+	err := filler.FillContext(context.Background(), v)
+
+	// Lookups are resolved once the fill is over, the values are encoded
+	// after it.
+	var trace Trace
+	for _, field := range fields {
+		value, ok := traceValue(field.value)
+		if !ok {
+			trace.Skipped = append(trace.Skipped, field.path)
+			continue
+		}
+		trace.Fields = append(trace.Fields, TraceField{Path: field.path, Source: field.source, Value: value})
+	}
+
+	return trace, err
+}
+
+// traceValue encodes value for a Trace, reporting false when encoding/json
+// fails to encode it or decodes it into another value.
+func traceValue(value reflect.Value) (json.RawMessage, bool) {
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return nil, false
+	}
+	decoded := reflect.New(value.Type())
+	if err := json.Unmarshal(encoded, decoded.Interface()); err != nil {
+		return nil, false
+	}
+
+	return encoded, traceEqual(value, decoded.Elem())
+}
+
+// traceEqual reports whether a, a recorded value, and b, the value decoded
+// from its encoding, are equal, times being equal when they are the same
+// instant: their monotonic clock reading isn't encoded.
+func traceEqual(a, b reflect.Value) bool {
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return traceEqual(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() || a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !traceEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).PkgPath != "" {
+				return reflect.DeepEqual(a.Interface(), b.Interface())
+			}
+		}
+		for i := 0; i < a.NumField(); i++ {
+			if !traceEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// ReplayTrace fills v, a pointer to a struct, like CaptureTrace did when it
+// recorded trace: the fields recorded get their recorded value rather than
+// resolving their default, reported as SourceReplayed, the others are filled
+// as usual by a Filler configured with opts. Like any fill, it leaves the
+// fields that have a value alone.
+func ReplayTrace(v interface{}, trace Trace, opts ...Option) error {
+	filler := NewFiller(opts...)
+	filler.replay = make(map[string]TraceField, len(trace.Fields))
+	for _, field := range trace.Fields {
+		filler.replay[field.Path] = field
+	}
+
+	return filler.FillContext(context.Background(), v)
+}
+
+// replays sets field from the trace replayed by f, if it recorded field,
+// reporting whether it did, see ReplayTrace.
+func (f *Filler) replays(field *FieldData) bool {
+	traced, ok := f.replay[field.Path()]
+	if !ok {
+		return false
+	}
+
+	field.resolved, field.source = true, SourceReplayed
+	value := reflect.New(field.Value.Type())
+	if err := json.Unmarshal(traced.Value, value.Interface()); err != nil {
+		field.fail(fmt.Errorf("replaying %s: %w", traced.Path, err))
+		return true
+	}
+	field.Value.Set(value.Elem())
+
+	return true
+}
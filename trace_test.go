@@ -0,0 +1,122 @@
+package godefault
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type TraceSuite struct{}
+
+var _ = Suite(&TraceSuite{})
+
+type ExampleTraceNested struct {
+	Host string `default:"env:GODEFAULT_TEST_TRACE_HOST:localhost"`
+}
+
+type ExampleTrace struct {
+	Port     int            `default:"env:GODEFAULT_TEST_TRACE_PORT:8080"`
+	ID       string         `default:"gen:uuid"`
+	Engine   string         `default:"choose:v1=50,v2=50"`
+	Workers  int            `default:"expr:Port/1000"`
+	Copy     string         `default:"lookup:Nested.Host"`
+	Token    string         `default:"env:GODEFAULT_TEST_TRACE_TOKEN" secret:"true"`
+	Timeout  *time.Duration `default:"5s"`
+	Tags     []string       `default:"[a,b]"`
+	Preset   string         `default:"tag"`
+	Nested   ExampleTraceNested
+	Untagged string
+}
+
+func (s *TraceSuite) TestCaptureTrace(c *C) {
+	os.Setenv("GODEFAULT_TEST_TRACE_PORT", "9000")
+	os.Setenv("GODEFAULT_TEST_TRACE_HOST", "db")
+	os.Setenv("GODEFAULT_TEST_TRACE_TOKEN", "s3cr3t")
+	captured := &ExampleTrace{Preset: "set"}
+	trace, err := CaptureTrace(captured, WithRandSource(rand.NewSource(1)))
+	os.Unsetenv("GODEFAULT_TEST_TRACE_PORT")
+	os.Unsetenv("GODEFAULT_TEST_TRACE_HOST")
+	os.Unsetenv("GODEFAULT_TEST_TRACE_TOKEN")
+	c.Assert(err, IsNil)
+	c.Assert(captured.Port, Equals, 9000)
+	c.Assert(captured.Copy, Equals, "db")
+
+	paths := make([]string, len(trace.Fields))
+	for i, field := range trace.Fields {
+		paths[i] = field.Path
+	}
+	c.Assert(paths, DeepEquals, []string{"Port", "ID", "Copy", "Token", "Timeout", "Tags", "Nested.Host", "Engine", "Workers"})
+	c.Assert(trace.Fields[0], DeepEquals, TraceField{Path: "Port", Source: SourceEnv, Value: json.RawMessage("9000")})
+	c.Assert(string(trace.Fields[2].Value), Equals, `"db"`)
+
+	// The trace survives encoding, e.g. to a golden file.
+	data, err := json.Marshal(trace)
+	c.Assert(err, IsNil)
+	var decoded Trace
+	c.Assert(json.Unmarshal(data, &decoded), IsNil)
+
+	// Replayed without the environment, another seed and another uuid.
+	report := &FillReport{}
+	replayed := &ExampleTrace{Preset: "set"}
+	c.Assert(ReplayTrace(replayed, decoded, WithReport(report), WithRandSource(rand.NewSource(2))), IsNil)
+	c.Assert(replayed, DeepEquals, captured)
+	c.Assert(report.Fields[0], DeepEquals, FieldReport{Path: "Port", Source: SourceReplayed, Value: "9000", Tag: "env:GODEFAULT_TEST_TRACE_PORT:8080"})
+
+	// Fields not recorded are filled as usual.
+	replayed = &ExampleTrace{}
+	c.Assert(ReplayTrace(replayed, Trace{}), IsNil)
+	c.Assert(replayed.Port, Equals, 8080)
+	c.Assert(replayed.Preset, Equals, "tag")
+
+	// As are the fields set already.
+	replayed = &ExampleTrace{Port: 1}
+	c.Assert(ReplayTrace(replayed, decoded), IsNil)
+	c.Assert(replayed.Port, Equals, 1)
+	c.Assert(replayed.ID, Equals, captured.ID)
+}
+
+type ExampleTraceSkipped struct {
+	Name    string     `default:"app"`
+	Hook    func()     `default:"noop"`
+	Routes  sync.Map   `default:"{/:index}"`
+	Started time.Time  `default:"startup"`
+	Epoch   *time.Time `default:"2020-01-02T03:04:05+07:00"`
+}
+
+func (s *TraceSuite) TestCaptureTraceSkipped(c *C) {
+	captured := &ExampleTraceSkipped{}
+	trace, err := CaptureTrace(captured)
+	c.Assert(err, IsNil)
+	c.Assert(captured.Hook, NotNil)
+	c.Assert(trace.Skipped, DeepEquals, []string{"Hook", "Routes"})
+	paths := make([]string, len(trace.Fields))
+	for i, field := range trace.Fields {
+		paths[i] = field.Path
+	}
+	c.Assert(paths, DeepEquals, []string{"Name", "Started", "Epoch"})
+
+	// The fields skipped are filled from their default.
+	replayed := &ExampleTraceSkipped{}
+	c.Assert(ReplayTrace(replayed, trace), IsNil)
+	c.Assert(replayed.Hook, NotNil)
+	route, ok := replayed.Routes.Load("/")
+	c.Assert(ok, Equals, true)
+	c.Assert(route, Equals, "index")
+	c.Assert(replayed.Started.Equal(captured.Started), Equals, true)
+	c.Assert(replayed.Epoch.Equal(*captured.Epoch), Equals, true)
+}
+
+func (s *TraceSuite) TestReplayTraceErrors(c *C) {
+	trace := Trace{Fields: []TraceField{{Path: "Port", Source: SourceEnv, Value: json.RawMessage(`"eighty"`)}}}
+	foo := &ExampleTrace{}
+	err := ReplayTrace(foo, trace)
+	c.Assert(err, ErrorMatches, `Port: replaying Port: json: cannot unmarshal string .*`)
+	c.Assert(foo.Port, Equals, 0)
+
+	_, err = CaptureTrace(ExampleTrace{})
+	c.Assert(err, ErrorMatches, `godefault: expected a non-nil pointer to a struct, got godefault.ExampleTrace`)
+}
@@ -0,0 +1,88 @@
+package godefault
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type UintSuite struct{}
+
+var _ = Suite(&UintSuite{})
+
+type ExampleUintSize uint64
+
+type ExampleUint struct {
+	Max       uint64             `default:"18446744073709551615"`
+	Half      uint64             `default:"9223372036854775808"`
+	Pointer   *uint64            `default:"18446744073709551615"`
+	Named     ExampleUintSize    `default:"18446744073709551615"`
+	Slice     []uint64           `default:"[9223372036854775808,18446744073709551615]"`
+	JSON      []uint64           `default:"json:[18446744073709551615]"`
+	Map       map[string]uint64  `default:"{max:18446744073709551615}"`
+	Keys      map[uint64]string  `default:"{18446744073709551615:max}"`
+	Env       uint64             `default:"env:GODEFAULT_TEST_UINT:1"`
+	Transform uint64             `default:"9223372036854775807|+1"`
+	Quantity  uint64             `default:"quantity:8Ei"`
+	Section   ExampleUintSection `default:"json:{\"n\":18446744073709551615}"`
+	Copy      uint64             `default:"ref:Max"`
+}
+
+type ExampleUintSection struct {
+	N uint64 `json:"n"`
+}
+
+func (s *UintSuite) TestSetDefaultsUint(c *C) {
+	os.Setenv("GODEFAULT_TEST_UINT", "18446744073709551615")
+	defer os.Unsetenv("GODEFAULT_TEST_UINT")
+
+	foo := &ExampleUint{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Max, Equals, uint64(math.MaxUint64))
+	c.Assert(foo.Half, Equals, uint64(1<<63))
+	c.Assert(*foo.Pointer, Equals, uint64(math.MaxUint64))
+	c.Assert(foo.Named, Equals, ExampleUintSize(math.MaxUint64))
+	c.Assert(foo.Slice, DeepEquals, []uint64{1 << 63, math.MaxUint64})
+	c.Assert(foo.JSON, DeepEquals, []uint64{math.MaxUint64})
+	c.Assert(foo.Map, DeepEquals, map[string]uint64{"max": math.MaxUint64})
+	c.Assert(foo.Keys, DeepEquals, map[uint64]string{math.MaxUint64: "max"})
+	c.Assert(foo.Env, Equals, uint64(math.MaxUint64))
+	c.Assert(foo.Transform, Equals, uint64(1<<63))
+	c.Assert(foo.Quantity, Equals, uint64(1<<63))
+	c.Assert(foo.Section.N, Equals, uint64(math.MaxUint64))
+	c.Assert(foo.Copy, Equals, uint64(math.MaxUint64))
+
+	c.Assert(CheckDefaults(&ExampleUint{}), HasLen, 0)
+	plan, err := Compile(reflect.TypeOf(ExampleUint{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleUint{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.Max, Equals, uint64(math.MaxUint64))
+}
+
+func (s *UintSuite) TestSetDefaultsUintOverflow(c *C) {
+	foo := &struct {
+		Max       uint64   `default:"18446744073709551616"`
+		Small     uint32   `default:"4294967296"`
+		Transform uint64   `default:"18446744073709551615|+1"`
+		Signed    int64    `default:"9223372036854775808"`
+		Quantity  uint64   `default:"quantity:16Ei"`
+		Elements  []uint16 `default:"[1,65536]"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Max: .*value out of range\n`+
+		`Small: value 4294967296 overflows uint32\n`+
+		`Transform: 18446744073709551615\|\+1 overflows uint64\n`+
+		`Signed: .*value out of range\n`+
+		`Quantity: quantity 16Ei overflows uint64\n`+
+		`Elements\[1\]: value 65536 overflows uint16`)
+	c.Assert(errors.Is(err, ErrOverflow), Equals, true)
+	c.Assert(foo.Max, Equals, uint64(0))
+	c.Assert(foo.Small, Equals, uint32(0))
+	c.Assert(foo.Transform, Equals, uint64(0))
+}
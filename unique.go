@@ -0,0 +1,62 @@
+package godefault
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// isUnique reports whether the slice default of sf is a set, its duplicates
+// being removed:
+//
+//	Roles []string `default:"[admin,user,admin]" unique:"true"` // [admin user]
+func isUnique(sf reflect.StructField) bool {
+	unique, _ := strconv.ParseBool(sf.Tag.Get("unique"))
+	return unique
+}
+
+// checkUnique validates the unique tag of sf, which requires comparable
+// elements.
+func checkUnique(sf reflect.StructField) error {
+	if !isUnique(sf) {
+		return nil
+	}
+
+	t := sf.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice || !t.Elem().Comparable() {
+		return fmt.Errorf("unique: %s has no comparable elements", sf.Type)
+	}
+
+	return nil
+}
+
+// dedupSlice removes the duplicates of the slice default of field, keeping
+// the first of each, when the field is unique. A slice that can't be
+// deduplicated is reset.
+func dedupSlice(field *FieldData) {
+	if field.notTag || !isUnique(field.Field) {
+		return
+	}
+	if err := checkUnique(field.Field); err != nil {
+		field.fail(parseErrorOf(err))
+		field.Value.Set(reflect.Zero(field.Value.Type()))
+		return
+	}
+
+	value := field.Value
+	seen := make(map[interface{}]bool, value.Len())
+	n := 0
+	for i := 0; i < value.Len(); i++ {
+		elem := value.Index(i).Interface()
+		if seen[elem] {
+			continue
+		}
+		seen[elem] = true
+		value.Index(n).Set(value.Index(i))
+		n++
+	}
+	field.Value.Set(value.Slice(0, n))
+}
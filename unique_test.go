@@ -0,0 +1,58 @@
+package godefault
+
+import (
+	"context"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type UniqueSuite struct{}
+
+var _ = Suite(&UniqueSuite{})
+
+type ExampleUnique struct {
+	Roles   []string  `default:"[admin,user,admin,guest,user]" unique:"true"`
+	Ports   []int     `default:"[80,443,443,80]" unique:"true"`
+	Names   *[]string `default:"[a,a]" unique:"true"`
+	Repeats []string  `default:"[a,a]"`
+	Off     []string  `default:"[a,a]" unique:"false"`
+}
+
+func (s *UniqueSuite) TestUnique(c *C) {
+	foo := &ExampleUnique{}
+	c.Assert(SetDefaultsContext(context.Background(), foo), IsNil)
+
+	c.Assert(foo.Roles, DeepEquals, []string{"admin", "user", "guest"})
+	c.Assert(foo.Ports, DeepEquals, []int{80, 443})
+	c.Assert(*foo.Names, DeepEquals, []string{"a"})
+	c.Assert(foo.Repeats, DeepEquals, []string{"a", "a"})
+	c.Assert(foo.Off, DeepEquals, []string{"a", "a"})
+
+	// Preset slices are kept as they are.
+	bar := &ExampleUnique{Roles: []string{"x", "x"}}
+	SetDefaults(bar)
+	c.Assert(bar.Roles, DeepEquals, []string{"x", "x"})
+}
+
+func (s *UniqueSuite) TestUniquePlan(c *C) {
+	plan, err := Compile(reflect.TypeOf(ExampleUnique{}))
+	c.Assert(err, IsNil)
+
+	foo := &ExampleUnique{}
+	c.Assert(plan.Apply(foo), IsNil)
+	c.Assert(foo.Roles, DeepEquals, []string{"admin", "user", "guest"})
+}
+
+func (s *UniqueSuite) TestUniqueNotComparable(c *C) {
+	foo := &struct {
+		Sets [][]int `default:"[[1],[1]]" unique:"true"`
+		Name string  `default:"a" unique:"true"`
+	}{}
+	err := SetDefaultsContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Sets: unique: \[\]\[\]int has no comparable elements`)
+	c.Assert(foo.Sets, IsNil)
+
+	c.Assert(CheckDefaults(foo), HasLen, 2)
+	c.Assert(CheckDefaults(&ExampleUnique{}), HasLen, 0)
+}
@@ -0,0 +1,20 @@
+//go:build !godefault_nounsafe
+// +build !godefault_nounsafe
+
+package godefault
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// unexportedValue returns a settable alias of value, an addressable
+// unexported field, see WithUnsafeUnexported. Building with the
+// godefault_nounsafe tag removes it.
+func unexportedValue(value reflect.Value) (reflect.Value, bool) {
+	if !value.CanAddr() {
+		return value, false
+	}
+
+	return reflect.NewAt(value.Type(), unsafe.Pointer(value.UnsafeAddr())).Elem(), true
+}
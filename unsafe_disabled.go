@@ -0,0 +1,12 @@
+//go:build godefault_nounsafe
+// +build godefault_nounsafe
+
+package godefault
+
+import "reflect"
+
+// unexportedValue never sets unexported fields when built with the
+// godefault_nounsafe tag, whatever WithUnsafeUnexported says.
+func unexportedValue(value reflect.Value) (reflect.Value, bool) {
+	return value, false
+}
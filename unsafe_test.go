@@ -0,0 +1,55 @@
+//go:build !godefault_nounsafe
+// +build !godefault_nounsafe
+
+package godefault
+
+import (
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type UnsafeSuite struct{}
+
+var _ = Suite(&UnsafeSuite{})
+
+type ExampleUnexported struct {
+	Name    string `default:"app"`
+	port    int    `default:"8080"`
+	timeout time.Duration
+	hosts   []string `default:"[a,b]"`
+	child   struct {
+		Age  int    `default:"10"`
+		name string `default:"bob"`
+	}
+}
+
+func (s *UnsafeSuite) TestWithUnsafeUnexported(c *C) {
+	foo := &ExampleUnexported{}
+	NewFiller(WithUnsafeUnexported()).Fill(foo)
+
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.port, Equals, 8080)
+	c.Assert(foo.timeout, Equals, time.Duration(0))
+	c.Assert(foo.hosts, DeepEquals, []string{"a", "b"})
+	c.Assert(foo.child.Age, Equals, 10)
+	c.Assert(foo.child.name, Equals, "bob")
+
+	plan, err := Compile(reflect.TypeOf(ExampleUnexported{}), WithUnsafeUnexported())
+	c.Assert(err, IsNil)
+	bar := &ExampleUnexported{port: 1}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar.port, Equals, 1)
+	c.Assert(bar.child.name, Equals, "bob")
+}
+
+func (s *UnsafeSuite) TestUnexportedSkipped(c *C) {
+	foo := &ExampleUnexported{}
+	SetDefaults(foo)
+
+	c.Assert(foo.Name, Equals, "app")
+	c.Assert(foo.port, Equals, 0)
+	c.Assert(foo.hosts, IsNil)
+	c.Assert(foo.child.Age, Equals, 0)
+}
@@ -0,0 +1,128 @@
+package godefault
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// varPrefix introduces a default read from a package-level string variable
+// registered with RegisterVar, e.g. one stamped at build time with -ldflags
+// -X, followed by an optional fallback after "||", used when the variable is
+// empty or not registered:
+//
+//	Endpoint string `default:"var:main.APIEndpoint||https://api.example.com"`
+//
+// The fallback is a default tag of its own, e.g. an env: reference. Integer
+// fields keep their transform, like with env: references.
+const varPrefix = "var:"
+
+// buildVarPrefix introduces a default read from a value registered with
+// RegisterBuildVar, e.g. the version of the binary, with an optional fallback
+// like var: references:
+//
+//	Version string `default:"buildvar:Version||dev"`
+const buildVarPrefix = "buildvar:"
+
+// varFallback separates the fallback of a var: or buildvar: reference.
+const varFallback = "||"
+
+// vars holds the variables registered with RegisterVar, by name, and
+// buildVars the values registered with RegisterBuildVar.
+var vars, buildVars sync.Map
+
+// RegisterVar makes the string variable at p the one var:name defaults read,
+// usually from the init function of the package declaring it:
+//
+//	var APIEndpoint string // set with -ldflags "-X main.APIEndpoint=..."
+//
+//	func init() {
+//	    godefault.RegisterVar("main.APIEndpoint", &APIEndpoint)
+//	}
+//
+// The variable is read by every fill, registering the same name again
+// replaces it.
+func RegisterVar(name string, p *string) {
+	vars.Store(name, p)
+}
+
+// RegisterBuildVar registers the value buildvar:name defaults resolve to,
+// e.g. a version or a commit stamped with -ldflags -X, at startup:
+//
+//	godefault.RegisterBuildVar("Version", version)
+//
+// Registering the same name again replaces the value.
+func RegisterBuildVar(name, value string) {
+	buildVars.Store(name, value)
+}
+
+// isVarRef reports whether value is a var: or a buildvar: reference.
+func isVarRef(value string) bool {
+	return strings.HasPrefix(value, varPrefix) || strings.HasPrefix(value, buildVarPrefix)
+}
+
+// parseVarRef splits a var: or buildvar: reference into its prefix, the name
+// of the variable and the fallback.
+func parseVarRef(value string) (prefix, name, fallback string, err error) {
+	prefix = varPrefix
+	if strings.HasPrefix(value, buildVarPrefix) {
+		prefix = buildVarPrefix
+	}
+	name = strings.TrimPrefix(value, prefix)
+	if i := strings.Index(name, varFallback); i >= 0 {
+		name, fallback = name[:i], name[i+len(varFallback):]
+	}
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", "", fmt.Errorf("invalid %s reference %q, expected %sname[%sfallback]", strings.TrimSuffix(prefix, ":"), value, prefix, varFallback)
+	}
+
+	return prefix, name, fallback, nil
+}
+
+// lookupVar returns the value of the variable registered under name for the
+// references of prefix, and whether there is one.
+func lookupVar(prefix, name string) (string, bool) {
+	if prefix == buildVarPrefix {
+		value, ok := buildVars.Load(name)
+		if !ok {
+			return "", false
+		}
+		return value.(string), true
+	}
+
+	p, ok := vars.Load(name)
+	if !ok {
+		return "", false
+	}
+
+	return *p.(*string), true
+}
+
+// resolveVarRef replaces the var: or buildvar: reference of field by the
+// value of the variable named name, or by the fallback, reporting whether the
+// variable was used. It fails strict fills on variables that aren't
+// registered.
+func resolveVarRef(field *FieldData) (value, name string, found bool) {
+	value, suffix := field.TagValue, ""
+	if isIntegerType(field.Field.Type) {
+		value, suffix = splitIntTransform(value)
+	}
+	prefix, name, fallback, err := parseVarRef(value)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return "", "", false
+	}
+
+	resolved, ok := lookupVar(prefix, name)
+	if !ok {
+		if field.owner().strict {
+			field.fail(&ResolverError{Prefix: prefix, Err: fmt.Errorf("%s%s: no such registered variable", prefix, name)})
+		}
+		return fallback + suffix, name, false
+	}
+	if resolved != "" {
+		return resolved + suffix, name, true
+	}
+
+	return fallback + suffix, name, false
+}
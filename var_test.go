@@ -0,0 +1,110 @@
+package godefault
+
+import (
+	"context"
+	"os"
+
+	. "gopkg.in/check.v1"
+)
+
+type VarSuite struct{}
+
+var _ = Suite(&VarSuite{})
+
+var (
+	testEndpoint = "https://stamped.example.com"
+	testPort     = "9000"
+	testUnset    string
+)
+
+func init() {
+	RegisterVar("godefault.testEndpoint", &testEndpoint)
+	RegisterVar("godefault.testPort", &testPort)
+	RegisterVar("godefault.testUnset", &testUnset)
+}
+
+type ExampleVar struct {
+	Endpoint string `default:"var:godefault.testEndpoint||https://api.example.com"`
+	Port     int    `default:"var:godefault.testPort||8080|+1"`
+	Unset    string `default:"var:godefault.testUnset||https://api.example.com"`
+	Env      int    `default:"var:godefault.testUnset||env:GODEFAULT_TEST_PORT:8080"`
+	Missing  string `default:"var:main.Missing||fallback"`
+	Bare     string `default:"var:godefault.testUnset"`
+}
+
+func (s *VarSuite) TestVar(c *C) {
+	os.Setenv("GODEFAULT_TEST_PORT", "7000")
+	defer os.Unsetenv("GODEFAULT_TEST_PORT")
+
+	foo := &ExampleVar{}
+	report := &FillReport{}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Endpoint, Equals, "https://stamped.example.com")
+	c.Assert(foo.Port, Equals, 9001)
+	c.Assert(foo.Unset, Equals, "https://api.example.com")
+	c.Assert(foo.Env, Equals, 7000)
+	c.Assert(foo.Missing, Equals, "fallback")
+	c.Assert(foo.Bare, Equals, "")
+
+	c.Assert(report.Fields[0].Source, Equals, SourceVar)
+	c.Assert(report.Fields[2].Source, Equals, SourceTag)
+	c.Assert(report.Fields[3].Source, Equals, SourceEnv)
+}
+
+func (s *VarSuite) TestVarRegisteredLater(c *C) {
+	value := "a"
+	RegisterVar("godefault.testLater", &value)
+	foo := &struct {
+		Value string `default:"var:godefault.testLater"`
+	}{}
+	value = "b"
+	SetDefaults(foo)
+	c.Assert(foo.Value, Equals, "b")
+}
+
+func (s *VarSuite) TestVarErrors(c *C) {
+	foo := &ExampleVar{}
+	err := NewFiller(WithStrict()).FillContext(context.Background(), foo)
+	c.Assert(err, ErrorMatches, `Missing: var:main.Missing: no such registered variable`)
+	c.Assert(foo.Missing, Equals, "fallback")
+
+	bar := &struct {
+		Empty string `default:"var:||x"`
+		Port  int    `default:"var:main.Port||x"`
+	}{}
+	c.Assert(SetDefaultsContext(context.Background(), bar), ErrorMatches,
+		`Empty: invalid var reference "var:\|\|x", expected var:name\[\|\|fallback\]\nPort: strconv.ParseInt: .*`)
+	c.Assert(CheckDefaults(bar), HasLen, 2)
+	c.Assert(CheckDefaults(&ExampleVar{}), HasLen, 0)
+}
+
+func (s *VarSuite) TestBuildVar(c *C) {
+	RegisterBuildVar("godefault.Version", "1.2.3")
+	RegisterBuildVar("godefault.Build", "42")
+	RegisterBuildVar("godefault.Empty", "")
+
+	foo := &struct {
+		Version string `default:"buildvar:godefault.Version"`
+		Build   int    `default:"buildvar:godefault.Build|+1"`
+		Empty   string `default:"buildvar:godefault.Empty||dev"`
+		Missing string `default:"buildvar:godefault.Missing||unknown"`
+		Var     string `default:"var:godefault.Version||var"`
+	}{}
+	report := &FillReport{}
+	c.Assert(NewFiller(WithReport(report)).FillContext(context.Background(), foo), IsNil)
+	c.Assert(foo.Version, Equals, "1.2.3")
+	c.Assert(foo.Build, Equals, 43)
+	c.Assert(foo.Empty, Equals, "dev")
+	c.Assert(foo.Missing, Equals, "unknown")
+	c.Assert(foo.Var, Equals, "var")
+	c.Assert(report.Fields[0].Source, Equals, SourceVar)
+
+	bar := &struct {
+		Missing string `default:"buildvar:godefault.Missing"`
+		Empty   string `default:"buildvar:"`
+	}{}
+	c.Assert(NewFiller(WithStrict()).FillContext(context.Background(), bar), ErrorMatches,
+		`Missing: buildvar:godefault.Missing: no such registered variable\n`+
+			`Empty: invalid buildvar reference "buildvar:", expected buildvar:name\[\|\|fallback\]`)
+	c.Assert(CheckDefaults(bar), HasLen, 1)
+}
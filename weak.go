@@ -0,0 +1,165 @@
+package godefault
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// WithWeakTyping makes the values assigned by layers and command line
+// arguments, see Apply and ApplyArgs, coerced to the kind of their field when
+// they are written for another one, as override files often do:
+//
+//	"true", "false"     1 and 0 in integer and float fields
+//	0, 1                false and true in bool fields
+//	1e3, 2.0            1000 and 2 in integer fields
+//	30, 1.5             durations in the unit of WithWeakDurationUnit
+//	[a]                 "a" in string fields
+//	a                   a single element in []string fields
+//
+// A coercion that would lose information, such as 1.5 in an integer field,
+// fails the field with an ErrParse unless WithLossyCoercion allows it.
+// Default tags are parsed as they are, whatever the option.
+func WithWeakTyping(weak bool) Option {
+	return func(f *Filler) {
+		f.weakTyping = weak
+	}
+}
+
+// WithLossyCoercion lets the coercions of WithWeakTyping truncate, toward
+// zero, the fractions they can't keep, e.g. 1.5 to 1 in an integer field.
+func WithLossyCoercion(lossy bool) Option {
+	return func(f *Filler) {
+		f.lossyCoercion = lossy
+	}
+}
+
+// WithWeakDurationUnit sets the unit of the bare numbers WithWeakTyping
+// coerces to durations, time.Second by default, time.Nanosecond keeping
+// them as they are counted by time.Duration.
+func WithWeakDurationUnit(unit time.Duration) Option {
+	return func(f *Filler) {
+		f.weakDurationUnit = unit
+	}
+}
+
+// coerce returns value, assigned to field, coerced to its kind when the
+// filler of field has weak typing, failing field and returning "" when the
+// coercion would lose information, see WithWeakTyping.
+func (field *FieldData) coerce(value string) string {
+	f := field.owner()
+	if !f.weakTyping {
+		return value
+	}
+
+	coerced, err := coerceValue(value, derefType(field.Value.Type()), f.lossyCoercion, f.weakDurationUnit)
+	if err != nil {
+		field.fail(parseErrorOf(err))
+		return ""
+	}
+
+	return coerced
+}
+
+// coerceValue coerces value to the type t, see WithWeakTyping. Values it
+// doesn't know how to coerce are returned as they are.
+func coerceValue(value string, t reflect.Type, lossy bool, unit time.Duration) (string, error) {
+	n, number := weakNumber(value)
+	switch {
+	case t == durationType:
+		if !number {
+			return value, nil
+		}
+		if unit == 0 {
+			unit = time.Second
+		}
+		d, err := weakInteger(value, new(big.Rat).Mul(n, new(big.Rat).SetInt64(int64(unit))), t, lossy)
+		if err != nil {
+			return "", err
+		}
+		if !d.IsInt64() {
+			return "", overflowf("value %s overflows %s", value, t)
+		}
+		return time.Duration(d.Int64()).String(), nil
+	case isIntegerType(t):
+		if b, ok := weakBool(value); ok {
+			return b, nil
+		}
+		if !number || n.IsInt() && !strings.ContainsAny(value, ".eE") {
+			return value, nil
+		}
+		i, err := weakInteger(value, n, t, lossy)
+		if err != nil {
+			return "", err
+		}
+		return i.String(), nil
+	case isFloatType(t):
+		if b, ok := weakBool(value); ok {
+			return b, nil
+		}
+	case t.Kind() == reflect.Bool:
+		if !number {
+			return value, nil
+		}
+		switch {
+		case n.Cmp(big.NewRat(0, 1)) == 0:
+			return "false", nil
+		case n.Cmp(big.NewRat(1, 1)) == 0:
+			return "true", nil
+		}
+		return "", fmt.Errorf("%s is not 0 or 1, it can't be a bool", value)
+	case t.Kind() == reflect.String:
+		elems, ok, err := splitSliceTag(value)
+		if !ok || err != nil {
+			return value, nil
+		}
+		switch len(elems) {
+		case 0:
+			return "", nil
+		case 1:
+			return elems[0], nil
+		}
+		return "", fmt.Errorf("%s has %d elements, it can't be a string", value, len(elems))
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		if value == "" || strings.HasPrefix(value, "[") {
+			return value, nil
+		}
+		return "[" + strings.ReplaceAll(value, ",", "|,") + "]", nil
+	}
+
+	return value, nil
+}
+
+// weakNumber parses value as a decimal number, exponents allowed.
+func weakNumber(value string) (*big.Rat, bool) {
+	if value == "" || strings.Contains(value, "/") {
+		return nil, false
+	}
+	n, ok := new(big.Rat).SetString(value)
+
+	return n, ok
+}
+
+// weakBool returns the number a bool value stands for in numeric fields.
+func weakBool(value string) (string, bool) {
+	switch value {
+	case "true":
+		return "1", true
+	case "false":
+		return "0", true
+	}
+
+	return "", false
+}
+
+// weakInteger returns n, the number value stands for, as an integer of the
+// type t, truncated when lossy allows it.
+func weakInteger(value string, n *big.Rat, t reflect.Type, lossy bool) (*big.Int, error) {
+	if !n.IsInt() && !lossy {
+		return nil, fmt.Errorf("%s would be truncated in a %s, see WithLossyCoercion", value, t)
+	}
+
+	return new(big.Int).Quo(n.Num(), n.Denom()), nil
+}
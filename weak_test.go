@@ -0,0 +1,102 @@
+package godefault
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type WeakSuite struct{}
+
+var _ = Suite(&WeakSuite{})
+
+type ExampleWeak struct {
+	Port    int           `default:"80"`
+	Ratio   float64       `default:"0.5"`
+	Debug   bool          `default:"false"`
+	Timeout time.Duration `default:"5s"`
+	Name    string        `default:"app"`
+	Hosts   []string      `default:"[localhost]"`
+	Count   *uint8        `default:"1"`
+}
+
+func (s *WeakSuite) TestWithWeakTyping(c *C) {
+	values := map[string]string{
+		"port":    "8e3",
+		"ratio":   "true",
+		"debug":   "1",
+		"timeout": "30",
+		"name":    "[web]",
+		"hosts":   "db,1",
+		"count":   "2.0",
+	}
+
+	foo := &ExampleWeak{}
+	c.Assert(NewFiller(WithWeakTyping(true)).Apply(foo, MapLayer(values), TagLayer()), IsNil)
+	c.Assert(foo.Port, Equals, 8000)
+	c.Assert(foo.Ratio, Equals, 1.0)
+	c.Assert(foo.Debug, Equals, true)
+	c.Assert(foo.Timeout, Equals, 30*time.Second)
+	c.Assert(foo.Name, Equals, "web")
+	c.Assert(foo.Hosts, DeepEquals, []string{"db,1"})
+	c.Assert(*foo.Count, Equals, uint8(2))
+
+	// Without the option the values are parsed like tags.
+	bar := &ExampleWeak{}
+	err := Apply(bar, MapLayer(values), TagLayer())
+	c.Assert(err, NotNil)
+	c.Assert(bar.Name, Equals, "[web]")
+	c.Assert(bar.Debug, Equals, true)
+
+	foo = &ExampleWeak{}
+	filler := NewFiller(WithWeakTyping(true), WithWeakDurationUnit(time.Millisecond))
+	c.Assert(filler.ApplyArgs(foo, []string{"--timeout", "1.5", "--port=true", "--debug=0"}), IsNil)
+	c.Assert(foo.Timeout, Equals, 1500*time.Microsecond)
+	c.Assert(foo.Port, Equals, 1)
+	c.Assert(foo.Debug, Equals, false)
+	c.Assert(foo.Name, Equals, "app")
+
+	// Tags are parsed as they are.
+	baz := &struct {
+		Timeout time.Duration `default:"30"`
+	}{}
+	c.Assert(NewFiller(WithWeakTyping(true)).Apply(baz, TagLayer()), NotNil)
+}
+
+func (s *WeakSuite) TestWithWeakTypingLossy(c *C) {
+	values := map[string]string{"port": "1.5", "debug": "2", "name": "[a,b]", "timeout": "1.5"}
+
+	foo := &ExampleWeak{}
+	err := NewFiller(WithWeakTyping(true), WithWeakDurationUnit(time.Nanosecond)).Apply(foo, MapLayer(values))
+	c.Assert(err, ErrorMatches, `Port: 1.5 would be truncated in a int, see WithLossyCoercion\n`+
+		`Debug: 2 is not 0 or 1, it can't be a bool\n`+
+		`Timeout: 1.5 would be truncated in a time.Duration, see WithLossyCoercion\n`+
+		`Name: \[a,b\] has 2 elements, it can't be a string`)
+	c.Assert(errors.Is(err, ErrParse), Equals, true)
+	c.Assert(foo.Port, Equals, 0)
+
+	foo = &ExampleWeak{}
+	filler := NewFiller(WithWeakTyping(true), WithLossyCoercion(true), WithWeakDurationUnit(time.Nanosecond))
+	c.Assert(filler.Apply(foo, MapLayer(map[string]string{"port": "-1.9", "timeout": "1.5"})), IsNil)
+	c.Assert(foo.Port, Equals, -1)
+	c.Assert(foo.Timeout, Equals, time.Nanosecond)
+}
+
+func (s *WeakSuite) TestCoerceValue(c *C) {
+	for value, expected := range map[string]string{
+		"8080":  "8080",
+		"0x10":  "0x10",
+		"true":  "1",
+		"1e2":   "100",
+		"-3.0":  "-3",
+		"1.5Ki": "1.5Ki",
+		"env:X": "env:X",
+		"":      "",
+	} {
+		coerced, err := coerceValue(value, reflect.TypeOf(0), false, time.Second)
+		c.Assert(err, IsNil, Commentf("%s", value))
+		c.Assert(coerced, Equals, expected, Commentf("%s", value))
+	}
+}
@@ -0,0 +1,50 @@
+package godefault
+
+import "reflect"
+
+// zeroSentinel is the default setting a field to the zero value of its type,
+// whatever its kind: the empty string, 0, false, a nil slice, map or pointer,
+// the zero time, and an empty but non-nil []byte, e.g. to reset with Apply a
+// value set by a higher layer:
+//
+//	Proxy   *url.URL      `default:"zero"`
+//	Timeout time.Duration `default:"zero"`
+//	Name    string        `default:"zero;force"`
+//
+// Fields holding strings, behind pointers or not, take "zero" as the word:
+// they need forcedZeroSentinel, which works for every type. Structs are
+// zeroed as a whole, their fields left alone. The fill report attributes the
+// value to SourceExplicitZero.
+const (
+	zeroSentinel       = "zero"
+	forcedZeroSentinel = "zero;force"
+)
+
+// isZeroSentinel reports whether value sets a field of type t to its zero
+// value.
+func isZeroSentinel(t reflect.Type, value string) bool {
+	switch value {
+	case forcedZeroSentinel:
+		return true
+	case zeroSentinel:
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		return t.Kind() != reflect.String
+	}
+
+	return false
+}
+
+// setZero sets field to the zero value of its type, see zeroSentinel. The
+// zero of a []byte is an empty, non-nil one, see fillBytes.
+func setZero(field *FieldData) {
+	t := field.Value.Type()
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		field.Value.Set(reflect.MakeSlice(t, 0, 0))
+		field.source = SourceExplicitZero
+		return
+	}
+	field.Value.Set(reflect.Zero(t))
+	field.source = SourceExplicitZero
+}
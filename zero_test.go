@@ -0,0 +1,72 @@
+package godefault
+
+import (
+	"reflect"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ZeroSuite struct{}
+
+var _ = Suite(&ZeroSuite{})
+
+type ExampleZero struct {
+	Port    int               `default:"zero"`
+	Pointer *int              `default:"zero"`
+	Tags    []string          `default:"zero"`
+	Labels  map[string]string `default:"zero"`
+	Started time.Time         `default:"zero"`
+	Word    string            `default:"zero"`
+	Text    *string           `default:"zero"`
+	Loose   interface{}       `default:"zero"`
+	Forced  string            `default:"zero;force"`
+	Child   Child             `default:"zero"`
+	Ratio   float64           `default:"env:GODEFAULT_TEST_UNSET:zero"`
+}
+
+func (s *ZeroSuite) TestSetDefaultsZero(c *C) {
+	var report FillReport
+	foo := &ExampleZero{}
+	NewFiller(WithReport(&report)).Fill(foo)
+
+	word := "zero"
+	c.Assert(foo, DeepEquals, &ExampleZero{Word: "zero", Text: &word})
+	sources := make(map[string]string)
+	for _, field := range report.Fields {
+		sources[field.Path] = field.Source
+	}
+	c.Assert(sources, DeepEquals, map[string]string{
+		"Port":    SourceExplicitZero,
+		"Pointer": SourceExplicitZero,
+		"Tags":    SourceExplicitZero,
+		"Labels":  SourceExplicitZero,
+		"Started": SourceExplicitZero,
+		"Word":    SourceTag,
+		"Text":    SourceTag,
+		"Loose":   SourceExplicitZero,
+		"Forced":  SourceExplicitZero,
+		"Ratio":   SourceExplicitZero,
+	})
+
+	plan, err := Compile(reflect.TypeOf(ExampleZero{}))
+	c.Assert(err, IsNil)
+	bar := &ExampleZero{}
+	c.Assert(plan.Apply(bar), IsNil)
+	c.Assert(bar, DeepEquals, foo)
+
+	c.Assert(CheckDefaults(&ExampleZero{}), HasLen, 0)
+}
+
+func (s *ZeroSuite) TestApplyZero(c *C) {
+	foo := &ExampleLayers{Name: "polluted", Workers: 9}
+	err := Apply(foo, MapLayer(map[string]string{"name": "zero;force", "workers": "zero"}), ValuesLayer(), TagLayer())
+	c.Assert(err, IsNil)
+	c.Assert(foo.Name, Equals, "")
+	c.Assert(foo.Workers, Equals, 0)
+	c.Assert(foo.Mode, Equals, "dev")
+
+	foo = &ExampleLayers{Name: "kept"}
+	c.Assert(Apply(foo, MapLayer(map[string]string{"name": "zero"})), IsNil)
+	c.Assert(foo.Name, Equals, "zero")
+}